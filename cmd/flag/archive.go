@@ -0,0 +1,21 @@
+package flag
+
+import (
+	"github.com/spf13/cobra"
+)
+
+type ArchiveFlagValues struct {
+	Archive string
+}
+
+var (
+	archiveFlagValues ArchiveFlagValues
+)
+
+func SetArchiveFlags(command *cobra.Command) {
+	command.Flags().StringVar(&archiveFlagValues.Archive, "archive", "", "Download to an archive file (.tar, .tar.gz, .tar.zst or .zip) instead of a local directory, one file at a time regardless of --thread_num")
+}
+
+func GetArchiveFlagValues() *ArchiveFlagValues {
+	return &archiveFlagValues
+}
@@ -0,0 +1,25 @@
+package flag
+
+import (
+	"github.com/spf13/cobra"
+)
+
+type CatFlagValues struct {
+	Offset int64
+	Length int64
+	WebDAV bool
+}
+
+var (
+	catFlagValues CatFlagValues
+)
+
+func SetCatFlags(command *cobra.Command) {
+	command.Flags().Int64Var(&catFlagValues.Offset, "offset", 0, "Start reading from the offset in bytes")
+	command.Flags().Int64Var(&catFlagValues.Length, "length", 0, "Read the number of bytes (0 to read to the end)")
+	command.Flags().BoolVar(&catFlagValues.WebDAV, "webdav", false, "Use WebDAV protocol (HTTP) for reading")
+}
+
+func GetCatFlagValues() *CatFlagValues {
+	return &catFlagValues
+}
@@ -0,0 +1,27 @@
+package flag
+
+import (
+	"github.com/spf13/cobra"
+)
+
+type DiscoveryFlagValues struct {
+	Recursive bool
+	MaxDepth  int
+	Include   []string
+	Exclude   []string
+}
+
+var (
+	discoveryFlagValues DiscoveryFlagValues
+)
+
+func SetDiscoveryFlags(command *cobra.Command) {
+	command.Flags().BoolVar(&discoveryFlagValues.Recursive, "recursive", false, "Find simulation directories recursively")
+	command.Flags().IntVar(&discoveryFlagValues.MaxDepth, "max_depth", 0, "Set max depth to find simulation directories in recursive mode (0 for unlimited)")
+	command.Flags().StringArrayVar(&discoveryFlagValues.Include, "include", []string{}, "Include simulation directories matching the glob pattern (relative path or name)")
+	command.Flags().StringArrayVar(&discoveryFlagValues.Exclude, "exclude", []string{}, "Exclude directories matching the glob pattern (relative path or name)")
+}
+
+func GetDiscoveryFlagValues() *DiscoveryFlagValues {
+	return &discoveryFlagValues
+}
@@ -0,0 +1,27 @@
+package flag
+
+import (
+	"github.com/spf13/cobra"
+)
+
+type DownloadFilterFlagValues struct {
+	Include     []string
+	Exclude     []string
+	MaxFileSize string
+	Only        string
+}
+
+var (
+	downloadFilterFlagValues DownloadFilterFlagValues
+)
+
+func SetDownloadFilterFlags(command *cobra.Command) {
+	command.Flags().StringArrayVar(&downloadFilterFlagValues.Include, "include", []string{}, "Download only files matching the glob pattern (relative path or name)")
+	command.Flags().StringArrayVar(&downloadFilterFlagValues.Exclude, "exclude", []string{}, "Do not download files or directories matching the glob pattern (relative path or name)")
+	command.Flags().StringVar(&downloadFilterFlagValues.MaxFileSize, "max_file_size", "", "Do not download files larger than the size (e.g., 100MB)")
+	command.Flags().StringVar(&downloadFilterFlagValues.Only, "only", "", "Download only files of the roles described in metadata (trajectory, structure, topology, additional, additional:<file_type>, comma-separated)")
+}
+
+func GetDownloadFilterFlagValues() *DownloadFilterFlagValues {
+	return &downloadFilterFlagValues
+}
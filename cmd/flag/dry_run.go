@@ -0,0 +1,21 @@
+package flag
+
+import (
+	"github.com/spf13/cobra"
+)
+
+type DryRunFlagValues struct {
+	DryRun bool
+}
+
+var (
+	dryRunFlagValues DryRunFlagValues
+)
+
+func SetDryRunFlags(command *cobra.Command) {
+	command.Flags().BoolVar(&dryRunFlagValues.DryRun, "dry_run", false, "Print what would be transferred without transferring")
+}
+
+func GetDryRunFlagValues() *DryRunFlagValues {
+	return &dryRunFlagValues
+}
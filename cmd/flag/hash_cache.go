@@ -0,0 +1,28 @@
+package flag
+
+import (
+	"github.com/spf13/cobra"
+)
+
+type HashCacheFlagValues struct {
+	NoHashCache bool
+	Clear       bool
+	Prune       bool
+}
+
+var (
+	hashCacheFlagValues HashCacheFlagValues
+)
+
+func SetHashCacheFlags(command *cobra.Command) {
+	command.Flags().BoolVar(&hashCacheFlagValues.NoHashCache, "no_hash_cache", false, "Do not use cached hashes of local files")
+}
+
+func SetHashCacheManagementFlags(command *cobra.Command) {
+	command.Flags().BoolVar(&hashCacheFlagValues.Clear, "clear", false, "Remove all cached hashes")
+	command.Flags().BoolVar(&hashCacheFlagValues.Prune, "prune", false, "Remove cached hashes of files that are missing or changed")
+}
+
+func GetHashCacheFlagValues() *HashCacheFlagValues {
+	return &hashCacheFlagValues
+}
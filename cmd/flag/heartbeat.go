@@ -0,0 +1,43 @@
+package flag
+
+import (
+	"time"
+
+	"github.com/MD-Repo/md-repo-cli/commons/types"
+	"github.com/cockroachdb/errors"
+	"github.com/spf13/cobra"
+)
+
+type HeartbeatFlagValues struct {
+	Heartbeat string
+}
+
+var (
+	heartbeatFlagValues HeartbeatFlagValues
+)
+
+func SetHeartbeatFlags(command *cobra.Command) {
+	command.Flags().StringVar(&heartbeatFlagValues.Heartbeat, "heartbeat", "", "Update the submission status on MD-Repo at the given interval (e.g., 30s, 5m, 1h)")
+}
+
+func GetHeartbeatFlagValues() *HeartbeatFlagValues {
+	return &heartbeatFlagValues
+}
+
+// GetInterval returns the heartbeat interval, 0 if disabled
+func (h *HeartbeatFlagValues) GetInterval() (time.Duration, error) {
+	if len(h.Heartbeat) == 0 {
+		return 0, nil
+	}
+
+	seconds, err := types.ParseTime(h.Heartbeat)
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to parse heartbeat interval %q", h.Heartbeat)
+	}
+
+	if seconds <= 0 {
+		return 0, errors.Errorf("heartbeat interval %q must be positive", h.Heartbeat)
+	}
+
+	return time.Duration(seconds) * time.Second, nil
+}
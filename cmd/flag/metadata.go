@@ -0,0 +1,21 @@
+package flag
+
+import (
+	"github.com/spf13/cobra"
+)
+
+type MetadataFlagValues struct {
+	Strict bool
+}
+
+var (
+	metadataFlagValues MetadataFlagValues
+)
+
+func SetMetadataFlags(command *cobra.Command) {
+	command.Flags().BoolVar(&metadataFlagValues.Strict, "strict", false, "Treat unknown fields and invalid formats in metadata files as errors")
+}
+
+func GetMetadataFlagValues() *MetadataFlagValues {
+	return &metadataFlagValues
+}
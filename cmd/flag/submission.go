@@ -8,6 +8,8 @@ type SubmissionFlagValues struct {
 	ExpectedSimulations int
 	OrcID               string
 	NoID                bool
+	Mapping             string
+	Resume              bool
 }
 
 var (
@@ -18,6 +20,8 @@ func SetSubmissionFlags(command *cobra.Command) {
 	command.Flags().IntVarP(&submissionFlagValues.ExpectedSimulations, "expected_simulations", "n", 0, "Set the number of expected simulations")
 	command.Flags().StringVar(&submissionFlagValues.OrcID, "orcid", "", "Set ORC-ID")
 	command.Flags().BoolVar(&submissionFlagValues.NoID, "no-id", false, "Submit without an ID")
+	command.Flags().BoolVar(&submissionFlagValues.Resume, "resume", false, "Resume an interrupted submission, reporting files left in the local journal")
+	command.Flags().StringVar(&submissionFlagValues.Mapping, "mapping", "", "Set a JSON file mapping simulation directories to submission IDs, replacing the saved mapping")
 }
 
 func GetSubmissionFlagValues() *SubmissionFlagValues {
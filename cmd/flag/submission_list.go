@@ -5,7 +5,9 @@ import (
 )
 
 type SubmissionListFlagValues struct {
-	OrcID string
+	OrcID   string
+	Compare bool
+	Mapping string
 }
 
 var (
@@ -14,6 +16,8 @@ var (
 
 func SetSubmissionListFlags(command *cobra.Command) {
 	command.Flags().StringVar(&submissionListFlagValues.OrcID, "orcid", "", "Set ORC-ID")
+	command.Flags().BoolVar(&submissionListFlagValues.Compare, "compare", false, "Compare local simulation directories given with submitted data")
+	command.Flags().StringVar(&submissionListFlagValues.Mapping, "mapping", "", "Set a JSON file mapping simulation directories to submission IDs, for --compare")
 }
 
 func GetSubmissionListFlagValues() *SubmissionListFlagValues {
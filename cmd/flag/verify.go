@@ -0,0 +1,21 @@
+package flag
+
+import (
+	"github.com/spf13/cobra"
+)
+
+type VerifyFlagValues struct {
+	Repair bool
+}
+
+var (
+	verifyFlagValues VerifyFlagValues
+)
+
+func SetVerifyFlags(command *cobra.Command) {
+	command.Flags().BoolVar(&verifyFlagValues.Repair, "repair", false, "Download missing or corrupt files again")
+}
+
+func GetVerifyFlagValues() *VerifyFlagValues {
+	return &verifyFlagValues
+}
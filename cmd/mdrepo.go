@@ -79,6 +79,11 @@ func main() {
 	subcmd.AddGetCommand(rootCmd)
 	subcmd.AddSubmitCommand(rootCmd)
 	subcmd.AddSubmitListCommand(rootCmd)
+	subcmd.AddVerifyCommand(rootCmd)
+	subcmd.AddCatCommand(rootCmd)
+	subcmd.AddValidateCommand(rootCmd)
+	subcmd.AddInitCommand(rootCmd)
+	subcmd.AddCacheCommand(rootCmd)
 	subcmd.AddUpgradeCommand(rootCmd)
 
 	// check for a new release in the background while the command runs
@@ -95,8 +100,9 @@ func main() {
 
 		if commons.HasNewRelease(commons.GetClientVersion(), release.Version()) {
 			// found a new release
+			// stderr, not to be mixed with data written to stdout, e.g., by cat
 			notifyIfNewRelease = func() {
-				terminal.Printf("A newer version v%s is available. Run 'mdrepo upgrade' to update.\n", release.Version())
+				terminal.Fprintf(os.Stderr, "A newer version v%s is available. Run 'mdrepo upgrade' to update.\n", release.Version())
 			}
 		}
 	}()
@@ -218,6 +224,17 @@ func main() {
 			} else {
 				terminal.PrintErrorf("submit metadata error!\n")
 			}
+		} else if types.IsSubmitMappingError(err) {
+			var mappingError *types.SubmitMappingError
+			if errors.As(err, &mappingError) {
+				terminal.PrintErrorf("MD-Repo simulation directories do not match tickets!\n")
+				for problemIdx, problem := range mappingError.Problems {
+					terminal.PrintErrorf("[%d] %s\n", problemIdx+1, problem)
+				}
+				terminal.PrintErrorf("Check the mapping file %q or provide one with --mapping.\n", mappingError.MappingPath)
+			} else {
+				terminal.PrintErrorf("MD-Repo simulation directories do not match tickets!\n")
+			}
 		} else if types.IsSimulationNoNotMatchingError(err) {
 			var matchingError *types.SimulationNoNotMatchingError
 			if errors.As(err, &matchingError) {
@@ -232,7 +249,7 @@ func main() {
 				}
 
 				if len(matchingError.InvalidSimulationPaths) > 0 {
-					terminal.PrintErrorf("the directories ignored due to lack of metadata file:\n")
+					terminal.PrintErrorf("the directories ignored:\n")
 					for sourceIdx, sourcePath := range matchingError.InvalidSimulationPaths {
 						if len(matchingError.InvalidSimulationPathsErrors) > sourceIdx {
 							terminal.PrintErrorf("[%d] %s: %s\n", sourceIdx+1, sourcePath, matchingError.InvalidSimulationPathsErrors[sourceIdx])
@@ -244,6 +261,44 @@ func main() {
 			} else {
 				terminal.PrintErrorf("MD-Repo simulation number not matching error!\n")
 			}
+		} else if types.IsSubmissionRejectedError(err) {
+			var rejectedError *types.SubmissionRejectedError
+			if errors.As(err, &rejectedError) {
+				terminal.PrintErrorf("MD-Repo would reject %d simulation directories!\n", len(rejectedError.RejectedSimulationPaths))
+				for sourceIdx, sourcePath := range rejectedError.RejectedSimulationPaths {
+					terminal.PrintErrorf("[%d] %s\n", sourceIdx+1, sourcePath)
+				}
+			} else {
+				terminal.PrintErrorf("MD-Repo would reject the simulation directories!\n")
+			}
+		} else if types.IsSubmissionNotMatchingError(err) {
+			var notMatchingError *types.SubmissionNotMatchingError
+			if errors.As(err, &notMatchingError) {
+				terminal.PrintErrorf("%d files do not match submitted data!\n", notMatchingError.Differences)
+				for sourceIdx, sourcePath := range notMatchingError.SimulationPaths {
+					terminal.PrintErrorf("[%d] %s\n", sourceIdx+1, sourcePath)
+				}
+			} else {
+				terminal.PrintErrorf("Local files do not match submitted data!\n")
+			}
+		} else if types.IsDamagedDownloadError(err) {
+			var damagedError *types.DamagedDownloadError
+			if errors.As(err, &damagedError) {
+				terminal.PrintErrorf("%d downloaded files are missing or corrupt!\n", damagedError.DamagedFiles)
+				for sourceIdx, sourcePath := range damagedError.SimulationPaths {
+					terminal.PrintErrorf("[%d] %s\n", sourceIdx+1, sourcePath)
+				}
+				terminal.PrintErrorf("Use --repair to download them again.\n")
+			} else {
+				terminal.PrintErrorf("Downloaded files are missing or corrupt!\n")
+			}
+		} else if types.IsChecksumMismatchError(err) {
+			var checksumMismatchError *types.ChecksumMismatchError
+			if errors.As(err, &checksumMismatchError) {
+				terminal.PrintErrorf("Data of %q is corrupt, %s checksum does not match (expected %s, actual %s)!\n", checksumMismatchError.Path, checksumMismatchError.Algorithm, checksumMismatchError.ExpectedChecksum, checksumMismatchError.ActualChecksum)
+			} else {
+				terminal.PrintErrorf("Data is corrupt, checksum does not match!\n")
+			}
 		} else if types.IsNotDirError(err) {
 			var notDirError *types.NotDirError
 			if errors.As(err, &notDirError) {
@@ -0,0 +1,125 @@
+package subcmd
+
+import (
+	"fmt"
+
+	"github.com/MD-Repo/md-repo-cli/cmd/flag"
+	"github.com/MD-Repo/md-repo-cli/commons/format"
+	"github.com/MD-Repo/md-repo-cli/commons/hashcache"
+	"github.com/MD-Repo/md-repo-cli/commons/terminal"
+	"github.com/MD-Repo/md-repo-cli/commons/types"
+	"github.com/cockroachdb/errors"
+	"github.com/spf13/cobra"
+)
+
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Inspect or invalidate the local hash cache",
+	Long:  "This command lists hashes of local files cached by submit and get. Use --prune to remove hashes of missing or changed files, or --clear to remove all.",
+	RunE:  processCacheCommand,
+	Args:  cobra.NoArgs,
+}
+
+func AddCacheCommand(rootCmd *cobra.Command) {
+	// attach common flags
+	flag.SetCommonFlags(cacheCmd)
+
+	flag.SetHashCacheManagementFlags(cacheCmd)
+	flag.SetOutputFormatFlags(cacheCmd, true)
+
+	rootCmd.AddCommand(cacheCmd)
+}
+
+func processCacheCommand(command *cobra.Command, args []string) error {
+	cache, err := NewCacheCommand(command, args)
+	if err != nil {
+		return err
+	}
+
+	return cache.Process()
+}
+
+type CacheCommand struct {
+	command *cobra.Command
+
+	commonFlagValues       *flag.CommonFlagValues
+	hashCacheFlagValues    *flag.HashCacheFlagValues
+	outputFormatFlagValues *flag.OutputFormatFlagValues
+}
+
+func NewCacheCommand(command *cobra.Command, args []string) (*CacheCommand, error) {
+	cache := &CacheCommand{
+		command: command,
+
+		commonFlagValues:       flag.GetCommonFlagValues(command),
+		hashCacheFlagValues:    flag.GetHashCacheFlagValues(),
+		outputFormatFlagValues: flag.GetOutputFormatFlagValues(),
+	}
+
+	return cache, nil
+}
+
+func (cache *CacheCommand) Process() error {
+	cont, err := flag.ProcessCommonFlags(cache.command)
+	if err != nil {
+		return errors.Wrapf(err, "failed to process common flags")
+	}
+
+	if !cont {
+		return nil
+	}
+
+	hashCache, err := hashcache.NewDefaultHashCache()
+	if err != nil {
+		return errors.Wrapf(err, "failed to open hash cache")
+	}
+
+	if cache.hashCacheFlagValues.Clear {
+		removed := hashCache.Clear()
+		err = hashCache.Save()
+		if err != nil {
+			return errors.Wrapf(err, "failed to save hash cache")
+		}
+
+		terminal.Printf("removed %d cached hashes from %q\n", removed, hashCache.GetPath())
+		return nil
+	}
+
+	if cache.hashCacheFlagValues.Prune {
+		removed := hashCache.Prune()
+		err = hashCache.Save()
+		if err != nil {
+			return errors.Wrapf(err, "failed to save hash cache")
+		}
+
+		terminal.Printf("removed %d stale cached hashes from %q\n", removed, hashCache.GetPath())
+		return nil
+	}
+
+	outputFormatter := format.NewOutputFormatter(terminal.GetTerminalWriter())
+	outputFormatterTable := outputFormatter.NewTable(fmt.Sprintf("Hash Cache (%s)", hashCache.GetPath()))
+
+	outputFormatterTable.SetHeader([]string{
+		"Path",
+		"Size",
+		"Modified",
+		"Algorithm",
+		"Hash",
+		"Cached At",
+	})
+
+	for _, entry := range hashCache.List() {
+		outputFormatterTable.AppendRow([]interface{}{
+			entry.Path,
+			types.SizeString(entry.Size),
+			types.MakeDateTimeString(entry.ModTime),
+			entry.Algorithm,
+			entry.Hash,
+			types.MakeDateTimeString(entry.CachedAt),
+		})
+	}
+
+	outputFormatter.Render(cache.outputFormatFlagValues.Format)
+
+	return nil
+}
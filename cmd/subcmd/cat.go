@@ -0,0 +1,278 @@
+package subcmd
+
+import (
+	"bytes"
+	"encoding/hex"
+	"hash"
+	"io"
+	"os"
+
+	"github.com/cockroachdb/errors"
+	irodsclient_fs "github.com/cyverse/go-irodsclient/fs"
+	irodsclient_types "github.com/cyverse/go-irodsclient/irods/types"
+
+	"github.com/MD-Repo/md-repo-cli/cmd/flag"
+	"github.com/MD-Repo/md-repo-cli/commons/config"
+	"github.com/MD-Repo/md-repo-cli/commons/irods"
+	"github.com/MD-Repo/md-repo-cli/commons/mdrepo"
+	"github.com/MD-Repo/md-repo-cli/commons/terminal"
+	"github.com/MD-Repo/md-repo-cli/commons/transfer"
+	"github.com/MD-Repo/md-repo-cli/commons/types"
+	"github.com/MD-Repo/md-repo-cli/commons/webdav"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var catCmd = &cobra.Command{
+	Use:   "cat <file path>",
+	Short: "Stream a released MD-Repo file to stdout",
+	Long:  `This streams a released MD-Repo file to stdout, without saving it to disk. The file path starts with the simulation (e.g., MDR00001234/traj.xtc).`,
+	RunE:  processCatCommand,
+	Args:  cobra.ExactArgs(1),
+}
+
+func AddCatCommand(rootCmd *cobra.Command) {
+	// attach common flags
+	flag.SetCommonFlags(catCmd)
+
+	flag.SetTokenFlags(catCmd)
+	flag.SetCatFlags(catCmd)
+
+	rootCmd.AddCommand(catCmd)
+}
+
+func processCatCommand(command *cobra.Command, args []string) error {
+	cat, err := NewCatCommand(command, args)
+	if err != nil {
+		return err
+	}
+
+	return cat.Process()
+}
+
+type CatCommand struct {
+	command *cobra.Command
+
+	commonFlagValues *flag.CommonFlagValues
+	tokenFlagValues  *flag.TokenFlagValues
+	catFlagValues    *flag.CatFlagValues
+
+	account    *irodsclient_types.IRODSAccount
+	filesystem *irodsclient_fs.FileSystem
+
+	sourcePath string
+
+	config *config.Config
+}
+
+func NewCatCommand(command *cobra.Command, args []string) (*CatCommand, error) {
+	cat := &CatCommand{
+		command: command,
+
+		commonFlagValues: flag.GetCommonFlagValues(command),
+		tokenFlagValues:  flag.GetTokenFlagValues(),
+		catFlagValues:    flag.GetCatFlagValues(),
+
+		config: config.GetConfig(),
+	}
+
+	// path
+	cat.sourcePath = args[0]
+
+	return cat, nil
+}
+
+func (cat *CatCommand) Process() error {
+	// stdout is for the file content, write logs to stderr
+	log.SetOutput(os.Stderr)
+
+	cont, err := flag.ProcessCommonFlags(cat.command)
+	if err != nil {
+		return errors.Wrapf(err, "failed to process common flags")
+	}
+
+	if !cont {
+		return nil
+	}
+
+	if cat.catFlagValues.Offset < 0 {
+		return errors.Errorf("invalid offset %d", cat.catFlagValues.Offset)
+	}
+
+	if cat.catFlagValues.Length < 0 {
+		return errors.Errorf("invalid length %d", cat.catFlagValues.Length)
+	}
+
+	// handle token
+	// the token is not asked as the prompt would be mixed with the file content
+	if len(cat.tokenFlagValues.TicketString) > 0 {
+		cat.config.TicketString = cat.tokenFlagValues.TicketString
+	}
+
+	if len(cat.tokenFlagValues.Token) > 0 {
+		cat.config.Token = cat.tokenFlagValues.Token
+	}
+
+	if len(cat.config.Token) > 0 && len(cat.config.TicketString) == 0 {
+		cat.config.TicketString, err = mdrepo.GetMDRepoTicketStringFromToken(cat.tokenFlagValues.ServiceURL, cat.config.Token)
+		if err != nil {
+			return errors.Wrapf(err, "failed to read ticket from token %q", cat.config.Token)
+		}
+	}
+
+	if len(cat.config.TicketString) == 0 {
+		return types.NewTokenNotProvidedError()
+	}
+
+	// get ticket
+	mdRepoTickets, err := mdrepo.GetMDRepoTicketsFromString(cat.config.TicketString)
+	if err != nil {
+		return errors.Wrapf(err, "failed to retrieve tickets")
+	}
+
+	mdRepoTicket, irodsPath, err := mdrepo.FindMDRepoTicketForReleasePath(mdRepoTickets, cat.sourcePath)
+	if err != nil {
+		return err
+	}
+
+	// we may further optimize this by run it parallel
+	cat.account, err = mdRepoTicket.GetAccount()
+	if err != nil {
+		return errors.Wrapf(err, "failed to get iRODS Account")
+	}
+
+	cat.filesystem, err = irods.GetIRODSFSClient(cat.account, false, cat.commonFlagValues.Timeout)
+	if err != nil {
+		return errors.Wrapf(err, "failed to get iRODS FS Client")
+	}
+	defer cat.filesystem.Release()
+
+	return cat.catOne(irodsPath)
+}
+
+func (cat *CatCommand) catOne(irodsPath string) error {
+	logger := log.WithFields(log.Fields{
+		"irods_path": irodsPath,
+		"offset":     cat.catFlagValues.Offset,
+		"length":     cat.catFlagValues.Length,
+	})
+
+	sourceEntry, err := cat.filesystem.Stat(irodsPath)
+	if err != nil {
+		return errors.Wrapf(err, "failed to stat %q", irodsPath)
+	}
+
+	if sourceEntry.IsDir() {
+		return types.NewNotFileError(irodsPath)
+	}
+
+	offset := cat.catFlagValues.Offset
+	if offset > sourceEntry.Size {
+		return errors.Errorf("offset %d is beyond the size of %q (%d bytes)", offset, irodsPath, sourceEntry.Size)
+	}
+
+	length := sourceEntry.Size - offset
+	if cat.catFlagValues.Length > 0 && cat.catFlagValues.Length < length {
+		length = cat.catFlagValues.Length
+	}
+
+	logger.Debug("streaming a data object")
+
+	reader, err := cat.openReader(irodsPath, offset, length)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	// checksum can be verified only when the whole file is read
+	var checksumHash hash.Hash
+	var dataReader io.Reader = reader
+	if offset == 0 && length == sourceEntry.Size && len(sourceEntry.CheckSum) > 0 {
+		checksumHash, err = transfer.NewChecksumHash(string(sourceEntry.CheckSumAlgorithm))
+		if err != nil {
+			logger.WithError(err).Warn("failed to verify checksum")
+			checksumHash = nil
+		} else {
+			dataReader = io.TeeReader(reader, checksumHash)
+		}
+	}
+
+	written, err := io.Copy(os.Stdout, dataReader)
+	if err != nil {
+		return errors.Wrapf(err, "failed to stream %q", irodsPath)
+	}
+
+	if written != length {
+		return errors.Errorf("failed to stream %q, %d bytes read, expected %d bytes", irodsPath, written, length)
+	}
+
+	if checksumHash == nil {
+		terminal.Fprintf(os.Stderr, "checksum of %q is not verified (%s)\n", irodsPath, cat.getUnverifiedReason(sourceEntry, offset, length))
+		return nil
+	}
+
+	actualChecksum := checksumHash.Sum(nil)
+	if !bytes.Equal(actualChecksum, sourceEntry.CheckSum) {
+		return types.NewChecksumMismatchError(irodsPath, string(sourceEntry.CheckSumAlgorithm), hex.EncodeToString(sourceEntry.CheckSum), hex.EncodeToString(actualChecksum))
+	}
+
+	terminal.Fprintf(os.Stderr, "checksum of %q is verified (%s %s)\n", irodsPath, sourceEntry.CheckSumAlgorithm, hex.EncodeToString(actualChecksum))
+	return nil
+}
+
+// openReader returns a reader of length bytes of the data object from offset
+func (cat *CatCommand) openReader(irodsPath string, offset int64, length int64) (io.ReadCloser, error) {
+	if cat.catFlagValues.WebDAV {
+		webdavClient, err := webdav.NewWebDAVClient(cat.filesystem, config.MDRepoWebDAVServerURL+config.MDRepoWebDAVPrefix, cat.account.ProxyUser, cat.account.Password)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to create WebDAV client")
+		}
+
+		return openWebDAVReader(webdavClient, irodsPath, offset, length)
+	}
+
+	handle, err := cat.filesystem.OpenFile(irodsPath, "", "r")
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to open %q", irodsPath)
+	}
+
+	if offset > 0 {
+		_, err = handle.Seek(offset, io.SeekStart)
+		if err != nil {
+			handle.Close()
+			return nil, errors.Wrapf(err, "failed to seek %q to %d", irodsPath, offset)
+		}
+	}
+
+	return &limitedReadCloser{
+		Reader: io.LimitReader(handle, length),
+		Closer: handle,
+	}, nil
+}
+
+// openWebDAVReader returns a reader of length bytes of the data object from offset via WebDAV
+func openWebDAVReader(webdavClient *webdav.WebDAVClient, irodsPath string, offset int64, length int64) (io.ReadCloser, error) {
+	if length == 0 {
+		// no range to request
+		return io.NopCloser(bytes.NewReader(nil)), nil
+	}
+
+	return webdavClient.ReadFileRange(irodsPath, "", offset, length)
+}
+
+func (cat *CatCommand) getUnverifiedReason(sourceEntry *irodsclient_fs.Entry, offset int64, length int64) string {
+	if offset != 0 || length != sourceEntry.Size {
+		return "partial read"
+	}
+
+	if len(sourceEntry.CheckSum) == 0 {
+		return "no checksum"
+	}
+
+	return "unsupported checksum algorithm"
+}
+
+type limitedReadCloser struct {
+	io.Reader
+	io.Closer
+}
@@ -0,0 +1,59 @@
+package subcmd
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MD-Repo/md-repo-cli/commons/webdav"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCat(t *testing.T) {
+	t.Run("test OpenWebDAVReader", testOpenWebDAVReader)
+}
+
+func testOpenWebDAVReader(t *testing.T) {
+	data := "0123456789"
+
+	// a local WebDAV server under /dav, like the MD-Repo WebDAV server
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodOptions:
+			w.Header().Set("DAV", "1")
+			w.WriteHeader(http.StatusOK)
+		case http.MethodGet:
+			if r.URL.Path != "/dav/iplant/home/shared/mdrepo/release/MDR00000001/traj.xtc" {
+				http.NotFound(w, r)
+				return
+			}
+
+			http.ServeContent(w, r, "traj.xtc", time.Now(), strings.NewReader(data))
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	}))
+	defer server.Close()
+
+	webdavClient, err := webdav.NewWebDAVClient(nil, server.URL+"/dav", "user", "password")
+	assert.NoError(t, err)
+
+	readAll := func(offset int64, length int64) string {
+		reader, err := openWebDAVReader(webdavClient, "/iplant/home/shared/mdrepo/release/MDR00000001/traj.xtc", offset, length)
+		if !assert.NoError(t, err) {
+			return ""
+		}
+		defer reader.Close()
+
+		readBytes, err := io.ReadAll(reader)
+		assert.NoError(t, err)
+		return string(readBytes)
+	}
+
+	assert.Equal(t, data, readAll(0, int64(len(data))))
+	assert.Equal(t, "345", readAll(3, 3))
+	assert.Equal(t, "", readAll(10, 0))
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/hex"
 	"fmt"
 	"os"
+	"path"
 	"path/filepath"
 	"time"
 
@@ -13,11 +14,13 @@ import (
 	irodsclient_fs "github.com/cyverse/go-irodsclient/fs"
 	irodsclient_irodsfs "github.com/cyverse/go-irodsclient/irods/fs"
 	irodsclient_types "github.com/cyverse/go-irodsclient/irods/types"
-	irodsclient_util "github.com/cyverse/go-irodsclient/irods/util"
 	"github.com/jedib0t/go-pretty/v6/progress"
 
 	"github.com/MD-Repo/md-repo-cli/cmd/flag"
+	"github.com/MD-Repo/md-repo-cli/commons/archive"
 	"github.com/MD-Repo/md-repo-cli/commons/config"
+	"github.com/MD-Repo/md-repo-cli/commons/format"
+	"github.com/MD-Repo/md-repo-cli/commons/hashcache"
 	"github.com/MD-Repo/md-repo-cli/commons/irods"
 	"github.com/MD-Repo/md-repo-cli/commons/mdrepo"
 	"github.com/MD-Repo/md-repo-cli/commons/parallel"
@@ -49,6 +52,11 @@ func AddGetCommand(rootCmd *cobra.Command) {
 	flag.SetProgressFlags(getCmd)
 	flag.SetRetryFlags(getCmd)
 	flag.SetTransferReportFlags(getCmd)
+	flag.SetHashCacheFlags(getCmd)
+	flag.SetDryRunFlags(getCmd)
+	flag.SetDownloadFilterFlags(getCmd)
+	flag.SetArchiveFlags(getCmd)
+	flag.SetOutputFormatFlags(getCmd, true)
 
 	rootCmd.AddCommand(getCmd)
 }
@@ -72,6 +80,11 @@ type GetCommand struct {
 	progressFlagValues         *flag.ProgressFlagValues
 	retryFlagValues            *flag.RetryFlagValues
 	transferReportFlagValues   *flag.TransferReportFlagValues
+	hashCacheFlagValues        *flag.HashCacheFlagValues
+	dryRunFlagValues           *flag.DryRunFlagValues
+	outputFormatFlagValues     *flag.OutputFormatFlagValues
+	downloadFilterFlagValues   *flag.DownloadFilterFlagValues
+	archiveFlagValues          *flag.ArchiveFlagValues
 
 	maxConnectionNum int
 
@@ -85,6 +98,14 @@ type GetCommand struct {
 
 	transferReportManager *transfer.TransferReportManager
 	config                *config.Config
+	hashCache             *hashcache.HashCache
+	transferPlan          *transfer.TransferPlan
+	downloadFilter        *mdrepo.DownloadFilter
+	downloadRoles         []mdrepo.DownloadRole
+	downloadSelections    map[string]mdrepo.DownloadRoleSelection // key: IRODSDataPath of ticket
+	manifests             map[string]*mdrepo.DownloadManifest     // key: IRODSDataPath of ticket
+	archiveWriter         *archive.ArchiveWriter
+	archiveIndex          *archive.ArchiveIndex // for dry-run
 
 	totalDownloadedFiles int
 	totalDownloadedBytes int64
@@ -102,8 +123,15 @@ func NewGetCommand(command *cobra.Command, args []string) (*GetCommand, error) {
 		progressFlagValues:         flag.GetProgressFlagValues(),
 		retryFlagValues:            flag.GetRetryFlagValues(),
 		transferReportFlagValues:   flag.GetTransferReportFlagValues(command),
+		hashCacheFlagValues:        flag.GetHashCacheFlagValues(),
+		dryRunFlagValues:           flag.GetDryRunFlagValues(),
+		outputFormatFlagValues:     flag.GetOutputFormatFlagValues(),
+		downloadFilterFlagValues:   flag.GetDownloadFilterFlagValues(),
+		archiveFlagValues:          flag.GetArchiveFlagValues(),
 
 		config:               config.GetConfig(),
+		manifests:            map[string]*mdrepo.DownloadManifest{},
+		downloadSelections:   map[string]mdrepo.DownloadRoleSelection{},
 		totalDownloadedFiles: 0,
 		totalDownloadedBytes: 0,
 		startTime:            time.Now(),
@@ -122,6 +150,8 @@ func NewGetCommand(command *cobra.Command, args []string) (*GetCommand, error) {
 }
 
 func (get *GetCommand) Process() error {
+	logger := log.WithFields(log.Fields{})
+
 	terminal.Printf("downloading MD-Repo data to a local directory\n")
 
 	cont, err := flag.ProcessCommonFlags(get.command)
@@ -133,6 +163,51 @@ func (get *GetCommand) Process() error {
 		return nil
 	}
 
+	// download filter
+	maxFileSize := int64(0)
+	if len(get.downloadFilterFlagValues.MaxFileSize) > 0 {
+		maxFileSize, err = types.ParseSize(get.downloadFilterFlagValues.MaxFileSize)
+		if err != nil {
+			return errors.Wrapf(err, "failed to parse max file size %q", get.downloadFilterFlagValues.MaxFileSize)
+		}
+	}
+
+	get.downloadFilter, err = mdrepo.NewDownloadFilter(get.downloadFilterFlagValues.Include, get.downloadFilterFlagValues.Exclude, maxFileSize)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create download filter")
+	}
+
+	if len(get.archiveFlagValues.Archive) > 0 {
+		_, err = archive.GetArchiveFormat(get.archiveFlagValues.Archive)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(get.downloadFilterFlagValues.Only) > 0 {
+		get.downloadRoles, err = mdrepo.ParseDownloadRoles(get.downloadFilterFlagValues.Only)
+		if err != nil {
+			return errors.Wrapf(err, "failed to parse roles %q", get.downloadFilterFlagValues.Only)
+		}
+	}
+
+	// hash cache
+	if !get.hashCacheFlagValues.NoHashCache {
+		get.hashCache, err = hashcache.NewDefaultHashCache()
+		if err != nil {
+			// run without hash cache
+			logger.WithError(err).Warn("failed to open hash cache")
+			get.hashCache = nil
+		} else {
+			defer func() {
+				saveErr := get.hashCache.Save()
+				if saveErr != nil {
+					logger.WithError(saveErr).Warn("failed to save hash cache")
+				}
+			}()
+		}
+	}
+
 	// handle token
 	if len(get.tokenFlagValues.TicketString) > 0 {
 		get.config.TicketString = get.tokenFlagValues.TicketString
@@ -166,16 +241,38 @@ func (get *GetCommand) Process() error {
 	}
 
 	// transfer report
-	get.transferReportManager, err = transfer.NewTransferReportManager(get.transferReportFlagValues.Report, get.transferReportFlagValues.ReportPath, get.transferReportFlagValues.ReportToStdout)
+	// dry-run does not write reports
+	report := get.transferReportFlagValues.Report && !get.dryRunFlagValues.DryRun
+	get.transferReportManager, err = transfer.NewTransferReportManager(report, get.transferReportFlagValues.ReportPath, get.transferReportFlagValues.ReportToStdout)
 	if err != nil {
 		return errors.Wrapf(err, "failed to create transfer report manager")
 	}
 	defer get.transferReportManager.Release()
 
 	// run
-	err = get.ensureTargetIsDir(get.targetPath)
-	if err != nil {
-		return err
+	if len(get.archiveFlagValues.Archive) > 0 {
+		archivePath := commons_path.MakeLocalPath(get.archiveFlagValues.Archive)
+
+		if get.dryRunFlagValues.DryRun {
+			get.archiveIndex = archive.ReadArchiveIndex(archivePath)
+		} else {
+			get.archiveWriter, err = archive.NewArchiveWriter(archivePath, get.forceFlagValues.Force)
+			if err != nil {
+				return errors.Wrapf(err, "failed to open archive %q", archivePath)
+			}
+			defer func() {
+				// entries written are kept readable even if transfer failed
+				closeErr := get.archiveWriter.Close()
+				if closeErr != nil {
+					logger.WithError(closeErr).Errorf("failed to close archive %q", archivePath)
+				}
+			}()
+		}
+	} else {
+		err = get.ensureTargetIsDir(get.targetPath)
+		if err != nil {
+			return err
+		}
 	}
 
 	// group tickets by IRODSTicket to share filesystem and parallel job manager
@@ -188,6 +285,10 @@ func (get *GetCommand) Process() error {
 		ticketGroups[mdRepoTicket.IRODSTicket] = append(ticketGroups[mdRepoTicket.IRODSTicket], mdRepoTicket)
 	}
 
+	if get.dryRunFlagValues.DryRun {
+		get.transferPlan = transfer.NewTransferPlan()
+	}
+
 	for _, irodsTicket := range ticketGroupOrder {
 		group := ticketGroups[irodsTicket]
 		err = get.processTicketGroup(group)
@@ -196,6 +297,13 @@ func (get *GetCommand) Process() error {
 		}
 	}
 
+	if get.dryRunFlagValues.DryRun {
+		outputFormatter := format.NewOutputFormatter(terminal.GetTerminalWriter())
+		get.transferPlan.MakeOutputTables(outputFormatter, "Download Plan")
+		outputFormatter.Render(get.outputFormatFlagValues.Format)
+		return nil
+	}
+
 	// print final summary
 	if !get.progressFlagValues.NoProgress {
 		timeTaken := time.Since(get.startTime).Seconds()
@@ -241,10 +349,18 @@ func (get *GetCommand) processTicketGroup(mdRepoTickets []mdrepo.MDRepoTicket) e
 
 	// parallel job manager - created once for the entire ticket group
 	ioSession := get.filesystem.GetIOSession()
-	get.parallelTransferJobManager = parallel.NewParallelJobManager(ioSession.GetMaxConnections(), !get.progressFlagValues.NoProgress, get.progressFlagValues.ShowFullPath, get.parallelTransferFlagValues.StopOnError)
+	weightCapacity := ioSession.GetMaxConnections()
+	if get.isArchiveMode() {
+		// entries are written to the archive one at a time, so data objects are read one at a time
+		weightCapacity = 1
+	}
+
+	get.parallelTransferJobManager = parallel.NewParallelJobManager(weightCapacity, !get.progressFlagValues.NoProgress, get.progressFlagValues.ShowFullPath, get.parallelTransferFlagValues.StopOnError)
 
 	// schedule all paths in this ticket group
-	terminal.Printf("scheduling transfer...\n")
+	if !get.dryRunFlagValues.DryRun {
+		terminal.Printf("scheduling transfer...\n")
+	}
 
 	for i := range mdRepoTickets {
 		mdRepoTicket := &mdRepoTickets[i]
@@ -254,11 +370,21 @@ func (get *GetCommand) processTicketGroup(mdRepoTickets []mdrepo.MDRepoTicket) e
 			return errors.Wrapf(err, "failed to extract data path from %q", mdRepoTicket.IRODSDataPath)
 		}
 
+		if get.isArchiveMode() {
+			err = get.archiveOne(mdRepoTicket, dataRelPath)
+			if err != nil {
+				return errors.Wrapf(err, "failed to archive %q", mdRepoTicket.IRODSDataPath)
+			}
+			continue
+		}
+
 		dataTargetPath := filepath.Join(get.targetPath, filepath.FromSlash(dataRelPath))
-		targetParentDir := filepath.Dir(dataTargetPath)
-		err = os.MkdirAll(targetParentDir, 0766)
-		if err != nil {
-			return errors.Wrapf(err, "failed to make a directory %q", targetParentDir)
+		if !get.dryRunFlagValues.DryRun {
+			targetParentDir := filepath.Dir(dataTargetPath)
+			err = os.MkdirAll(targetParentDir, 0766)
+			if err != nil {
+				return errors.Wrapf(err, "failed to make a directory %q", targetParentDir)
+			}
 		}
 
 		err = get.getOne(mdRepoTicket, dataTargetPath)
@@ -267,10 +393,24 @@ func (get *GetCommand) processTicketGroup(mdRepoTickets []mdrepo.MDRepoTicket) e
 		}
 	}
 
+	if get.dryRunFlagValues.DryRun {
+		// plan only, nothing is scheduled
+		return nil
+	}
+
 	// start all scheduled transfers at once
 	terminal.Printf("start transfer...\n")
 
 	transferErr := get.parallelTransferJobManager.Start()
+
+	// save manifests even if some transfers failed, files downloaded are listed
+	for i := range mdRepoTickets {
+		err = get.saveManifest(&mdRepoTickets[i])
+		if err != nil {
+			return err
+		}
+	}
+
 	if transferErr != nil {
 		return errors.Wrap(transferErr, "failed to perform transfer jobs")
 	}
@@ -326,6 +466,20 @@ func (get *GetCommand) getOne(mdRepoTicket *mdrepo.MDRepoTicket, targetPath stri
 
 	if sourceEntry.IsDir() {
 		// dir
+		if !get.dryRunFlagValues.DryRun {
+			simulation, err := mdrepo.GetMDRepoSimulationRelPath(mdRepoTicket.IRODSDataPath)
+			if err != nil {
+				return errors.Wrapf(err, "failed to extract data path from %q", mdRepoTicket.IRODSDataPath)
+			}
+
+			get.manifests[mdRepoTicket.IRODSDataPath] = mdrepo.NewDownloadManifest(targetPath, simulation, sourcePath)
+		}
+
+		err = get.selectFilesByRoles(mdRepoTicket, sourcePath)
+		if err != nil {
+			return err
+		}
+
 		// save content to target path without creating a subdir for the source dir
 		return get.getDir(mdRepoTicket, sourceEntry, targetPath)
 	}
@@ -335,6 +489,214 @@ func (get *GetCommand) getOne(mdRepoTicket *mdrepo.MDRepoTicket, targetPath stri
 	return get.getFile(mdRepoTicket, sourceEntry, "", targetPath)
 }
 
+func (get *GetCommand) isArchiveMode() bool {
+	return get.archiveWriter != nil || get.archiveIndex != nil
+}
+
+// archiveOne writes the released data to the archive, in the same layout as in a local directory
+func (get *GetCommand) archiveOne(mdRepoTicket *mdrepo.MDRepoTicket, dataRelPath string) error {
+	sourcePath := commons_path.MakeIRODSReleasePath(mdRepoTicket.IRODSDataPath)
+
+	sourceEntry, err := get.filesystem.Stat(sourcePath)
+	if err != nil {
+		return errors.Wrapf(err, "failed to stat %q", sourcePath)
+	}
+
+	if sourceEntry.IsDir() {
+		err = get.selectFilesByRoles(mdRepoTicket, sourcePath)
+		if err != nil {
+			return err
+		}
+
+		return get.archiveDir(mdRepoTicket, sourceEntry, dataRelPath)
+	}
+
+	return get.archiveFile(mdRepoTicket, sourceEntry, dataRelPath)
+}
+
+func (get *GetCommand) archiveDir(mdRepoTicket *mdrepo.MDRepoTicket, sourceEntry *irodsclient_fs.Entry, entryPath string) error {
+	entries, err := get.filesystem.List(sourceEntry.Path)
+	if err != nil {
+		return errors.Wrapf(err, "failed to list a directory %q", sourceEntry.Path)
+	}
+
+	for _, entry := range entries {
+		newEntryPath := path.Join(entryPath, entry.Name)
+
+		if get.filterEntry(mdRepoTicket, entry, newEntryPath) {
+			continue
+		}
+
+		if entry.IsDir() {
+			err = get.archiveDir(mdRepoTicket, entry, newEntryPath)
+		} else {
+			err = get.archiveFile(mdRepoTicket, entry, newEntryPath)
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (get *GetCommand) archiveFile(mdRepoTicket *mdrepo.MDRepoTicket, sourceEntry *irodsclient_fs.Entry, entryPath string) error {
+	if get.dryRunFlagValues.DryRun {
+		if get.archiveIndex.HasEntry(entryPath, sourceEntry.Size, string(sourceEntry.CheckSumAlgorithm), sourceEntry.CheckSum) {
+			get.planGet(mdRepoTicket, transfer.TransferPlanActionSkip, sourceEntry, entryPath, sourceEntry.Size, "in archive")
+			return nil
+		}
+
+		get.planGet(mdRepoTicket, transfer.TransferPlanActionNew, sourceEntry, entryPath, 0, "archive")
+		return nil
+	}
+
+	if !get.forceFlagValues.Force && get.archiveWriter.HasEntry(entryPath, sourceEntry.Size, string(sourceEntry.CheckSumAlgorithm), sourceEntry.CheckSum) {
+		now := time.Now()
+		reportFile := &transfer.TransferReportFile{
+			Method:                  transfer.TransferMethodGet,
+			StartAt:                 now,
+			EndAt:                   now,
+			SourcePath:              sourceEntry.Path,
+			SourceSize:              sourceEntry.Size,
+			SourceChecksumAlgorithm: string(sourceEntry.CheckSumAlgorithm),
+			SourceChecksum:          hex.EncodeToString(sourceEntry.CheckSum),
+			DestPath:                entryPath,
+			DestSize:                sourceEntry.Size,
+			Notes:                   []string{"get", "archive", "in archive", "skipped"},
+		}
+
+		get.transferReportManager.AddFile(reportFile)
+
+		terminal.Printf("skip archiving a data object %q to %q. The entry already exists!\n", sourceEntry.Path, entryPath)
+		return nil
+	}
+
+	get.scheduleArchive(mdRepoTicket, sourceEntry, entryPath)
+	return nil
+}
+
+// scheduleArchive schedules streaming a data object into an archive entry
+// entries are written one at a time, data is verified with the checksum while streaming
+func (get *GetCommand) scheduleArchive(mdRepoTicket *mdrepo.MDRepoTicket, sourceEntry *irodsclient_fs.Entry, entryPath string) {
+	logger := log.WithFields(log.Fields{
+		"irods_data_path": mdRepoTicket.IRODSDataPath,
+		"irods_ticket":    mdRepoTicket.IRODSTicket,
+		"source_path":     sourceEntry.Path,
+		"entry_path":      entryPath,
+	})
+
+	report := func(startTime time.Time, err error, additionalNotes ...string) {
+		reportFile := &transfer.TransferReportFile{
+			Method:                  transfer.TransferMethodGet,
+			StartAt:                 startTime,
+			EndAt:                   time.Now(),
+			SourcePath:              sourceEntry.Path,
+			SourceSize:              sourceEntry.Size,
+			SourceChecksumAlgorithm: string(sourceEntry.CheckSumAlgorithm),
+			SourceChecksum:          hex.EncodeToString(sourceEntry.CheckSum),
+			DestPath:                entryPath,
+			Error:                   err,
+			Notes:                   append([]string{"get", "archive"}, additionalNotes...),
+		}
+
+		if err == nil {
+			reportFile.DestSize = sourceEntry.Size
+			reportFile.DestChecksumAlgorithm = reportFile.SourceChecksumAlgorithm
+			reportFile.DestChecksum = reportFile.SourceChecksum
+		}
+
+		get.transferReportManager.AddFile(reportFile)
+	}
+
+	archiveTask := func(job *parallel.ParallelJob) error {
+		startTime := time.Now()
+
+		if job.IsCanceled() {
+			// job is canceled, do not run
+			job.Progress("download", -1, sourceEntry.Size, true)
+
+			report(startTime, nil, "canceled")
+			logger.Debug("canceled a task for archiving a data object")
+			return nil
+		}
+
+		logger.Debug("archiving a data object")
+
+		job.Progress("download", 0, sourceEntry.Size, false)
+
+		retryNum := get.retryFlagValues.GetRetryNumber()
+		retryInterval := get.retryFlagValues.GetRetryIntervalSeconds()
+
+		attempt := 0
+		retryErr := retry.Do(func() error {
+			attempt++
+			if attempt > 1 {
+				logger.Debugf("retrying archive attempt %d/%d for %q", attempt, retryNum+1, sourceEntry.Path)
+			}
+
+			handle, err := get.filesystem.OpenFile(sourceEntry.Path, "", "r")
+			if err != nil {
+				return errors.Wrapf(err, "failed to open %q", sourceEntry.Path)
+			}
+
+			processed := int64(0)
+			reader := webdav.NewReaderWithProgress(handle, func(readSize int) {
+				processed += int64(readSize)
+				job.Progress("download", processed, sourceEntry.Size, false)
+			})
+			defer reader.Close()
+
+			return get.archiveWriter.WriteEntry(entryPath, sourceEntry.Size, sourceEntry.ModifyTime, reader, string(sourceEntry.CheckSumAlgorithm), sourceEntry.CheckSum)
+		}, retry.Attempts(uint(retryNum+1)), retry.Delay(retryInterval), retry.LastErrorOnly(true), retry.RetryIf(func(err error) bool {
+			// corrupt data is not fixed by retrying
+			return !types.IsChecksumMismatchError(err)
+		}))
+
+		if retryErr != nil {
+			job.Progress("download", -1, sourceEntry.Size, true)
+
+			report(startTime, retryErr, fmt.Sprintf("%d attempts", attempt))
+			return errors.Wrapf(retryErr, "failed to archive %q to %q", sourceEntry.Path, entryPath)
+		}
+
+		get.totalDownloadedFiles++
+		get.totalDownloadedBytes += sourceEntry.Size
+
+		report(startTime, nil, fmt.Sprintf("%d attempts", attempt))
+
+		logger.Debugf("archived a data object %q to %q", sourceEntry.Path, entryPath)
+		return nil
+	}
+
+	get.parallelTransferJobManager.Schedule(sourceEntry.Path, archiveTask, 1, progress.UnitsBytes)
+	logger.Debugf("scheduled archiving a data object %q to %q", sourceEntry.Path, entryPath)
+}
+
+// selectFilesByRoles reads the released metadata and selects files of the requested roles
+func (get *GetCommand) selectFilesByRoles(mdRepoTicket *mdrepo.MDRepoTicket, sourcePath string) error {
+	if len(get.downloadRoles) == 0 {
+		return nil
+	}
+
+	metadataPath := path.Join(sourcePath, mdrepo.SubmissionMetadataFilename)
+
+	buffer := bytes.Buffer{}
+	_, err := get.filesystem.DownloadFileToBuffer(metadataPath, "", &buffer, false, nil)
+	if err != nil {
+		return errors.Wrapf(err, "failed to download metadata %q", metadataPath)
+	}
+
+	metadata, err := mdrepo.ParseSubmitMetadataString(buffer.String())
+	if err != nil {
+		return errors.Wrapf(err, "failed to parse metadata %q", metadataPath)
+	}
+
+	get.downloadSelections[mdRepoTicket.IRODSDataPath] = mdrepo.SelectDownloadFilesByRoles(metadata, get.downloadRoles)
+	return nil
+}
+
 func (get *GetCommand) scheduleGet(mdRepoTicket *mdrepo.MDRepoTicket, sourceEntry *irodsclient_fs.Entry, tempPath string, targetPath string) {
 	logger := log.WithFields(log.Fields{
 		"irods_data_path": mdRepoTicket.IRODSDataPath,
@@ -446,6 +808,11 @@ func (get *GetCommand) scheduleGet(mdRepoTicket *mdrepo.MDRepoTicket, sourceEntr
 
 		reportTransfer(downloadResult, downloadErr, notes...)
 
+		manifestErr := get.addManifestFile(mdRepoTicket, sourceEntry, targetPath, downloadResult)
+		if manifestErr != nil {
+			return manifestErr
+		}
+
 		logger.Debugf("downloaded a data object %q to %q", sourceEntry.Path, targetPath)
 
 		return nil
@@ -509,6 +876,11 @@ func (get *GetCommand) getFile(mdRepoTicket *mdrepo.MDRepoTicket, sourceEntry *i
 		if os.IsNotExist(err) {
 			// target does not exist
 			// target must be a file with new name
+			if get.dryRunFlagValues.DryRun {
+				get.planGet(mdRepoTicket, transfer.TransferPlanActionNew, sourceEntry, targetPath, 0)
+				return nil
+			}
+
 			get.scheduleGet(mdRepoTicket, sourceEntry, tempPath, targetPath)
 			return nil
 		}
@@ -528,6 +900,11 @@ func (get *GetCommand) getFile(mdRepoTicket *mdrepo.MDRepoTicket, sourceEntry *i
 	// check transfer status file
 	if get.hasTransferStatusFile(targetPath) {
 		// incomplete file - resume downloading
+		if get.dryRunFlagValues.DryRun {
+			get.planGet(mdRepoTicket, transfer.TransferPlanActionResume, sourceEntry, targetPath, targetStat.Size(), "transfer status file")
+			return nil
+		}
+
 		terminal.Printf("resume downloading a data object %q\n", targetPath)
 		logger.Debug("resume downloading a data object")
 
@@ -539,7 +916,7 @@ func (get *GetCommand) getFile(mdRepoTicket *mdrepo.MDRepoTicket, sourceEntry *i
 		if targetStat.Size() == sourceEntry.Size {
 			// compare hash
 			if len(sourceEntry.CheckSum) > 0 {
-				localChecksum, err := irodsclient_util.HashLocalFile(targetPath, string(sourceEntry.CheckSumAlgorithm), nil)
+				localChecksum, err := get.hashCache.HashLocalFile(targetPath, string(sourceEntry.CheckSumAlgorithm), nil)
 				if err != nil {
 					reportSimple(err, "differential")
 					return errors.Wrapf(err, "failed to get hash of %q", targetPath)
@@ -547,6 +924,11 @@ func (get *GetCommand) getFile(mdRepoTicket *mdrepo.MDRepoTicket, sourceEntry *i
 
 				if bytes.Equal(sourceEntry.CheckSum, localChecksum) {
 					// skip
+					if get.dryRunFlagValues.DryRun {
+						get.planGet(mdRepoTicket, transfer.TransferPlanActionSkip, sourceEntry, targetPath, targetStat.Size(), "same checksum")
+						return nil
+					}
+
 					now := time.Now()
 					reportFile := &transfer.TransferReportFile{
 						Method:                  transfer.TransferMethodGet,
@@ -566,6 +948,13 @@ func (get *GetCommand) getFile(mdRepoTicket *mdrepo.MDRepoTicket, sourceEntry *i
 
 					get.transferReportManager.AddFile(reportFile)
 
+					if manifest, ok := get.manifests[mdRepoTicket.IRODSDataPath]; ok {
+						err = manifest.AddUnchangedFile(targetPath, sourceEntry.Path, targetStat.Size(), string(sourceEntry.CheckSumAlgorithm), hex.EncodeToString(localChecksum))
+						if err != nil {
+							return errors.Wrapf(err, "failed to add %q to manifest", targetPath)
+						}
+					}
+
 					terminal.Printf("skip downloading a data object %q to %q. The file with the same hash already exists!\n", sourceEntry.Path, targetPath)
 					logger.Debug("skip downloading a data object. The file with the same hash already exists!")
 					return nil
@@ -574,11 +963,103 @@ func (get *GetCommand) getFile(mdRepoTicket *mdrepo.MDRepoTicket, sourceEntry *i
 		}
 	}
 
+	if get.dryRunFlagValues.DryRun {
+		notes := []string{}
+		if get.forceFlagValues.Force {
+			notes = append(notes, "force")
+		} else if targetStat.Size() != sourceEntry.Size {
+			notes = append(notes, "different size")
+		} else if len(sourceEntry.CheckSum) > 0 {
+			notes = append(notes, "different checksum")
+		} else {
+			notes = append(notes, "no checksum")
+		}
+
+		get.planGet(mdRepoTicket, transfer.TransferPlanActionOverwrite, sourceEntry, targetPath, targetStat.Size(), notes...)
+		return nil
+	}
+
 	// schedule
 	get.scheduleGet(mdRepoTicket, sourceEntry, tempPath, targetPath)
 	return nil
 }
 
+func (get *GetCommand) addManifestFile(mdRepoTicket *mdrepo.MDRepoTicket, sourceEntry *irodsclient_fs.Entry, targetPath string, downloadResult *irodsclient_fs.FileTransferResult) error {
+	manifest, ok := get.manifests[mdRepoTicket.IRODSDataPath]
+	if !ok {
+		return nil
+	}
+
+	downloadedAt := time.Now()
+	checksumAlgorithm := ""
+	var checksum []byte
+
+	if downloadResult != nil {
+		if !downloadResult.EndTime.IsZero() {
+			downloadedAt = downloadResult.EndTime
+		}
+
+		if len(downloadResult.LocalCheckSum) > 0 {
+			checksumAlgorithm = string(downloadResult.LocalCheckSumAlgorithm)
+			checksum = downloadResult.LocalCheckSum
+		} else if len(downloadResult.IRODSCheckSum) > 0 {
+			checksumAlgorithm = string(downloadResult.IRODSCheckSumAlgorithm)
+			checksum = downloadResult.IRODSCheckSum
+		}
+	}
+
+	if len(checksum) == 0 {
+		// checksum is not available in iRODS, compute in local
+		checksumAlgorithm = config.MDRepoHashScheme
+
+		localChecksum, err := get.hashCache.HashLocalFile(targetPath, checksumAlgorithm, nil)
+		if err != nil {
+			return errors.Wrapf(err, "failed to get hash of %q", targetPath)
+		}
+
+		checksum = localChecksum
+	}
+
+	err := manifest.AddFile(targetPath, sourceEntry.Path, sourceEntry.Size, checksumAlgorithm, hex.EncodeToString(checksum), downloadedAt)
+	if err != nil {
+		return errors.Wrapf(err, "failed to add %q to manifest", targetPath)
+	}
+
+	return nil
+}
+
+func (get *GetCommand) saveManifest(mdRepoTicket *mdrepo.MDRepoTicket) error {
+	manifest, ok := get.manifests[mdRepoTicket.IRODSDataPath]
+	if !ok {
+		return nil
+	}
+
+	err := manifest.Save()
+	if err != nil {
+		return errors.Wrapf(err, "failed to save manifest for %q", mdRepoTicket.IRODSDataPath)
+	}
+
+	return nil
+}
+
+func (get *GetCommand) planGet(mdRepoTicket *mdrepo.MDRepoTicket, action transfer.TransferPlanAction, sourceEntry *irodsclient_fs.Entry, targetPath string, targetSize int64, notes ...string) {
+	simulation, err := mdrepo.GetMDRepoSimulationRelPath(mdRepoTicket.IRODSDataPath)
+	if err != nil {
+		simulation = mdRepoTicket.IRODSDataPath
+	}
+
+	get.transferPlan.AddEntry(&transfer.TransferPlanEntry{
+		Simulation: simulation,
+		Method:     transfer.TransferMethodGet,
+		Action:     action,
+		SourcePath: sourceEntry.Path,
+		SourceSize: sourceEntry.Size,
+		DestPath:   targetPath,
+		DestSize:   targetSize,
+		Notes:      notes,
+	})
+}
+
 func (get *GetCommand) getDir(mdRepoTicket *mdrepo.MDRepoTicket, sourceEntry *irodsclient_fs.Entry, targetPath string) error {
 	logger := log.WithFields(log.Fields{
 		"irods_data_path": mdRepoTicket.IRODSDataPath,
@@ -631,10 +1112,12 @@ func (get *GetCommand) getDir(mdRepoTicket *mdrepo.MDRepoTicket, sourceEntry *ir
 		if os.IsNotExist(err) {
 			// target does not exist
 			// target must be a directorywith new name
-			err = os.MkdirAll(targetPath, 0766)
-			reportSimple(err)
-			if err != nil {
-				return errors.Wrapf(err, "failed to make a directory %q", targetPath)
+			if !get.dryRunFlagValues.DryRun {
+				err = os.MkdirAll(targetPath, 0766)
+				reportSimple(err)
+				if err != nil {
+					return errors.Wrapf(err, "failed to make a directory %q", targetPath)
+				}
 			}
 
 			// fallthrough to get entries
@@ -661,6 +1144,10 @@ func (get *GetCommand) getDir(mdRepoTicket *mdrepo.MDRepoTicket, sourceEntry *ir
 	for _, entry := range entries {
 		newEntryPath := commons_path.MakeLocalTargetFilePath(entry.Path, targetPath)
 
+		if get.filterEntry(mdRepoTicket, entry, newEntryPath) {
+			continue
+		}
+
 		if entry.IsDir() {
 			// dir
 			err = get.getDir(mdRepoTicket, entry, newEntryPath)
@@ -679,6 +1166,57 @@ func (get *GetCommand) getDir(mdRepoTicket *mdrepo.MDRepoTicket, sourceEntry *ir
 	return nil
 }
 
+// filterEntry returns true if the entry is not selected by the download filter
+func (get *GetCommand) filterEntry(mdRepoTicket *mdrepo.MDRepoTicket, entry *irodsclient_fs.Entry, targetPath string) bool {
+	selection, hasSelection := get.downloadSelections[mdRepoTicket.IRODSDataPath]
+	if !hasSelection && (get.downloadFilter == nil || get.downloadFilter.IsEmpty()) {
+		return false
+	}
+
+	releasePath := commons_path.MakeIRODSReleasePath(mdRepoTicket.IRODSDataPath)
+	relPath := commons_path.GetIRODSRelativePath(releasePath, entry.Path)
+
+	reason := ""
+	if entry.IsDir() {
+		if hasSelection && !selection.HasDir(relPath) {
+			reason = "no files of requested roles"
+		} else if get.downloadFilter != nil {
+			reason = get.downloadFilter.FilterDir(relPath)
+		}
+	} else {
+		if hasSelection && !selection.HasFile(relPath) {
+			reason = "not a requested role"
+		} else if get.downloadFilter != nil {
+			reason = get.downloadFilter.FilterFile(relPath, entry.Size)
+		}
+	}
+
+	if len(reason) == 0 {
+		return false
+	}
+
+	log.Debugf("filtered %q, %s", entry.Path, reason)
+
+	if get.dryRunFlagValues.DryRun {
+		get.planGet(mdRepoTicket, transfer.TransferPlanActionFiltered, entry, targetPath, 0, reason)
+		return true
+	}
+
+	now := time.Now()
+	reportFile := &transfer.TransferReportFile{
+		Method:     transfer.TransferMethodGet,
+		StartAt:    now,
+		EndAt:      now,
+		SourcePath: entry.Path,
+		SourceSize: entry.Size,
+		DestPath:   targetPath,
+		Notes:      []string{"get", "filtered", reason},
+	}
+
+	get.transferReportManager.AddFile(reportFile)
+	return true
+}
+
 func (get *GetCommand) determineTransferMethod(size int64) (transfer.TransferMode, int) {
 	logger := log.WithFields(log.Fields{})
 
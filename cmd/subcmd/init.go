@@ -0,0 +1,135 @@
+package subcmd
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/MD-Repo/md-repo-cli/cmd/flag"
+	"github.com/MD-Repo/md-repo-cli/commons/mdrepo"
+	"github.com/MD-Repo/md-repo-cli/commons/terminal"
+	"github.com/MD-Repo/md-repo-cli/commons/types"
+	"github.com/cockroachdb/errors"
+	irodsclient_types "github.com/cyverse/go-irodsclient/irods/types"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var initCmd = &cobra.Command{
+	Use:     "init [data dir]",
+	Short:   "Create a submit metadata file from directory contents",
+	Long:    "This command creates a submit metadata file (mdrepo-metadata.toml) by classifying files in the given directory. Fields that must be supplied by a person are left as placeholders.",
+	Aliases: []string{"scaffold"},
+	RunE:    processInitCommand,
+	Args:    cobra.MaximumNArgs(1),
+}
+
+func AddInitCommand(rootCmd *cobra.Command) {
+	// attach common flags
+	flag.SetCommonFlags(initCmd)
+
+	flag.SetForceFlags(initCmd, false)
+
+	rootCmd.AddCommand(initCmd)
+}
+
+func processInitCommand(command *cobra.Command, args []string) error {
+	initialize, err := NewInitCommand(command, args)
+	if err != nil {
+		return err
+	}
+
+	return initialize.Process()
+}
+
+type InitCommand struct {
+	command *cobra.Command
+
+	commonFlagValues *flag.CommonFlagValues
+	forceFlagValues  *flag.ForceFlagValues
+
+	sourcePath string
+}
+
+func NewInitCommand(command *cobra.Command, args []string) (*InitCommand, error) {
+	initialize := &InitCommand{
+		command: command,
+
+		commonFlagValues: flag.GetCommonFlagValues(command),
+		forceFlagValues:  flag.GetForceFlagValues(),
+	}
+
+	// path
+	initialize.sourcePath = "./"
+	if len(args) > 0 {
+		initialize.sourcePath = args[0]
+	}
+
+	return initialize, nil
+}
+
+func (initialize *InitCommand) Process() error {
+	logger := log.WithFields(log.Fields{})
+
+	cont, err := flag.ProcessCommonFlags(initialize.command)
+	if err != nil {
+		return errors.Wrapf(err, "failed to process common flags")
+	}
+
+	if !cont {
+		return nil
+	}
+
+	sourcePath, err := filepath.Abs(initialize.sourcePath)
+	if err != nil {
+		return errors.Wrapf(err, "failed to get absolute path of %q", initialize.sourcePath)
+	}
+
+	sourceStat, err := os.Stat(sourcePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return irodsclient_types.NewFileNotFoundError(sourcePath)
+		}
+
+		return errors.Wrapf(err, "failed to stat %q", sourcePath)
+	}
+
+	if !sourceStat.IsDir() {
+		return types.NewNotDirError(sourcePath)
+	}
+
+	metadataPath := mdrepo.GetSubmitMetadataPath(sourcePath)
+	if _, err := os.Stat(metadataPath); err == nil {
+		if !initialize.forceFlagValues.Force {
+			return irodsclient_types.NewFileAlreadyExistError(metadataPath)
+		}
+
+		logger.Debugf("overwriting existing submit metadata file %q", metadataPath)
+	}
+
+	metadata, err := mdrepo.NewSubmitMetadataFromDir(sourcePath)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create submit metadata for %q", sourcePath)
+	}
+
+	metadataString, err := mdrepo.MakeSubmitMetadataTemplate(metadata)
+	if err != nil {
+		return errors.Wrapf(err, "failed to make submit metadata for %q", sourcePath)
+	}
+
+	err = os.WriteFile(metadataPath, []byte(metadataString), 0644)
+	if err != nil {
+		return errors.Wrapf(err, "failed to write submit metadata file %q", metadataPath)
+	}
+
+	terminal.Printf("Created %q with %d trajectory files and %d additional files\n", metadataPath, len(metadata.TrajectoryFileNames), len(metadata.AdditionalFiles))
+	if len(metadata.StructureFileName) == 0 {
+		terminal.Printf("No structure file found, please fill in 'structure_file_name'\n")
+	}
+
+	if len(metadata.TopologyFileName) == 0 {
+		terminal.Printf("No topology file found, please fill in 'topology_file_name'\n")
+	}
+
+	terminal.Printf("Please fill in the fields marked as required before submitting\n")
+	return nil
+}
@@ -0,0 +1,38 @@
+package subcmd
+
+import (
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSubcommand(t *testing.T) {
+	t.Run("test UniqueNamesAndAliases", testUniqueNamesAndAliases)
+}
+
+func testUniqueNamesAndAliases(t *testing.T) {
+	rootCmd := &cobra.Command{
+		Use: "mdrepo",
+	}
+
+	AddGetCommand(rootCmd)
+	AddSubmitCommand(rootCmd)
+	AddSubmitListCommand(rootCmd)
+	AddVerifyCommand(rootCmd)
+	AddCatCommand(rootCmd)
+	AddValidateCommand(rootCmd)
+	AddInitCommand(rootCmd)
+	AddCacheCommand(rootCmd)
+	AddUpgradeCommand(rootCmd)
+
+	owners := map[string]string{}
+	for _, command := range rootCmd.Commands() {
+		names := append([]string{command.Name()}, command.Aliases...)
+		for _, name := range names {
+			owner, ok := owners[name]
+			assert.Falsef(t, ok, "%q of subcommand %q is already used by subcommand %q", name, command.Name(), owner)
+			owners[name] = command.Name()
+		}
+	}
+}
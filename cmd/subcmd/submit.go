@@ -1,18 +1,21 @@
 package subcmd
 
 import (
+	"bytes"
 	"encoding/hex"
 	"fmt"
 	"io/fs"
 	"os"
 	"path"
 	"path/filepath"
-	"strings"
+	"sync"
 	"time"
 
 	"github.com/MD-Repo/md-repo-cli/cmd/flag"
 	"github.com/MD-Repo/md-repo-cli/commons/checksum"
 	"github.com/MD-Repo/md-repo-cli/commons/config"
+	"github.com/MD-Repo/md-repo-cli/commons/format"
+	"github.com/MD-Repo/md-repo-cli/commons/hashcache"
 	"github.com/MD-Repo/md-repo-cli/commons/irods"
 	"github.com/MD-Repo/md-repo-cli/commons/mdrepo"
 	"github.com/MD-Repo/md-repo-cli/commons/parallel"
@@ -25,11 +28,9 @@ import (
 	"github.com/cockroachdb/errors"
 	irodsclient_fs "github.com/cyverse/go-irodsclient/fs"
 	irodsclient_types "github.com/cyverse/go-irodsclient/irods/types"
-	irodsclient_util "github.com/cyverse/go-irodsclient/irods/util"
 	"github.com/jedib0t/go-pretty/v6/progress"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
-	"golang.org/x/exp/slices"
 )
 
 var submitCmd = &cobra.Command{
@@ -46,12 +47,18 @@ func AddSubmitCommand(rootCmd *cobra.Command) {
 	flag.SetCommonFlags(submitCmd)
 
 	flag.SetSubmissionFlags(submitCmd)
+	flag.SetMetadataFlags(submitCmd)
+	flag.SetDiscoveryFlags(submitCmd)
 	flag.SetTokenFlags(submitCmd)
 	flag.SetParallelTransferFlags(submitCmd, false, false)
 	flag.SetForceFlags(submitCmd, true)
 	flag.SetProgressFlags(submitCmd)
 	flag.SetRetryFlags(submitCmd)
 	flag.SetTransferReportFlags(submitCmd)
+	flag.SetHashCacheFlags(submitCmd)
+	flag.SetDryRunFlags(submitCmd)
+	flag.SetHeartbeatFlags(submitCmd)
+	flag.SetOutputFormatFlags(submitCmd, true)
 
 	rootCmd.AddCommand(submitCmd)
 }
@@ -70,14 +77,21 @@ type SubmitCommand struct {
 
 	commonFlagValues           *flag.CommonFlagValues
 	submissionFlagValues       *flag.SubmissionFlagValues
+	metadataFlagValues         *flag.MetadataFlagValues
+	discoveryFlagValues        *flag.DiscoveryFlagValues
 	tokenFlagValues            *flag.TokenFlagValues
 	parallelTransferFlagValues *flag.ParallelTransferFlagValues
 	forceFlagValues            *flag.ForceFlagValues
 	progressFlagValues         *flag.ProgressFlagValues
 	retryFlagValues            *flag.RetryFlagValues
 	transferReportFlagValues   *flag.TransferReportFlagValues
+	hashCacheFlagValues        *flag.HashCacheFlagValues
+	dryRunFlagValues           *flag.DryRunFlagValues
+	outputFormatFlagValues     *flag.OutputFormatFlagValues
+	heartbeatFlagValues        *flag.HeartbeatFlagValues
 
-	maxConnectionNum int
+	maxConnectionNum  int
+	heartbeatInterval time.Duration
 
 	account      *irodsclient_types.IRODSAccount
 	filesystem   *irodsclient_fs.FileSystem
@@ -89,6 +103,12 @@ type SubmitCommand struct {
 	transferReportManager      *transfer.TransferReportManager
 	config                     *config.Config
 	submitStatusFileWriter     *mdrepo.SubmitStatusFileWriter
+	hashCache                  *hashcache.HashCache
+	journal                    *mdrepo.SubmitJournal
+	mapping                    *mdrepo.SubmitMapping
+	mappingSaveOnce            sync.Once
+	transferProgress           *transfer.TransferProgress
+	transferPlan               *transfer.TransferPlan
 
 	totalUploadedFiles int
 	totalUploadedBytes int64
@@ -103,12 +123,18 @@ func NewSubmitCommand(command *cobra.Command, args []string) (*SubmitCommand, er
 
 		commonFlagValues:           flag.GetCommonFlagValues(command),
 		submissionFlagValues:       flag.GetSubmissionFlagValues(),
+		metadataFlagValues:         flag.GetMetadataFlagValues(),
+		discoveryFlagValues:        flag.GetDiscoveryFlagValues(),
 		tokenFlagValues:            flag.GetTokenFlagValues(),
 		parallelTransferFlagValues: flag.GetParallelTransferFlagValues(),
 		forceFlagValues:            flag.GetForceFlagValues(),
 		progressFlagValues:         flag.GetProgressFlagValues(),
 		retryFlagValues:            flag.GetRetryFlagValues(),
 		transferReportFlagValues:   flag.GetTransferReportFlagValues(command),
+		hashCacheFlagValues:        flag.GetHashCacheFlagValues(),
+		dryRunFlagValues:           flag.GetDryRunFlagValues(),
+		outputFormatFlagValues:     flag.GetOutputFormatFlagValues(),
+		heartbeatFlagValues:        flag.GetHeartbeatFlagValues(),
 
 		config:             config.GetConfig(),
 		totalUploadedFiles: 0,
@@ -138,6 +164,28 @@ func (submit *SubmitCommand) Process() error {
 		return nil
 	}
 
+	submit.heartbeatInterval, err = submit.heartbeatFlagValues.GetInterval()
+	if err != nil {
+		return err
+	}
+
+	// hash cache
+	if !submit.hashCacheFlagValues.NoHashCache {
+		submit.hashCache, err = hashcache.NewDefaultHashCache()
+		if err != nil {
+			// run without hash cache
+			logger.WithError(err).Warn("failed to open hash cache")
+			submit.hashCache = nil
+		} else {
+			defer func() {
+				saveErr := submit.hashCache.Save()
+				if saveErr != nil {
+					logger.WithError(saveErr).Warn("failed to save hash cache")
+				}
+			}()
+		}
+	}
+
 	// handle token
 	if len(submit.tokenFlagValues.TicketString) > 0 {
 		submit.config.TicketString = submit.tokenFlagValues.TicketString
@@ -160,6 +208,8 @@ func (submit *SubmitCommand) Process() error {
 		return errors.Wrapf(err, "Failed to scan source paths")
 	}
 
+	submit.printDiscoveredSourcePaths(validSourcePaths, invalidSourcePaths, invalidSourcePathsErrors)
+
 	// check if the number of simulations matches the expected number
 	expectedSimulationNo := 0
 	if submit.submissionFlagValues.ExpectedSimulations > 0 {
@@ -176,7 +226,7 @@ func (submit *SubmitCommand) Process() error {
 			logger.Debugf("[%d] %s", sourceIdx+1, sourcePath)
 		}
 
-		logger.Debugf("the directories ignored due to lack of metadata file:")
+		logger.Debugf("the directories ignored:")
 		for sourceIdx, sourcePath := range invalidSourcePaths {
 			if len(invalidSourcePathsErrors) > sourceIdx {
 				logger.Debugf("[%d] %s: %s", sourceIdx+1, sourcePath, invalidSourcePathsErrors[sourceIdx])
@@ -227,6 +277,26 @@ func (submit *SubmitCommand) Process() error {
 		if err != nil {
 			return errors.Wrapf(err, "Failed to validate local files listed in the metadata file %q", metadata.MetadataFilePath)
 		}
+
+		if submit.metadataFlagValues.Strict {
+			err = metadata.ValidateKeys()
+			if err != nil {
+				return errors.Wrapf(err, "Failed to validate keys in the metadata file %q", metadata.MetadataFilePath)
+			}
+
+			err = metadata.ValidateFormats()
+			if err != nil {
+				return errors.Wrapf(err, "Failed to validate formats in the metadata file %q", metadata.MetadataFilePath)
+			}
+		} else {
+			for _, unknownKey := range metadata.GetUnknownKeys() {
+				terminal.Printf("WARNING: %s in metadata file %q\n", unknownKey.String(), metadata.MetadataFilePath)
+			}
+
+			for _, formatWarning := range metadata.GetFormatWarnings() {
+				terminal.Printf("WARNING: %s in metadata file %q\n", formatWarning, metadata.MetadataFilePath)
+			}
+		}
 	}
 
 	// verify metadata via server
@@ -244,17 +314,31 @@ func (submit *SubmitCommand) Process() error {
 		return errors.Wrapf(err, "Failed to retrieve tickets")
 	}
 
-	if len(mdRepoTickets) != len(validSourcePaths) {
-		logger.Debugf("we found %d simulations, but we got %d tokens", len(mdRepoTickets), len(validSourcePaths))
+	// tie simulation dirs to tickets
+	mdRepoTickets, mapping, err := submit.mapSourcePathsToTickets(validSourcePaths, mdRepoTickets)
+	if err != nil {
+		return err
+	}
+
+	// local journal to resume
+	err = submit.openJournal(mapping)
+	if err != nil {
+		return err
 	}
 
 	// transfer report
-	submit.transferReportManager, err = transfer.NewTransferReportManager(submit.transferReportFlagValues.Report, submit.transferReportFlagValues.ReportPath, submit.transferReportFlagValues.ReportToStdout)
+	// dry-run does not write reports
+	report := submit.transferReportFlagValues.Report && !submit.dryRunFlagValues.DryRun
+	submit.transferReportManager, err = transfer.NewTransferReportManager(report, submit.transferReportFlagValues.ReportPath, submit.transferReportFlagValues.ReportToStdout)
 	if err != nil {
 		return errors.Wrapf(err, "Failed to create transfer report manager")
 	}
 	defer submit.transferReportManager.Release()
 
+	if submit.dryRunFlagValues.DryRun {
+		submit.transferPlan = transfer.NewTransferPlan()
+	}
+
 	// run
 	for ticketIdx, mdRepoTicket := range mdRepoTickets {
 		sourcePath := validSourcePaths[ticketIdx]
@@ -264,6 +348,16 @@ func (submit *SubmitCommand) Process() error {
 		}
 	}
 
+	// all files may have been skipped
+	submit.saveMapping()
+
+	if submit.dryRunFlagValues.DryRun {
+		outputFormatter := format.NewOutputFormatter(terminal.GetTerminalWriter())
+		submit.transferPlan.MakeOutputTables(outputFormatter, "Submission Plan")
+		outputFormatter.Render(submit.outputFormatFlagValues.Format)
+		return nil
+	}
+
 	// print final summary
 	if !submit.progressFlagValues.NoProgress {
 		timeTaken := time.Since(submit.startTime).Seconds()
@@ -311,8 +405,20 @@ func (submit *SubmitCommand) processTicket(sourcePath string, mdRepoTicket *mdre
 	// run
 	targetPath := commons_path.MakeIRODSLandingPath(mdRepoTicket.IRODSDataPath)
 
+	if submit.dryRunFlagValues.DryRun {
+		// plan only, no status files and no transfer
+		err = submit.submitOne(mdRepoTicket, sourcePath)
+		if err != nil {
+			return errors.Wrapf(err, "Failed to plan submission of %q to %q", sourcePath, targetPath)
+		}
+
+		return nil
+	}
+
 	// setup submit status file writer
 	submit.submitStatusFileWriter = mdrepo.NewSubmitStatusFileWriter(submit.filesystem, submit.config.Token, targetPath)
+	submit.submitStatusFileWriter.SetTransferSettings(string(submit.determineTransferMode()), submit.parallelTransferFlagValues.ThreadNumber, submit.parallelTransferFlagValues.ThreadNumberPerFile)
+	submit.transferProgress = transfer.NewTransferProgress()
 
 	// run
 	terminal.Printf("scheduling transfer...\n")
@@ -334,10 +440,17 @@ func (submit *SubmitCommand) processTicket(sourcePath string, mdRepoTicket *mdre
 	}
 
 	defer func() {
+		completedFiles, transferredSize, _ := submit.transferProgress.Sample()
+		submit.submitStatusFileWriter.SetProgress(completedFiles, transferredSize, 0)
 		submit.submitStatusFileWriter.CreateStatusFile()
 		submit.submitStatusFileWriter = nil
 	}()
 
+	if submit.heartbeatInterval > 0 {
+		stopHeartbeat := submit.startHeartbeat(submit.heartbeatInterval)
+		defer stopHeartbeat()
+	}
+
 	transferErr := submit.parallelTransferJobManager.Start()
 	if transferErr != nil {
 		submit.submitStatusFileWriter.SetErrored()
@@ -352,119 +465,92 @@ func (submit *SubmitCommand) processTicket(sourcePath string, mdRepoTicket *mdre
 	return nil
 }
 
-// scanSourcePaths scans source paths and return valid sources only
-func (submit *SubmitCommand) scanSourcePaths(orcID string) ([]string, []string, []error, string, error) {
-	validSourcePaths := []string{}
-	invalidSourcePaths := []string{}
-	invalidSourcePathsErrors := []error{}
-
-	for _, sourcePath := range submit.sourcePaths {
-		sourcePath = commons_path.MakeLocalPath(sourcePath)
-
-		st, stErr := os.Stat(sourcePath)
-		if stErr != nil {
-			if os.IsNotExist(stErr) {
-				return nil, nil, nil, "", errors.Join(stErr, irodsclient_types.NewFileNotFoundError(sourcePath))
-			}
+// startHeartbeat rewrites the in-progress status file with progress at the interval, returns a function to stop
+func (submit *SubmitCommand) startHeartbeat(interval time.Duration) func() {
+	logger := log.WithFields(log.Fields{
+		"interval": interval,
+	})
 
-			return nil, nil, nil, "", stErr
-		}
+	statusFileWriter := submit.submitStatusFileWriter
+	transferProgress := submit.transferProgress
 
-		if !st.IsDir() {
-			return nil, nil, nil, "", types.NewNotDirError(sourcePath)
-		}
+	stopCh := make(chan struct{})
+	waitGroup := sync.WaitGroup{}
+	waitGroup.Add(1)
 
-		err := mdrepo.ValidateSubmissionSourcePath(sourcePath)
-		if err == nil {
-			// valid
-			validSourcePaths = append(validSourcePaths, sourcePath)
-			continue
-		}
+	go func() {
+		defer waitGroup.Done()
 
-		// may have sub dirs?
-		dirEntries, readErr := os.ReadDir(sourcePath)
-		if readErr != nil {
-			return nil, nil, nil, "", errors.Wrapf(readErr, "Failed to list source %q", sourcePath)
-		}
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
 
-		hasSubDirs := false
-		for _, dirEntry := range dirEntries {
-			if dirEntry.IsDir() {
-				hasSubDirs = true
+		for {
+			select {
+			case <-stopCh:
+				return
+			case <-ticker.C:
+				completedFiles, transferredSize, transferRate := transferProgress.Sample()
+				statusFileWriter.SetProgress(completedFiles, transferredSize, transferRate)
 
-				entryPath := filepath.Join(sourcePath, dirEntry.Name())
-				chkErr := mdrepo.ValidateSubmissionSourcePath(entryPath)
-				if chkErr == nil {
-					// valid
-					validSourcePaths = append(validSourcePaths, entryPath)
-				} else {
-					// invalid
-					invalidSourcePaths = append(invalidSourcePaths, entryPath)
-					invalidSourcePathsErrors = append(invalidSourcePathsErrors, chkErr)
+				err := statusFileWriter.CreateStatusFile()
+				if err != nil {
+					logger.WithError(err).Warn("failed to update status file")
+					continue
 				}
+
+				logger.Debugf("updated status file, %d files, %d bytes transferred", completedFiles, transferredSize)
 			}
 		}
+	}()
 
-		if !hasSubDirs {
-			// invalid
-			invalidSourcePaths = append(invalidSourcePaths, sourcePath)
-			invalidSourcePathsErrors = append(invalidSourcePathsErrors, err)
-		}
+	return func() {
+		close(stopCh)
+		waitGroup.Wait()
 	}
+}
 
-	// sort source paths by name to match to tickets always in the same order
-	slices.Sort(validSourcePaths)
+// scanSourcePaths scans source paths and return valid sources only
+func (submit *SubmitCommand) scanSourcePaths(orcID string) ([]string, []string, []error, string, error) {
+	validSourcePaths, invalidSourcePaths, invalidSourcePathsErrors, err := mdrepo.FindSubmissionSourcePaths(submit.sourcePaths, submit.getDiscoveryOptions())
+	if err != nil {
+		return nil, nil, nil, "", err
+	}
 
-	// check files: no zero-length files and no duplicate MD5 hashes
-	hashToFiles := map[string][]string{} // md5 hex -> all file paths sharing it
+	metadataList := []*mdrepo.MDRepoSubmitMetadata{}
 	for _, validSourcePath := range validSourcePaths {
-		entries, err := os.ReadDir(validSourcePath)
+		metadata, err := mdrepo.ParseSubmitMetadataDir(validSourcePath)
 		if err != nil {
-			return nil, nil, nil, "", errors.Wrapf(err, "Failed to read dir %q", validSourcePath)
+			return nil, nil, nil, "", errors.Wrapf(err, "Failed to parse metadata for %q", validSourcePath)
 		}
 
-		for _, entry := range entries {
-			if entry.IsDir() {
-				continue
-			}
-
-			filePath := filepath.Join(validSourcePath, entry.Name())
-			absFilePath, err := filepath.Abs(filePath)
-			if err != nil {
-				return nil, nil, nil, "", errors.Wrapf(err, "Failed to get absolute path for %q", filePath)
-			}
-
-			info, err := entry.Info()
-			if err != nil {
-				return nil, nil, nil, "", errors.Wrapf(err, "Failed to stat %q", filePath)
-			}
-
-			if info.Size() == 0 {
-				return nil, nil, nil, "", errors.Errorf("file %q is empty", filePath)
-			}
+		metadataList = append(metadataList, metadata)
+	}
 
-			hash, err := irodsclient_util.HashLocalFile(absFilePath, "md5", nil)
-			if err != nil {
-				return nil, nil, nil, "", errors.Wrapf(err, "Failed to compute MD5 for %q", filePath)
-			}
+	// check files to be submitted: no zero-length files and no duplicate MD5 hashes
+	fileHashes, err := mdrepo.HashSubmissionFilesParallel(metadataList, submit.hashCache, submit.parallelTransferFlagValues.ThreadNumber, !submit.progressFlagValues.NoProgress, submit.progressFlagValues.ShowFullPath)
+	if err != nil {
+		return nil, nil, nil, "", err
+	}
 
-			hashStr := hex.EncodeToString(hash)
-			hashToFiles[hashStr] = append(hashToFiles[hashStr], filePath)
+	// store for later use
+	for absFilePath, hashStr := range fileHashes {
+		submit.fileHashes[absFilePath] = hashStr
+	}
 
-			// store for later use
-			submit.fileHashes[absFilePath] = hashStr
+	for _, metadata := range metadataList {
+		orphanFiles, err := mdrepo.FindOrphanSubmissionFiles(metadata)
+		if err != nil {
+			return nil, nil, nil, "", err
 		}
-	}
 
-	duplicateMessages := []string{}
-	for hashStr, files := range hashToFiles {
-		if len(files) > 1 {
-			duplicateMessages = append(duplicateMessages, fmt.Sprintf("hash %s: %s", hashStr, strings.Join(files, ", ")))
+		for _, orphanFile := range orphanFiles {
+			terminal.Printf("WARNING: file %q in %q is not listed in metadata and will not be submitted\n", orphanFile, metadata.SubmissionPath)
 		}
 	}
-	if len(duplicateMessages) > 0 {
-		slices.Sort(duplicateMessages)
-		return nil, nil, nil, "", errors.Errorf("duplicate MD5 hashes found:\n%s", strings.Join(duplicateMessages, "\n"))
+
+	err = mdrepo.CheckDuplicateSubmissionFiles(submit.fileHashes)
+	if err != nil {
+		return nil, nil, nil, "", err
 	}
 
 	// if orcID is given, override the orcID
@@ -529,22 +615,24 @@ func (submit *SubmitCommand) submitOne(mdRepoTicket *mdrepo.MDRepoTicket, source
 		return errors.Wrapf(err, "Failed to parse submit metadata in dir %q", sourcePath)
 	}
 
-	sourceFiles := metadata.GetFiles()
-
-	hasMetadata := false
-	for _, sourceFile := range sourceFiles {
-		if filepath.Base(sourceFile) == mdrepo.SubmissionMetadataFilename {
-			hasMetadata = true
+	if !submit.dryRunFlagValues.DryRun {
+		metadataHash, err := submit.getFileHash(metadata.MetadataFilePath)
+		if err != nil {
+			return err
 		}
+
+		submit.submitStatusFileWriter.SetMetadataHash(metadataHash)
 	}
 
-	if !hasMetadata {
-		// include submission metadata file itself
-		sourceFiles = append(sourceFiles, mdrepo.SubmissionMetadataFilename)
+	sourceFiles := mdrepo.GetSubmissionFiles(metadata)
+
+	// sub-directories created on iRODS
+	targetDirPaths := map[string]bool{
+		targetPath: true,
 	}
 
 	for _, sourceFile := range sourceFiles {
-		sourceFileAbsPath := filepath.Join(metadata.SubmissionPath, sourceFile)
+		sourceFileAbsPath := metadata.GetSubmitFileLocalPath(sourceFile)
 		sourceFileAbsPath, err = filepath.Abs(sourceFileAbsPath)
 		if err != nil {
 			return errors.Wrapf(err, "Failed to get absolute path for %q", sourceFileAbsPath)
@@ -555,37 +643,106 @@ func (submit *SubmitCommand) submitOne(mdRepoTicket *mdrepo.MDRepoTicket, source
 			return errors.Wrapf(err, "Failed to stat source file %q", sourceFileAbsPath)
 		}
 
+		hashStr, err := submit.getFileHash(sourceFileAbsPath)
+		if err != nil {
+			return err
+		}
+
+		// keep sub-directory structure under the landing path
 		targetFilePath := path.Join(targetPath, sourceFile)
 
-		submitErr := submit.submitFile(mdRepoTicket, sourceFileStat, sourceFileAbsPath, targetPath, targetFilePath)
-		if submitErr != nil {
-			return submitErr
+		submitStatusEntry := mdrepo.SubmitStatusEntry{
+			IRODSPath: commons_path.GetIRODSRelativePath(targetPath, targetFilePath),
+			Size:      sourceFileStat.Size(),
+			MD5Hash:   hashStr,
 		}
 
-		// add status entry
-		hashStr := ""
-		if hash, ok := submit.fileHashes[sourceFileAbsPath]; ok {
-			hashStr = hash
-		} else {
-			hash, err := irodsclient_util.HashLocalFile(sourceFileAbsPath, "md5", nil)
+		// skip files uploaded in the previous run without checking iRODS
+		if !submit.forceFlagValues.Force && submit.journal != nil && submit.journal.IsDone(sourceFileAbsPath, targetFilePath, sourceFileStat, hashStr) {
+			if submit.dryRunFlagValues.DryRun {
+				submit.planSubmit(mdRepoTicket, transfer.TransferPlanActionSkip, sourceFileStat, sourceFileAbsPath, targetFilePath, sourceFileStat.Size(), "uploaded in previous run")
+				continue
+			}
+
+			now := time.Now()
+			reportFile := &transfer.TransferReportFile{
+				Method:                  transfer.TransferMethodPut,
+				StartAt:                 now,
+				EndAt:                   now,
+				SourcePath:              sourceFileAbsPath,
+				SourceSize:              sourceFileStat.Size(),
+				SourceChecksumAlgorithm: "MD5",
+				SourceChecksum:          hashStr,
+				DestPath:                targetFilePath,
+
+				Notes: []string{"put", "file", "journal", "skipped"},
+			}
+
+			submit.transferReportManager.AddFile(reportFile)
+
+			terminal.Printf("skip uploading a file %q to %q. The file was uploaded in the previous run!\n", sourceFileAbsPath, targetFilePath)
+			logger.Debugf("skip uploading a file %q. The file was uploaded in the previous run!", sourceFileAbsPath)
+
+			submit.submitStatusFileWriter.AddFile(submitStatusEntry)
+			submit.transferProgress.Skip()
+			continue
+		}
+
+		targetDirPath := path.Dir(targetFilePath)
+		if !targetDirPaths[targetDirPath] && !submit.dryRunFlagValues.DryRun {
+			logger.Debugf("making a sub-directory %q", targetDirPath)
+			err = submit.filesystem.MakeDir(targetDirPath, true)
 			if err != nil {
-				return errors.Wrapf(err, "Failed to get hash for %q", sourceFileAbsPath)
+				return errors.Wrapf(err, "Failed to make a directory %q", targetDirPath)
 			}
 
-			hashStr = hex.EncodeToString(hash)
+			targetDirPaths[targetDirPath] = true
 		}
 
-		submitStatusEntry := mdrepo.SubmitStatusEntry{
-			IRODSPath: commons_path.GetIRODSRelativePath(targetPath, targetFilePath),
-			Size:      sourceFileStat.Size(),
-			MD5Hash:   hashStr,
+		if !submit.dryRunFlagValues.DryRun && submit.journal != nil {
+			err = submit.journal.AddPending(sourceFileAbsPath, targetFilePath, sourceFileStat, hashStr)
+			if err != nil {
+				return errors.Wrapf(err, "Failed to add %q to the journal", sourceFileAbsPath)
+			}
+		}
+
+		submitErr := submit.submitFile(mdRepoTicket, sourceFileStat, sourceFileAbsPath, targetPath, targetFilePath)
+		if submitErr != nil {
+			return submitErr
+		}
+
+		if submit.dryRunFlagValues.DryRun {
+			continue
 		}
+
+		// add status entry
 		submit.submitStatusFileWriter.AddFile(submitStatusEntry)
 	}
 
+	submit.saveJournal()
+
 	return nil
 }
 
+// getFileHash returns MD5 hash of the local file, computed while scanning if available
+func (submit *SubmitCommand) getFileHash(sourcePath string) (string, error) {
+	absSourcePath, err := filepath.Abs(sourcePath)
+	if err != nil {
+		return "", errors.Wrapf(err, "Failed to get absolute path for %q", sourcePath)
+	}
+
+	if hash, ok := submit.fileHashes[absSourcePath]; ok {
+		return hash, nil
+	}
+
+	hash, err := submit.hashCache.HashLocalFile(absSourcePath, "md5", nil)
+	if err != nil {
+		return "", errors.Wrapf(err, "Failed to get hash for %q", absSourcePath)
+	}
+
+	return hex.EncodeToString(hash), nil
+}
+
 func (submit *SubmitCommand) submitFile(mdRepoTicket *mdrepo.MDRepoTicket, sourceStat fs.FileInfo, sourcePath string, targetRootPath string, targetPath string) error {
 	logger := log.WithFields(log.Fields{
 		"irods_data_path":  mdRepoTicket.IRODSDataPath,
@@ -637,6 +794,11 @@ func (submit *SubmitCommand) submitFile(mdRepoTicket *mdrepo.MDRepoTicket, sourc
 		if irodsclient_types.IsFileNotFoundError(err) {
 			// target does not exist
 			// target must be a file with new name
+			if submit.dryRunFlagValues.DryRun {
+				submit.planSubmit(mdRepoTicket, transfer.TransferPlanActionNew, sourceStat, sourcePath, targetPath, 0)
+				return nil
+			}
+
 			submit.scheduleSubmit(mdRepoTicket, sourceStat, sourcePath, targetRootPath, targetPath)
 			return nil
 		}
@@ -667,7 +829,7 @@ func (submit *SubmitCommand) submitFile(mdRepoTicket *mdrepo.MDRepoTicket, sourc
 				if hash, ok := submit.fileHashes[sourceFileAbsPath]; ok {
 					hashStr = hash
 				} else {
-					hash, err := irodsclient_util.HashLocalFile(sourcePath, string(targetEntry.CheckSumAlgorithm), nil)
+					hash, err := submit.hashCache.HashLocalFile(sourcePath, string(targetEntry.CheckSumAlgorithm), nil)
 					if err != nil {
 						return errors.Wrapf(err, "Failed to get hash for %q", sourcePath)
 					}
@@ -677,6 +839,11 @@ func (submit *SubmitCommand) submitFile(mdRepoTicket *mdrepo.MDRepoTicket, sourc
 
 				if hashStr == hex.EncodeToString(targetEntry.CheckSum) {
 					// skip
+					if submit.dryRunFlagValues.DryRun {
+						submit.planSubmit(mdRepoTicket, transfer.TransferPlanActionSkip, sourceStat, sourcePath, targetPath, targetEntry.Size, "same checksum")
+						return nil
+					}
+
 					now := time.Now()
 					reportFile := &transfer.TransferReportFile{
 						Method:                  transfer.TransferMethodPut,
@@ -695,6 +862,10 @@ func (submit *SubmitCommand) submitFile(mdRepoTicket *mdrepo.MDRepoTicket, sourc
 					}
 
 					submit.transferReportManager.AddFile(reportFile)
+					submit.setJournalState(sourcePath, mdrepo.SubmitJournalFileStateVerified)
+					if submit.transferProgress != nil {
+						submit.transferProgress.Skip()
+					}
 
 					terminal.Printf("skip uploading a file %q to %q. The data object with the same hash already exists!\n", sourcePath, targetPath)
 					logger.Debug("skip uploading a file. The data object with the same hash already exists!")
@@ -704,10 +875,44 @@ func (submit *SubmitCommand) submitFile(mdRepoTicket *mdrepo.MDRepoTicket, sourc
 		}
 	}
 
+	if submit.dryRunFlagValues.DryRun {
+		notes := []string{}
+		if submit.forceFlagValues.Force {
+			notes = append(notes, "force")
+		} else if targetEntry.Size != sourceStat.Size() {
+			notes = append(notes, "different size")
+		} else if len(targetEntry.CheckSum) > 0 {
+			notes = append(notes, "different checksum")
+		} else {
+			notes = append(notes, "no checksum")
+		}
+
+		submit.planSubmit(mdRepoTicket, transfer.TransferPlanActionOverwrite, sourceStat, sourcePath, targetPath, targetEntry.Size, notes...)
+		return nil
+	}
+
 	// schedule
 	return submit.scheduleSubmit(mdRepoTicket, sourceStat, sourcePath, targetRootPath, targetPath)
 }
 
+func (submit *SubmitCommand) planSubmit(mdRepoTicket *mdrepo.MDRepoTicket, action transfer.TransferPlanAction, sourceStat fs.FileInfo, sourcePath string, targetPath string, targetSize int64, notes ...string) {
+	simulation, err := mdrepo.GetMDRepoSimulationRelPath(mdRepoTicket.IRODSDataPath)
+	if err != nil {
+		simulation = mdRepoTicket.IRODSDataPath
+	}
+
+	submit.transferPlan.AddEntry(&transfer.TransferPlanEntry{
+		Simulation: simulation,
+		Method:     transfer.TransferMethodPut,
+		Action:     action,
+		SourcePath: sourcePath,
+		SourceSize: sourceStat.Size(),
+		DestPath:   targetPath,
+		DestSize:   targetSize,
+		Notes:      notes,
+	})
+}
+
 func (submit *SubmitCommand) scheduleSubmit(mdRepoTicket *mdrepo.MDRepoTicket, sourceStat fs.FileInfo, sourcePath string, targetRootPath string, targetPath string) error {
 	logger := log.WithFields(log.Fields{
 		"irods_data_path":  mdRepoTicket.IRODSDataPath,
@@ -745,6 +950,8 @@ func (submit *SubmitCommand) scheduleSubmit(mdRepoTicket *mdrepo.MDRepoTicket, s
 	}
 
 	transferMode, threadsRequired := submit.determineTransferMethod(sourceStat.Size())
+	statusFileWriter := submit.submitStatusFileWriter
+	statusFilePath := commons_path.GetIRODSRelativePath(targetRootPath, targetPath)
 
 	submitTask := func(job *parallel.ParallelJob) error {
 		if job.IsCanceled() {
@@ -762,6 +969,10 @@ func (submit *SubmitCommand) scheduleSubmit(mdRepoTicket *mdrepo.MDRepoTicket, s
 
 		progressCallbackPut := func(taskType string, processed int64, total int64) {
 			job.Progress(taskType, processed, total, false)
+
+			if taskType == "upload" {
+				submit.transferProgress.Update(sourcePath, processed)
+			}
 		}
 
 		job.Progress("upload", 0, sourceStat.Size(), false)
@@ -787,6 +998,7 @@ func (submit *SubmitCommand) scheduleSubmit(mdRepoTicket *mdrepo.MDRepoTicket, s
 		retryNum := submit.retryFlagValues.GetRetryNumber()
 		retryInterval := submit.retryFlagValues.GetRetryIntervalSeconds()
 
+		startTime := time.Now()
 		attempt := 0
 		retryErr := retry.Do(func() error {
 			attempt++
@@ -806,6 +1018,10 @@ func (submit *SubmitCommand) scheduleSubmit(mdRepoTicket *mdrepo.MDRepoTicket, s
 			return uploadErr
 		}, retry.Attempts(uint(retryNum+1)), retry.Delay(retryInterval), retry.LastErrorOnly(true))
 
+		if statusFileWriter != nil {
+			statusFileWriter.SetFileTransfer(statusFilePath, startTime, time.Now(), attempt, threadsRequired)
+		}
+
 		if retryErr != nil {
 			job.Progress("upload", -1, sourceStat.Size(), true)
 			job.Progress("checksum", -1, sourceStat.Size(), true)
@@ -816,9 +1032,16 @@ func (submit *SubmitCommand) scheduleSubmit(mdRepoTicket *mdrepo.MDRepoTicket, s
 
 		submit.totalUploadedFiles++
 		submit.totalUploadedBytes += sourceStat.Size()
+		submit.transferProgress.Complete(sourcePath, sourceStat.Size())
 
 		reportTransfer(uploadResult, nil, notes...)
 
+		if uploadResult != nil && len(uploadResult.IRODSCheckSum) > 0 && bytes.Equal(uploadResult.IRODSCheckSum, uploadResult.LocalCheckSum) {
+			submit.setJournalState(sourcePath, mdrepo.SubmitJournalFileStateVerified)
+		} else {
+			submit.setJournalState(sourcePath, mdrepo.SubmitJournalFileStateUploaded)
+		}
+
 		logger.Debug("uploaded a file")
 		return nil
 	}
@@ -839,7 +1062,206 @@ func (submit *SubmitCommand) determineTransferMethod(size int64) (transfer.Trans
 		threads = 1
 	}
 
-	// we don't support webdav transfer here
 	logger.Info("using ICAT transfer for uploading a data object")
-	return transfer.TransferModeICAT, threads
+	return submit.determineTransferMode(), threads
+}
+
+// determineTransferMode returns the transfer mode used for uploading data objects
+func (submit *SubmitCommand) determineTransferMode() transfer.TransferMode {
+	// we don't support webdav transfer here
+	return transfer.TransferModeICAT
+}
+
+// mapSourcePathsToTickets returns tickets for simulation dirs, in the same order as sourcePaths
+// the mapping is persisted under the submission root path once a file is uploaded, to be reused when resuming
+func (submit *SubmitCommand) mapSourcePathsToTickets(sourcePaths []string, mdRepoTickets []mdrepo.MDRepoTicket) ([]mdrepo.MDRepoTicket, *mdrepo.SubmitMapping, error) {
+	logger := log.WithFields(log.Fields{})
+
+	rootPath, err := mdrepo.GetSubmissionRootPath(submit.sourcePaths)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	mappingPath := mdrepo.GetSubmitMappingPath(rootPath)
+
+	logger.Debugf("mapping simulation directories under %q", rootPath)
+
+	// --force pairs dirs again, ignoring the mapping persisted
+	mapping, replacedMapping, err := mdrepo.GetSubmitMapping(rootPath, submit.submissionFlagValues.Mapping, sourcePaths, mdRepoTickets, submit.forceFlagValues.Force)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if replacedMapping != nil {
+		terminal.Printf("WARNING: replacing the simulation mapping saved in %q\n", mappingPath)
+		for _, difference := range replacedMapping.GetDifferences(mapping) {
+			terminal.Printf("  %s\n", difference)
+		}
+	}
+
+	mappedTickets, err := mapping.ResolveTickets(sourcePaths, mdRepoTickets)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	terminal.Printf("simulation directories are mapped to submissions\n")
+	for sourceIdx, sourcePath := range sourcePaths {
+		terminal.Printf("[%d] %s -> %s\n", sourceIdx+1, sourcePath, mappedTickets[sourceIdx].GetSubmissionID())
+	}
+
+	submit.mapping = mapping
+	return mappedTickets, mapping, nil
+}
+
+// saveMapping persists the mapping, called once a file is uploaded so that a wrong pairing is not kept after a failed run
+func (submit *SubmitCommand) saveMapping() {
+	logger := log.WithFields(log.Fields{})
+
+	if submit.dryRunFlagValues.DryRun || submit.mapping == nil {
+		return
+	}
+
+	submit.mappingSaveOnce.Do(func() {
+		err := submit.mapping.Save()
+		if err != nil {
+			logger.WithError(err).Warnf("failed to save simulation mapping to %q", mdrepo.GetSubmitMappingPath(submit.mapping.RootPath))
+		}
+	})
+}
+
+// openJournal opens the local journal of the submission, creating a new one if not exist or not matching
+func (submit *SubmitCommand) openJournal(mapping *mdrepo.SubmitMapping) error {
+	journalPath := mdrepo.GetSubmitJournalPath(mapping.RootPath)
+
+	token := submit.config.Token
+	if len(token) == 0 {
+		token = submit.config.TicketString
+	}
+
+	tokenFingerprint := mdrepo.GetTokenFingerprint(token)
+
+	journal, err := mdrepo.LoadSubmitJournal(mapping.RootPath)
+	if err != nil {
+		// broken journal must not block submission
+		terminal.Printf("WARNING: ignoring the journal: %s\n", err)
+		journal = nil
+	}
+
+	if journal != nil && !journal.IsCompatible(tokenFingerprint, mapping) {
+		terminal.Printf("WARNING: the journal %q was made with a different token or mapping, starting a new journal\n", journalPath)
+		journal = nil
+	}
+
+	if submit.submissionFlagValues.Resume {
+		if journal == nil {
+			return errors.Errorf("No journal to resume is found in %q", journalPath)
+		}
+
+		submit.printJournalRemainingFiles(journal)
+	}
+
+	if journal == nil {
+		journal = mdrepo.NewSubmitJournal(mapping.RootPath, tokenFingerprint, mapping)
+	}
+
+	submit.journal = journal
+	return nil
+}
+
+func (submit *SubmitCommand) saveJournal() {
+	logger := log.WithFields(log.Fields{})
+
+	if submit.dryRunFlagValues.DryRun || submit.journal == nil {
+		return
+	}
+
+	err := submit.journal.Save()
+	if err != nil {
+		logger.WithError(err).Warn("failed to save the journal")
+	}
+}
+
+func (submit *SubmitCommand) setJournalState(sourcePath string, state mdrepo.SubmitJournalFileState) {
+	logger := log.WithFields(log.Fields{
+		"source_path": sourcePath,
+	})
+
+	if submit.dryRunFlagValues.DryRun || submit.journal == nil {
+		return
+	}
+
+	err := submit.journal.SetState(sourcePath, state)
+	if err != nil {
+		logger.WithError(err).Warn("failed to update the journal")
+		return
+	}
+
+	submit.saveMapping()
+	submit.saveJournal()
+}
+
+func (submit *SubmitCommand) printJournalRemainingFiles(journal *mdrepo.SubmitJournal) {
+	files := journal.GetFiles()
+	remainingFiles := journal.GetRemainingFiles()
+
+	remainingSize := int64(0)
+	for _, file := range remainingFiles {
+		remainingSize += file.Size
+	}
+
+	terminal.Printf("resuming the submission started at %s, %d of %d files (%s) are left\n", types.MakeDateTimeString(journal.CreatedAt), len(remainingFiles), len(files), types.SizeString(remainingSize))
+
+	if len(remainingFiles) == 0 {
+		return
+	}
+
+	outputFormatter := format.NewOutputFormatter(terminal.GetTerminalWriter())
+	outputFormatterTable := outputFormatter.NewTable("Remaining Files")
+
+	outputFormatterTable.SetHeader([]string{
+		"Path",
+		"Size",
+		"State",
+		"Updated At",
+	})
+
+	for _, file := range remainingFiles {
+		outputFormatterTable.AppendRow([]interface{}{
+			file.Path,
+			types.SizeString(file.Size),
+			string(file.State),
+			types.MakeDateTimeString(file.UpdatedAt),
+		})
+	}
+
+	outputFormatter.Render(submit.outputFormatFlagValues.Format)
+}
+
+func (submit *SubmitCommand) printDiscoveredSourcePaths(validSourcePaths []string, invalidSourcePaths []string, invalidSourcePathsErrors []error) {
+	terminal.Printf("found %d simulation directories\n", len(validSourcePaths))
+	for sourceIdx, sourcePath := range validSourcePaths {
+		terminal.Printf("[%d] %s\n", sourceIdx+1, sourcePath)
+	}
+
+	if len(invalidSourcePaths) == 0 {
+		return
+	}
+
+	terminal.Printf("skipped %d directories\n", len(invalidSourcePaths))
+	for sourceIdx, sourcePath := range invalidSourcePaths {
+		if len(invalidSourcePathsErrors) > sourceIdx {
+			terminal.Printf("[%d] %s: %s\n", sourceIdx+1, sourcePath, invalidSourcePathsErrors[sourceIdx])
+		} else {
+			terminal.Printf("[%d] %s\n", sourceIdx+1, sourcePath)
+		}
+	}
+}
+
+func (submit *SubmitCommand) getDiscoveryOptions() *mdrepo.SubmissionDiscoveryOptions {
+	return &mdrepo.SubmissionDiscoveryOptions{
+		Recursive: submit.discoveryFlagValues.Recursive,
+		MaxDepth:  submit.discoveryFlagValues.MaxDepth,
+		Include:   submit.discoveryFlagValues.Include,
+		Exclude:   submit.discoveryFlagValues.Exclude,
+	}
 }
@@ -2,8 +2,8 @@ package subcmd
 
 import (
 	"bytes"
-	"encoding/json"
 	"fmt"
+	"os"
 	"sort"
 	"time"
 
@@ -12,6 +12,7 @@ import (
 	"github.com/MD-Repo/md-repo-cli/commons/checksum"
 	"github.com/MD-Repo/md-repo-cli/commons/config"
 	"github.com/MD-Repo/md-repo-cli/commons/format"
+	"github.com/MD-Repo/md-repo-cli/commons/hashcache"
 	"github.com/MD-Repo/md-repo-cli/commons/irods"
 	"github.com/MD-Repo/md-repo-cli/commons/mdrepo"
 	"github.com/MD-Repo/md-repo-cli/commons/path"
@@ -27,12 +28,12 @@ import (
 )
 
 var submitListCmd = &cobra.Command{
-	Use:     "submitls",
+	Use:     "submitls [<data dirs> ...]",
 	Short:   "List MD-Repo data",
-	Long:    `This command lists MD-Repo submission data associated with the given token.`,
+	Long:    `This command lists MD-Repo submission data associated with the given token. With --compare, it compares local data dirs with submission data.`,
 	Aliases: []string{"submit_ls", "list_submission", "list_submit"},
 	RunE:    processSubmitListCommand,
-	Args:    cobra.NoArgs,
+	Args:    cobra.ArbitraryArgs,
 }
 
 func AddSubmitListCommand(rootCmd *cobra.Command) {
@@ -41,6 +42,8 @@ func AddSubmitListCommand(rootCmd *cobra.Command) {
 	flag.SetOutputFormatFlags(submitListCmd, false)
 	flag.SetTokenFlags(submitListCmd)
 	flag.SetSubmissionListFlags(submitListCmd)
+	flag.SetDiscoveryFlags(submitListCmd)
+	flag.SetHashCacheFlags(submitListCmd)
 
 	rootCmd.AddCommand(submitListCmd)
 }
@@ -61,10 +64,15 @@ type SubmitListCommand struct {
 	outputFormatFlagValues   *flag.OutputFormatFlagValues
 	tokenFlagValues          *flag.TokenFlagValues
 	submissionListFlagValues *flag.SubmissionListFlagValues
+	discoveryFlagValues      *flag.DiscoveryFlagValues
+	hashCacheFlagValues      *flag.HashCacheFlagValues
 
 	account    *irodsclient_types.IRODSAccount
 	filesystem *irodsclient_fs.FileSystem
 	config     *config.Config
+	hashCache  *hashcache.HashCache
+
+	sourcePaths []string
 
 	dataRootPath string
 }
@@ -77,10 +85,15 @@ func NewSubmitListCommand(command *cobra.Command, args []string) (*SubmitListCom
 		outputFormatFlagValues:   flag.GetOutputFormatFlagValues(),
 		tokenFlagValues:          flag.GetTokenFlagValues(),
 		submissionListFlagValues: flag.GetSubmissionListFlagValues(),
+		discoveryFlagValues:      flag.GetDiscoveryFlagValues(),
+		hashCacheFlagValues:      flag.GetHashCacheFlagValues(),
 
 		config: config.GetConfig(),
 	}
 
+	// path
+	submitls.sourcePaths = args
+
 	return submitls, nil
 }
 
@@ -97,6 +110,41 @@ func (submitls *SubmitListCommand) Process() error {
 		return nil
 	}
 
+	if submitls.submissionListFlagValues.Compare {
+		if len(submitls.sourcePaths) == 0 {
+			return errors.New("data dirs to compare are not given")
+		}
+	} else if len(submitls.sourcePaths) > 0 {
+		return errors.New("data dirs are given without --compare")
+	}
+
+	// hash cache
+	if submitls.submissionListFlagValues.Compare && !submitls.hashCacheFlagValues.NoHashCache {
+		submitls.hashCache, err = hashcache.NewDefaultHashCache()
+		if err != nil {
+			// run without hash cache
+			logger.WithError(err).Warn("failed to open hash cache")
+			submitls.hashCache = nil
+		} else {
+			defer func() {
+				saveErr := submitls.hashCache.Save()
+				if saveErr != nil {
+					logger.WithError(saveErr).Warn("failed to save hash cache")
+				}
+			}()
+		}
+	}
+
+	// find simulation dirs to compare
+	validSourcePaths := []string{}
+	metadataOrcID := ""
+	if submitls.submissionListFlagValues.Compare {
+		validSourcePaths, metadataOrcID, err = submitls.scanSourcePaths()
+		if err != nil {
+			return errors.Wrapf(err, "failed to scan source paths")
+		}
+	}
+
 	// handle token
 	if len(submitls.tokenFlagValues.TicketString) > 0 {
 		submitls.config.TicketString = submitls.tokenFlagValues.TicketString
@@ -118,6 +166,8 @@ func (submitls *SubmitListCommand) Process() error {
 		orcID := ""
 		if len(submitls.submissionListFlagValues.OrcID) > 0 {
 			orcID = submitls.submissionListFlagValues.OrcID
+		} else if len(metadataOrcID) > 0 {
+			orcID = metadataOrcID
 		} else {
 			orcID = terminal.Input("Input ORCID")
 		}
@@ -145,28 +195,63 @@ func (submitls *SubmitListCommand) Process() error {
 		return types.NewTokenNotProvidedError()
 	}
 
-	// get ticket
-	mdRepoTicket, err := mdrepo.GetMDRepoTicketFromString(submitls.config.TicketString)
+	if submitls.submissionListFlagValues.Compare {
+		return submitls.compareSourcePaths(validSourcePaths)
+	}
+
+	// get tickets, a ticket for each simulation
+	mdRepoTickets, err := mdrepo.GetMDRepoTicketsFromString(submitls.config.TicketString)
 	if err != nil {
-		return errors.Wrapf(err, "failed to retrieve ticket")
+		return errors.Wrapf(err, "failed to retrieve tickets")
 	}
 
-	// Create a file system
+	// run
+	outputFormatter := format.NewOutputFormatter(terminal.GetTerminalWriter())
+
+	summaries := []*submissionListSummary{}
+	for _, mdRepoTicket := range mdRepoTickets {
+		summary, err := submitls.listTicket(outputFormatter, &mdRepoTicket)
+		if err != nil {
+			return err
+		}
+
+		summaries = append(summaries, summary)
+	}
+
+	submitls.makeSummaryTable(outputFormatter, summaries)
+
+	outputFormatter.Render(submitls.outputFormatFlagValues.Format)
+
+	return nil
+}
+
+// submissionListSummary is a summary of a simulation's landing collection
+type submissionListSummary struct {
+	SubmissionID string
+	Status       string
+	FileNumber   int64
+	FileSize     int64
+}
+
+func (submitls *SubmitListCommand) listTicket(outputFormatter *format.OutputFormatter, mdRepoTicket *mdrepo.MDRepoTicket) (*submissionListSummary, error) {
+	logger := log.WithFields(log.Fields{})
+
+	// we create filesystem for every ticket as they require separate auth
 	account, err := mdRepoTicket.GetAccount()
 	if err != nil {
-		return errors.Wrapf(err, "failed to get iRODS Account")
+		return nil, errors.Wrapf(err, "failed to get iRODS Account")
 	}
 
 	submitls.account = account
 
 	submitls.filesystem, err = irods.GetIRODSFSClient(submitls.account, true, submitls.commonFlagValues.Timeout)
 	if err != nil {
-		return errors.Wrapf(err, "failed to get iRODS FS Client")
+		return nil, errors.Wrapf(err, "failed to get iRODS FS Client")
 	}
-	defer submitls.filesystem.Release()
-
-	// run
-	outputFormatter := format.NewOutputFormatter(terminal.GetTerminalWriter())
+	defer func() {
+		submitls.filesystem.Release()
+		submitls.filesystem = nil
+	}()
 
 	sourcePath := commons_path.MakeIRODSLandingPath(mdRepoTicket.IRODSDataPath)
 
@@ -174,29 +259,62 @@ func (submitls *SubmitListCommand) Process() error {
 
 	logger.Debugf("list submission %q (ticket: %q)", sourcePath, mdRepoTicket.IRODSTicket)
 
-	err = submitls.listSourcePath(outputFormatter, sourcePath)
+	summary := &submissionListSummary{
+		SubmissionID: mdRepoTicket.GetSubmissionID(),
+	}
+
+	err = submitls.listSourcePath(outputFormatter, sourcePath, summary)
 	if err != nil {
-		return errors.Wrapf(err, "failed to list path %q", sourcePath)
+		return nil, errors.Wrapf(err, "failed to list path %q", sourcePath)
 	}
 
-	outputFormatter.Render(submitls.outputFormatFlagValues.Format)
+	return summary, nil
+}
 
-	return nil
+func (submitls *SubmitListCommand) makeSummaryTable(outputFormatter *format.OutputFormatter, summaries []*submissionListSummary) {
+	summaryTable := outputFormatter.NewTable("Submission Summary")
+	summaryTable.SetHeader([]string{
+		"Submission",
+		"Status",
+		"Files",
+		"Size",
+	})
+
+	totalFileNumber := int64(0)
+	totalFileSize := int64(0)
+	for _, summary := range summaries {
+		summaryTable.AppendRow([]interface{}{
+			summary.SubmissionID,
+			summary.Status,
+			fmt.Sprintf("%d", summary.FileNumber),
+			types.SizeString(summary.FileSize),
+		})
+
+		totalFileNumber += summary.FileNumber
+		totalFileSize += summary.FileSize
+	}
+
+	summaryTable.AppendRow([]interface{}{
+		"total",
+		"",
+		fmt.Sprintf("%d", totalFileNumber),
+		types.SizeString(totalFileSize),
+	})
 }
 
-func (submitls *SubmitListCommand) listSourcePath(outputFormatter *format.OutputFormatter, sourcePath string) error {
+func (submitls *SubmitListCommand) listSourcePath(outputFormatter *format.OutputFormatter, sourcePath string, summary *submissionListSummary) error {
 	connection, err := submitls.filesystem.GetMetadataConnection(true)
 	if err != nil {
 		return errors.Wrapf(err, "failed to get connection")
 	}
 	defer submitls.filesystem.ReturnMetadataConnection(connection)
 
-	err = submitls.printStatusFile(outputFormatter, sourcePath)
+	err = submitls.printStatusFile(outputFormatter, sourcePath, summary)
 	if err != nil {
 		return errors.Wrapf(err, "failed to print status file")
 	}
 
-	err = submitls.listCollection(outputFormatter, sourcePath)
+	err = submitls.listCollection(outputFormatter, sourcePath, summary)
 	if err != nil {
 		return errors.Wrapf(err, "failed to list collection %q", sourcePath)
 	}
@@ -204,7 +322,7 @@ func (submitls *SubmitListCommand) listSourcePath(outputFormatter *format.Output
 	return nil
 }
 
-func (submitls *SubmitListCommand) printStatusFile(outputFormatter *format.OutputFormatter, sourcePath string) error {
+func (submitls *SubmitListCommand) printStatusFile(outputFormatter *format.OutputFormatter, sourcePath string, summary *submissionListSummary) error {
 	logger := log.WithFields(log.Fields{})
 
 	connection, err := submitls.filesystem.GetMetadataConnection(true)
@@ -218,70 +336,164 @@ func (submitls *SubmitListCommand) printStatusFile(outputFormatter *format.Outpu
 		return errors.Wrapf(err, "failed to list data-objects in %q", sourcePath)
 	}
 
-	statusFilePath := ""
-
-	// find status file
-	var latestObjs *irodsclient_types.IRODSDataObject
-
+	// find status files, a file is written for each status
+	statuses := []*mdrepo.SubmitStatusFile{}
 	for _, obj := range objs {
 		if !mdrepo.IsStatusFile(obj.Name) {
 			continue
 		}
 
-		if len(obj.Replicas) == 0 {
-			continue
+		status, err := submitls.readStatusFile(obj.Path)
+		if err != nil {
+			return err
 		}
 
-		if latestObjs == nil {
-			latestObjs = obj
-			continue
+		if status.Time.IsZero() && len(obj.Replicas) > 0 {
+			// old status files may not have time
+			status.Time = submitls.getDataObjectModifyTime(obj)
 		}
 
-		if obj.Replicas[0].ModifyTime.After(latestObjs.Replicas[0].ModifyTime) {
-			latestObjs = obj
-		}
+		statuses = append(statuses, status)
 	}
 
-	if len(statusFilePath) == 0 {
+	if len(statuses) == 0 {
 		logger.Debugf("no status file found in %q", sourcePath)
 		return nil
 	}
 
-	buffer := bytes.Buffer{}
-
-	_, err = submitls.filesystem.DownloadFileToBuffer(statusFilePath, "", &buffer, false, nil)
-	if err != nil {
-		return errors.Wrapf(err, "failed to download file %q", statusFilePath)
-	}
-
-	status := mdrepo.SubmitStatusFile{}
-	err = json.Unmarshal(buffer.Bytes(), &status)
-	if err != nil {
-		return errors.Wrapf(err, "failed to decode json")
-	}
+	sort.SliceStable(statuses, func(i int, j int) bool {
+		return statuses[i].Time.Before(statuses[j].Time)
+	})
 
-	outputFormatterTable := outputFormatter.NewTable("Submission Status")
+	latestStatus := statuses[len(statuses)-1]
+	summary.Status = latestStatus.Status.String()
 
-	outputFormatterTable.SetHeader([]string{
+	// latest status
+	statusTable := outputFormatter.NewTable(fmt.Sprintf("Submission Status of %s", summary.SubmissionID))
+	statusTable.SetHeader([]string{
 		"Total Files",
 		"Total Size",
-		"Token",
+		"Token Fingerprint",
 		"Status",
 		"Time",
+		"Client Version",
+		"Platform",
+		"Metadata MD5 Hash",
 	})
 
-	outputFormatterTable.AppendRow([]interface{}{
-		fmt.Sprintf("%d", status.TotalFileNumber),
-		types.SizeString(status.TotalFileSize),
-		status.Token,
-		status.Status,
-		status.Time,
+	clientVersion := ""
+	platform := ""
+	metadataHash := ""
+	if latestStatus.Provenance != nil {
+		clientVersion = latestStatus.Provenance.ClientVersion
+		platform = latestStatus.Provenance.Platform
+		metadataHash = latestStatus.Provenance.MetadataMD5Hash
+	}
+
+	statusTable.AppendRow([]interface{}{
+		fmt.Sprintf("%d", latestStatus.TotalFileNumber),
+		types.SizeString(latestStatus.TotalFileSize),
+		mdrepo.GetTokenFingerprint(latestStatus.Token),
+		latestStatus.Status,
+		types.MakeDateTimeString(latestStatus.Time),
+		clientVersion,
+		platform,
+		metadataHash,
 	})
 
+	// how status changed over time
+	historyTable := outputFormatter.NewTable(fmt.Sprintf("Submission Status History of %s", summary.SubmissionID))
+	historyTable.SetHeader([]string{
+		"Time",
+		"Status",
+		"Total Files",
+		"Total Size",
+		"Completed Files",
+		"Transferred Size",
+		"Last Update",
+	})
+
+	for _, status := range statuses {
+		completedFiles := ""
+		transferredSize := ""
+		if status.CompletedFileNumber > 0 || status.TransferredSize > 0 {
+			completedFiles = fmt.Sprintf("%d", status.CompletedFileNumber)
+			transferredSize = types.SizeString(status.TransferredSize)
+		}
+
+		lastUpdate := ""
+		if status.LastUpdate != nil {
+			lastUpdate = types.MakeDateTimeString(*status.LastUpdate)
+		}
+
+		historyTable.AppendRow([]interface{}{
+			types.MakeDateTimeString(status.Time),
+			status.Status,
+			fmt.Sprintf("%d", status.TotalFileNumber),
+			types.SizeString(status.TotalFileSize),
+			completedFiles,
+			transferredSize,
+			lastUpdate,
+		})
+	}
+
+	// files in the latest status
+	filesTable := outputFormatter.NewTable(fmt.Sprintf("Submission Status Files of %s", summary.SubmissionID))
+	filesTable.SetHeader([]string{
+		"Path",
+		"Size",
+		"MD5 Hash",
+		"Attempts",
+		"Upload Start Time",
+		"Upload End Time",
+	})
+
+	for _, file := range latestStatus.Files {
+		attempts := ""
+		if file.Attempts > 0 {
+			attempts = fmt.Sprintf("%d", file.Attempts)
+		}
+
+		startTime := ""
+		if file.StartTime != nil {
+			startTime = types.MakeDateTimeString(*file.StartTime)
+		}
+
+		endTime := ""
+		if file.EndTime != nil {
+			endTime = types.MakeDateTimeString(*file.EndTime)
+		}
+
+		filesTable.AppendRow([]interface{}{
+			file.IRODSPath,
+			fmt.Sprintf("%d", file.Size),
+			file.MD5Hash,
+			attempts,
+			startTime,
+			endTime,
+		})
+	}
+
 	return nil
 }
 
-func (submitls *SubmitListCommand) listCollection(outputFormatter *format.OutputFormatter, sourcePath string) error {
+func (submitls *SubmitListCommand) readStatusFile(statusFilePath string) (*mdrepo.SubmitStatusFile, error) {
+	buffer := bytes.Buffer{}
+
+	_, err := submitls.filesystem.DownloadFileToBuffer(statusFilePath, "", &buffer, false, nil)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to download file %q", statusFilePath)
+	}
+
+	status, err := mdrepo.ParseSubmitStatusFile(buffer.Bytes())
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read status file %q", statusFilePath)
+	}
+
+	return status, nil
+}
+
+func (submitls *SubmitListCommand) listCollection(outputFormatter *format.OutputFormatter, sourcePath string, summary *submissionListSummary) error {
 	connection, err := submitls.filesystem.GetMetadataConnection(true)
 	if err != nil {
 		return errors.Wrapf(err, "failed to get connection")
@@ -323,12 +535,21 @@ func (submitls *SubmitListCommand) listCollection(outputFormatter *format.Output
 		return errors.Wrapf(err, "failed to list data-objects in %q", sourcePath)
 	}
 
+	for _, obj := range objs {
+		if sourcePath == submitls.dataRootPath && mdrepo.IsStatusFile(obj.Name) {
+			continue
+		}
+
+		summary.FileNumber++
+		summary.FileSize += obj.Size
+	}
+
 	submitls.printDataObjectsAndCollections(outputFormatter, sourcePath, objs, colls, false)
 
 	// call recursively
 	for _, coll := range colls {
 		terminal.Printf("\n")
-		err = submitls.listCollection(outputFormatter, coll.Path)
+		err = submitls.listCollection(outputFormatter, coll.Path, summary)
 		if err != nil {
 			return errors.Wrapf(err, "failed to list %q", coll.Path)
 		}
@@ -438,3 +659,250 @@ func (submitls *SubmitListCommand) getCollectionSortFunction(entries []*irodscli
 		return entries[i].Name < entries[j].Name
 	}
 }
+
+// scanSourcePaths finds simulation dirs to compare, returns ORCID in metadata
+func (submitls *SubmitListCommand) scanSourcePaths() ([]string, string, error) {
+	discoveryOptions := &mdrepo.SubmissionDiscoveryOptions{
+		Recursive: submitls.discoveryFlagValues.Recursive,
+		MaxDepth:  submitls.discoveryFlagValues.MaxDepth,
+		Include:   submitls.discoveryFlagValues.Include,
+		Exclude:   submitls.discoveryFlagValues.Exclude,
+	}
+
+	validSourcePaths, invalidSourcePaths, invalidSourcePathsErrors, err := mdrepo.FindSubmissionSourcePaths(submitls.sourcePaths, discoveryOptions)
+	if err != nil {
+		return nil, "", err
+	}
+
+	terminal.Printf("found %d simulation directories\n", len(validSourcePaths))
+	for sourceIdx, sourcePath := range invalidSourcePaths {
+		if len(invalidSourcePathsErrors) > sourceIdx {
+			terminal.Printf("WARNING: skipped %q: %s\n", sourcePath, invalidSourcePathsErrors[sourceIdx])
+		} else {
+			terminal.Printf("WARNING: skipped %q\n", sourcePath)
+		}
+	}
+
+	if len(validSourcePaths) == 0 {
+		return nil, "", errors.Errorf("no simulation directories are found in %v", submitls.sourcePaths)
+	}
+
+	metadata, err := mdrepo.ParseSubmitMetadataDir(validSourcePaths[0])
+	if err != nil {
+		return nil, "", errors.Wrapf(err, "failed to parse metadata for %q", validSourcePaths[0])
+	}
+
+	orcID, err := metadata.GetOrcID()
+	if err != nil {
+		// ask later
+		orcID = ""
+	}
+
+	return validSourcePaths, orcID, nil
+}
+
+// compareSourcePaths compares simulation dirs with landing collections paired as submit does
+// returns SubmissionNotMatchingError if any file differs
+func (submitls *SubmitListCommand) compareSourcePaths(sourcePaths []string) error {
+	mdRepoTickets, err := mdrepo.GetMDRepoTicketsFromString(submitls.config.TicketString)
+	if err != nil {
+		return errors.Wrapf(err, "failed to retrieve tickets")
+	}
+
+	rootPath, err := mdrepo.GetSubmissionRootPath(submitls.sourcePaths)
+	if err != nil {
+		return err
+	}
+
+	mapping, _, err := mdrepo.GetSubmitMapping(rootPath, submitls.submissionListFlagValues.Mapping, sourcePaths, mdRepoTickets, false)
+	if err != nil {
+		return err
+	}
+
+	mappedTickets, err := mapping.ResolveTickets(sourcePaths, mdRepoTickets)
+	if err != nil {
+		return err
+	}
+
+	outputFormatter := format.NewOutputFormatter(terminal.GetTerminalWriter())
+
+	differenceTable := outputFormatter.NewTable("Submission Differences")
+	differenceTable.SetHeader([]string{
+		"Submission",
+		"Path",
+		"Result",
+		"Local Size",
+		"Submitted Size",
+		"Checksum Algorithm",
+		"Local Checksum",
+		"Submitted Checksum",
+	})
+
+	summaryTable := outputFormatter.NewTable("Submission Comparison Summary")
+	summaryTable.SetHeader([]string{
+		"Submission",
+		"Local Path",
+		"Missing",
+		"Extra",
+		"Size Mismatch",
+		"Checksum Mismatch",
+		"Unverified",
+	})
+
+	notMatchingSourcePaths := []string{}
+	differences := 0
+
+	for sourceIdx, sourcePath := range sourcePaths {
+		mdRepoTicket := mappedTickets[sourceIdx]
+
+		entries, err := submitls.compareSourcePath(sourcePath, &mdRepoTicket)
+		if err != nil {
+			return errors.Wrapf(err, "failed to compare %q with submission %q", sourcePath, mdRepoTicket.GetSubmissionID())
+		}
+
+		counts := map[mdrepo.SubmitCompareResult]int{}
+		for _, entry := range entries {
+			counts[entry.Result]++
+
+			localSize := ""
+			if len(entry.LocalPath) > 0 {
+				localSize = fmt.Sprintf("%d", entry.LocalSize)
+			}
+
+			irodsSize := ""
+			if len(entry.IRODSPath) > 0 {
+				irodsSize = fmt.Sprintf("%d", entry.IRODSSize)
+			}
+
+			differenceTable.AppendRow([]interface{}{
+				mdRepoTicket.GetSubmissionID(),
+				entry.Path,
+				string(entry.Result),
+				localSize,
+				irodsSize,
+				entry.ChecksumAlgorithm,
+				entry.LocalChecksum,
+				entry.IRODSChecksum,
+			})
+
+			if entry.Result.IsDifference() {
+				differences++
+			}
+		}
+
+		summaryTable.AppendRow([]interface{}{
+			mdRepoTicket.GetSubmissionID(),
+			sourcePath,
+			counts[mdrepo.SubmitCompareResultMissing],
+			counts[mdrepo.SubmitCompareResultExtra],
+			counts[mdrepo.SubmitCompareResultSizeMismatch],
+			counts[mdrepo.SubmitCompareResultChecksumMismatch],
+			counts[mdrepo.SubmitCompareResultUnverified],
+		})
+
+		if len(entries) > counts[mdrepo.SubmitCompareResultUnverified] {
+			notMatchingSourcePaths = append(notMatchingSourcePaths, sourcePath)
+		}
+	}
+
+	outputFormatter.Render(submitls.outputFormatFlagValues.Format)
+
+	if differences > 0 {
+		return types.NewSubmissionNotMatchingError(notMatchingSourcePaths, differences)
+	}
+
+	terminal.Printf("all files match submitted data\n")
+	return nil
+}
+
+func (submitls *SubmitListCommand) compareSourcePath(sourcePath string, mdRepoTicket *mdrepo.MDRepoTicket) ([]mdrepo.SubmitCompareEntry, error) {
+	logger := log.WithFields(log.Fields{})
+
+	metadata, err := mdrepo.ParseSubmitMetadataDir(sourcePath)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse submit metadata in dir %q", sourcePath)
+	}
+
+	localFiles := []mdrepo.SubmitCompareLocalFile{}
+	for _, sourceFile := range mdrepo.GetSubmissionFiles(metadata) {
+		localPath := metadata.GetSubmitFileLocalPath(sourceFile)
+
+		localStat, err := os.Stat(localPath)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to stat %q", localPath)
+		}
+
+		localFiles = append(localFiles, mdrepo.SubmitCompareLocalFile{
+			Path:      sourceFile,
+			LocalPath: localPath,
+			Size:      localStat.Size(),
+		})
+	}
+
+	// we create filesystem for every ticket as they require separate auth
+	account, err := mdRepoTicket.GetAccount()
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get iRODS Account")
+	}
+
+	submitls.account = account
+
+	submitls.filesystem, err = irods.GetIRODSFSClient(submitls.account, true, submitls.commonFlagValues.Timeout)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get iRODS FS Client")
+	}
+	defer func() {
+		submitls.filesystem.Release()
+		submitls.filesystem = nil
+	}()
+
+	targetPath := commons_path.MakeIRODSLandingPath(mdRepoTicket.IRODSDataPath)
+
+	logger.Debugf("compare %q with %q (ticket: %q)", sourcePath, targetPath, mdRepoTicket.IRODSTicket)
+
+	remoteFiles, err := submitls.listSubmittedFiles(targetPath, targetPath)
+	if err != nil {
+		return nil, err
+	}
+
+	hashFunc := func(localPath string, algorithm string) ([]byte, error) {
+		return submitls.hashCache.HashLocalFile(localPath, algorithm, nil)
+	}
+
+	return mdrepo.CompareSubmissionFiles(localFiles, remoteFiles, hashFunc)
+}
+
+// listSubmittedFiles lists data objects under the landing collection recursively, except status files
+func (submitls *SubmitListCommand) listSubmittedFiles(landingPath string, collectionPath string) ([]mdrepo.SubmitCompareRemoteFile, error) {
+	entries, err := submitls.filesystem.List(collectionPath)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list %q", collectionPath)
+	}
+
+	remoteFiles := []mdrepo.SubmitCompareRemoteFile{}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			subRemoteFiles, err := submitls.listSubmittedFiles(landingPath, entry.Path)
+			if err != nil {
+				return nil, err
+			}
+
+			remoteFiles = append(remoteFiles, subRemoteFiles...)
+			continue
+		}
+
+		if collectionPath == landingPath && mdrepo.IsStatusFile(entry.Name) {
+			continue
+		}
+
+		remoteFiles = append(remoteFiles, mdrepo.SubmitCompareRemoteFile{
+			Path:              path.GetIRODSRelativePath(landingPath, entry.Path),
+			IRODSPath:         entry.Path,
+			Size:              entry.Size,
+			ChecksumAlgorithm: string(entry.CheckSumAlgorithm),
+			Checksum:          entry.CheckSum,
+		})
+	}
+
+	return remoteFiles, nil
+}
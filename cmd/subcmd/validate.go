@@ -0,0 +1,424 @@
+package subcmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/MD-Repo/md-repo-cli/cmd/flag"
+	"github.com/MD-Repo/md-repo-cli/commons/format"
+	"github.com/MD-Repo/md-repo-cli/commons/hashcache"
+	"github.com/MD-Repo/md-repo-cli/commons/mdrepo"
+	"github.com/MD-Repo/md-repo-cli/commons/terminal"
+	"github.com/MD-Repo/md-repo-cli/commons/types"
+	"github.com/cockroachdb/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"golang.org/x/exp/slices"
+)
+
+var validateCmd = &cobra.Command{
+	Use:     "validate <data dirs> ...",
+	Short:   "Validate local data before submitting to MD-Repo",
+	Long:    "This command validates local data and submit metadata without accessing MD-Repo",
+	Aliases: []string{"check"},
+	RunE:    processValidateCommand,
+	Args:    cobra.MinimumNArgs(1),
+}
+
+func AddValidateCommand(rootCmd *cobra.Command) {
+	// attach common flags
+	flag.SetCommonFlags(validateCmd)
+
+	flag.SetMetadataFlags(validateCmd)
+	flag.SetDiscoveryFlags(validateCmd)
+	flag.SetOutputFormatFlags(validateCmd, true)
+	flag.SetHashCacheFlags(validateCmd)
+
+	rootCmd.AddCommand(validateCmd)
+}
+
+func processValidateCommand(command *cobra.Command, args []string) error {
+	validate, err := NewValidateCommand(command, args)
+	if err != nil {
+		return err
+	}
+
+	return validate.Process()
+}
+
+type validateStatus string
+
+const (
+	validateStatusValid   validateStatus = "valid"
+	validateStatusInvalid validateStatus = "invalid"
+	validateStatusIgnored validateStatus = "ignored"
+)
+
+type validateResult struct {
+	SourcePath string
+	Status     validateStatus
+	FileNumber int
+	TotalSize  int64
+	Errors     []error
+	Warnings   []string
+}
+
+func (result *validateResult) addError(err error) {
+	var invalidSubmitMetadataError *types.InvalidSubmitMetadataError
+	if errors.As(err, &invalidSubmitMetadataError) {
+		// report each error separately
+		result.Errors = append(result.Errors, invalidSubmitMetadataError.Errors...)
+	} else {
+		result.Errors = append(result.Errors, err)
+	}
+
+	if result.Status == validateStatusValid {
+		result.Status = validateStatusInvalid
+	}
+}
+
+type ValidateCommand struct {
+	command *cobra.Command
+
+	commonFlagValues       *flag.CommonFlagValues
+	metadataFlagValues     *flag.MetadataFlagValues
+	discoveryFlagValues    *flag.DiscoveryFlagValues
+	outputFormatFlagValues *flag.OutputFormatFlagValues
+	hashCacheFlagValues    *flag.HashCacheFlagValues
+
+	sourcePaths []string
+
+	hashCache *hashcache.HashCache
+
+	fileHashes map[string]string // file path -> md5 hash
+}
+
+func NewValidateCommand(command *cobra.Command, args []string) (*ValidateCommand, error) {
+	validate := &ValidateCommand{
+		command: command,
+
+		commonFlagValues:       flag.GetCommonFlagValues(command),
+		metadataFlagValues:     flag.GetMetadataFlagValues(),
+		discoveryFlagValues:    flag.GetDiscoveryFlagValues(),
+		outputFormatFlagValues: flag.GetOutputFormatFlagValues(),
+		hashCacheFlagValues:    flag.GetHashCacheFlagValues(),
+
+		fileHashes: map[string]string{},
+	}
+
+	// path
+	validate.sourcePaths = args
+
+	return validate, nil
+}
+
+func (validate *ValidateCommand) Process() error {
+	logger := log.WithFields(log.Fields{})
+
+	cont, err := flag.ProcessCommonFlags(validate.command)
+	if err != nil {
+		return errors.Wrapf(err, "failed to process common flags")
+	}
+
+	if !cont {
+		return nil
+	}
+
+	// hash cache
+	if !validate.hashCacheFlagValues.NoHashCache {
+		validate.hashCache, err = hashcache.NewDefaultHashCache()
+		if err != nil {
+			// run without hash cache
+			logger.WithError(err).Warn("failed to open hash cache")
+			validate.hashCache = nil
+		} else {
+			defer func() {
+				saveErr := validate.hashCache.Save()
+				if saveErr != nil {
+					logger.WithError(saveErr).Warn("failed to save hash cache")
+				}
+			}()
+		}
+	}
+
+	validSourcePaths, invalidSourcePaths, invalidSourcePathsErrors, err := mdrepo.FindSubmissionSourcePaths(validate.sourcePaths, validate.getDiscoveryOptions())
+	if err != nil {
+		return errors.Wrapf(err, "failed to scan source paths")
+	}
+
+	results := []*validateResult{}
+	for _, validSourcePath := range validSourcePaths {
+		result := validate.validateSourcePath(validSourcePath)
+		results = append(results, result)
+	}
+
+	validate.checkDuplicateFiles(results)
+	validate.checkOrcIDs(results)
+
+	for sourceIdx, sourcePath := range invalidSourcePaths {
+		result := &validateResult{
+			SourcePath: sourcePath,
+			Status:     validateStatusIgnored,
+			Errors:     []error{},
+			Warnings:   []string{},
+		}
+
+		if len(invalidSourcePathsErrors) > sourceIdx {
+			result.Errors = append(result.Errors, invalidSourcePathsErrors[sourceIdx])
+		}
+
+		results = append(results, result)
+	}
+
+	rejectedSourcePaths := []string{}
+	for _, result := range results {
+		if result.Status == validateStatusInvalid {
+			rejectedSourcePaths = append(rejectedSourcePaths, result.SourcePath)
+		}
+	}
+
+	if len(validSourcePaths) == 0 {
+		// nothing to submit
+		rejectedSourcePaths = append(rejectedSourcePaths, invalidSourcePaths...)
+	}
+
+	outputFormatter := format.NewOutputFormatter(terminal.GetTerminalWriter())
+	validate.printResults(outputFormatter, results)
+	outputFormatter.Render(validate.outputFormatFlagValues.Format)
+
+	if len(rejectedSourcePaths) > 0 {
+		logger.Debugf("%d simulation directories would be rejected", len(rejectedSourcePaths))
+		return types.NewSubmissionRejectedError(rejectedSourcePaths)
+	}
+
+	return nil
+}
+
+func (validate *ValidateCommand) validateSourcePath(sourcePath string) *validateResult {
+	result := &validateResult{
+		SourcePath: sourcePath,
+		Status:     validateStatusValid,
+		Errors:     []error{},
+		Warnings:   []string{},
+	}
+
+	metadata, err := mdrepo.ParseSubmitMetadataDir(sourcePath)
+	if err != nil {
+		result.addError(err)
+		return result
+	}
+
+	fileHashes, err := mdrepo.HashSubmissionFiles(metadata, validate.hashCache)
+	if err != nil {
+		result.addError(err)
+	}
+
+	for absFilePath, hashStr := range fileHashes {
+		validate.fileHashes[absFilePath] = hashStr
+	}
+
+	orphanFiles, err := mdrepo.FindOrphanSubmissionFiles(metadata)
+	if err != nil {
+		result.addError(err)
+	}
+
+	for _, orphanFile := range orphanFiles {
+		result.Warnings = append(result.Warnings, fmt.Sprintf("file %q is not listed in metadata and will not be submitted", orphanFile))
+	}
+
+	err = metadata.ValidateFiles()
+	if err != nil {
+		result.addError(err)
+	}
+
+	if validate.metadataFlagValues.Strict {
+		err = metadata.ValidateKeys()
+		if err != nil {
+			result.addError(err)
+		}
+
+		err = metadata.ValidateFormats()
+		if err != nil {
+			result.addError(err)
+		}
+	} else {
+		for _, unknownKey := range metadata.GetUnknownKeys() {
+			result.Warnings = append(result.Warnings, unknownKey.String())
+		}
+
+		result.Warnings = append(result.Warnings, metadata.GetFormatWarnings()...)
+	}
+
+	// count files to be submitted, including the metadata file itself
+	for _, sourceFile := range mdrepo.GetSubmissionFiles(metadata) {
+		st, err := os.Stat(metadata.GetSubmitFileLocalPath(sourceFile))
+		if err != nil || st.IsDir() {
+			continue
+		}
+
+		result.FileNumber++
+		result.TotalSize += st.Size()
+	}
+
+	return result
+}
+
+func (validate *ValidateCommand) checkDuplicateFiles(results []*validateResult) {
+	duplicates := mdrepo.GetDuplicateSubmissionFiles(validate.fileHashes)
+
+	hashStrs := []string{}
+	for hashStr := range duplicates {
+		hashStrs = append(hashStrs, hashStr)
+	}
+	slices.Sort(hashStrs)
+
+	for _, hashStr := range hashStrs {
+		files := duplicates[hashStr]
+		for _, file := range files {
+			result := validate.findResultForFile(results, file)
+			if result == nil {
+				continue
+			}
+
+			others := []string{}
+			for _, other := range files {
+				if other != file {
+					others = append(others, other)
+				}
+			}
+
+			result.addError(errors.Errorf("file %q has the same MD5 hash %s as %s", file, hashStr, strings.Join(others, ", ")))
+		}
+	}
+}
+
+func (validate *ValidateCommand) checkOrcIDs(results []*validateResult) {
+	orcIDFound := ""
+	for _, result := range results {
+		metadata, err := mdrepo.ParseSubmitMetadataDir(result.SourcePath)
+		if err != nil {
+			continue
+		}
+
+		myOrcID, err := metadata.GetOrcID()
+		if err != nil {
+			continue
+		}
+
+		if len(orcIDFound) == 0 {
+			orcIDFound = myOrcID
+			continue
+		}
+
+		if orcIDFound != myOrcID {
+			result.addError(errors.Errorf("Lead Contributor's ORCID mismatch for %q, expected %s, but got %s: %w", result.SourcePath, orcIDFound, myOrcID, types.NewInvalidOrcIDError(myOrcID, orcIDFound)))
+		}
+	}
+}
+
+func (validate *ValidateCommand) findResultForFile(results []*validateResult, filePath string) *validateResult {
+	for _, result := range results {
+		rel, err := filepath.Rel(result.SourcePath, filePath)
+		if err != nil {
+			continue
+		}
+
+		if rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return result
+		}
+	}
+
+	return nil
+}
+
+func (validate *ValidateCommand) printResults(outputFormatter *format.OutputFormatter, results []*validateResult) {
+	outputFormatterTable := outputFormatter.NewTable("Validation Results")
+
+	outputFormatterTable.SetHeader([]string{
+		"Simulation",
+		"Status",
+		"Files",
+		"Total Size",
+		"Errors",
+		"Warnings",
+	})
+
+	for _, result := range results {
+		totalSize := ""
+		if result.Status != validateStatusIgnored {
+			totalSize = types.SizeString(result.TotalSize)
+		}
+
+		outputFormatterTable.AppendRow([]interface{}{
+			result.SourcePath,
+			string(result.Status),
+			fmt.Sprintf("%d", result.FileNumber),
+			totalSize,
+			fmt.Sprintf("%d", len(result.Errors)),
+			fmt.Sprintf("%d", len(result.Warnings)),
+		})
+	}
+
+	hasErrors := false
+	hasWarnings := false
+	for _, result := range results {
+		if len(result.Errors) > 0 {
+			hasErrors = true
+		}
+
+		if len(result.Warnings) > 0 {
+			hasWarnings = true
+		}
+	}
+
+	if hasWarnings {
+		outputFormatterWarningTable := outputFormatter.NewTable("Validation Warnings")
+
+		outputFormatterWarningTable.SetHeader([]string{
+			"Simulation",
+			"Warning",
+		})
+
+		for _, result := range results {
+			for _, resultWarning := range result.Warnings {
+				outputFormatterWarningTable.AppendRow([]interface{}{
+					result.SourcePath,
+					resultWarning,
+				})
+			}
+		}
+	}
+
+	if !hasErrors {
+		return
+	}
+
+	outputFormatterErrorTable := outputFormatter.NewTable("Validation Errors")
+
+	outputFormatterErrorTable.SetHeader([]string{
+		"Simulation",
+		"Status",
+		"Error",
+	})
+
+	for _, result := range results {
+		for _, resultErr := range result.Errors {
+			outputFormatterErrorTable.AppendRow([]interface{}{
+				result.SourcePath,
+				string(result.Status),
+				resultErr.Error(),
+			})
+		}
+	}
+}
+
+func (validate *ValidateCommand) getDiscoveryOptions() *mdrepo.SubmissionDiscoveryOptions {
+	return &mdrepo.SubmissionDiscoveryOptions{
+		Recursive: validate.discoveryFlagValues.Recursive,
+		MaxDepth:  validate.discoveryFlagValues.MaxDepth,
+		Include:   validate.discoveryFlagValues.Include,
+		Exclude:   validate.discoveryFlagValues.Exclude,
+	}
+}
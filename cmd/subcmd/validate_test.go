@@ -0,0 +1,32 @@
+package subcmd
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidate(t *testing.T) {
+	t.Run("test FindResultForFile", testFindResultForFile)
+}
+
+func testFindResultForFile(t *testing.T) {
+	rootPath := t.TempDir()
+
+	validate := &ValidateCommand{}
+	results := []*validateResult{
+		{SourcePath: filepath.Join(rootPath, "sim1")},
+		{SourcePath: filepath.Join(rootPath, "sim2")},
+	}
+
+	assert.Equal(t, results[0], validate.findResultForFile(results, filepath.Join(rootPath, "sim1", "run.xtc")))
+	assert.Equal(t, results[1], validate.findResultForFile(results, filepath.Join(rootPath, "sim2")))
+
+	// names starting with ".." are not in parent dirs
+	assert.Equal(t, results[0], validate.findResultForFile(results, filepath.Join(rootPath, "sim1", "..run.xtc")))
+	assert.Equal(t, results[1], validate.findResultForFile(results, filepath.Join(rootPath, "sim2", "..data", "run.xtc")))
+
+	assert.Nil(t, validate.findResultForFile(results, filepath.Join(rootPath, "sim3", "run.xtc")))
+	assert.Nil(t, validate.findResultForFile(results, rootPath))
+}
@@ -0,0 +1,555 @@
+package subcmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/cockroachdb/errors"
+	irodsclient_fs "github.com/cyverse/go-irodsclient/fs"
+	irodsclient_irodsfs "github.com/cyverse/go-irodsclient/irods/fs"
+	irodsclient_types "github.com/cyverse/go-irodsclient/irods/types"
+
+	"github.com/MD-Repo/md-repo-cli/cmd/flag"
+	"github.com/MD-Repo/md-repo-cli/commons/config"
+	"github.com/MD-Repo/md-repo-cli/commons/format"
+	"github.com/MD-Repo/md-repo-cli/commons/hashcache"
+	"github.com/MD-Repo/md-repo-cli/commons/irods"
+	"github.com/MD-Repo/md-repo-cli/commons/mdrepo"
+	"github.com/MD-Repo/md-repo-cli/commons/parallel"
+	commons_path "github.com/MD-Repo/md-repo-cli/commons/path"
+	"github.com/MD-Repo/md-repo-cli/commons/terminal"
+	"github.com/MD-Repo/md-repo-cli/commons/transfer"
+	"github.com/MD-Repo/md-repo-cli/commons/types"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var verifyCmd = &cobra.Command{
+	Use:   "verify [local dir]",
+	Short: "Verify downloaded MD-Repo data",
+	Long:  `This verifies MD-Repo data downloaded to the specified local directory against the release, reporting missing, corrupt and extra files.`,
+	RunE:  processVerifyCommand,
+	Args:  cobra.MaximumNArgs(1),
+}
+
+func AddVerifyCommand(rootCmd *cobra.Command) {
+	// attach common flags
+	flag.SetCommonFlags(verifyCmd)
+
+	flag.SetTokenFlags(verifyCmd)
+	flag.SetVerifyFlags(verifyCmd)
+	flag.SetHashCacheFlags(verifyCmd)
+	flag.SetOutputFormatFlags(verifyCmd, true)
+
+	// used to repair
+	flag.SetParallelTransferFlags(verifyCmd, false, false)
+	flag.SetProgressFlags(verifyCmd)
+	flag.SetRetryFlags(verifyCmd)
+	flag.SetTransferReportFlags(verifyCmd)
+
+	rootCmd.AddCommand(verifyCmd)
+}
+
+func processVerifyCommand(command *cobra.Command, args []string) error {
+	verify, err := NewVerifyCommand(command, args)
+	if err != nil {
+		return err
+	}
+
+	return verify.Process()
+}
+
+type VerifyCommand struct {
+	command *cobra.Command
+
+	commonFlagValues         *flag.CommonFlagValues
+	tokenFlagValues          *flag.TokenFlagValues
+	verifyFlagValues         *flag.VerifyFlagValues
+	hashCacheFlagValues      *flag.HashCacheFlagValues
+	outputFormatFlagValues   *flag.OutputFormatFlagValues
+	transferReportFlagValues *flag.TransferReportFlagValues
+
+	account    *irodsclient_types.IRODSAccount
+	filesystem *irodsclient_fs.FileSystem
+
+	targetPath string
+
+	config    *config.Config
+	hashCache *hashcache.HashCache
+
+	// downloads missing or corrupt files, only when repairing
+	get *GetCommand
+}
+
+func NewVerifyCommand(command *cobra.Command, args []string) (*VerifyCommand, error) {
+	verify := &VerifyCommand{
+		command: command,
+
+		commonFlagValues:         flag.GetCommonFlagValues(command),
+		tokenFlagValues:          flag.GetTokenFlagValues(),
+		verifyFlagValues:         flag.GetVerifyFlagValues(),
+		hashCacheFlagValues:      flag.GetHashCacheFlagValues(),
+		outputFormatFlagValues:   flag.GetOutputFormatFlagValues(),
+		transferReportFlagValues: flag.GetTransferReportFlagValues(command),
+
+		config: config.GetConfig(),
+	}
+
+	// path
+	verify.targetPath = "./"
+
+	if len(args) > 0 {
+		verify.targetPath = args[0]
+	}
+
+	return verify, nil
+}
+
+func (verify *VerifyCommand) Process() error {
+	logger := log.WithFields(log.Fields{})
+
+	terminal.Printf("verifying MD-Repo data in a local directory\n")
+
+	cont, err := flag.ProcessCommonFlags(verify.command)
+	if err != nil {
+		return errors.Wrapf(err, "failed to process common flags")
+	}
+
+	if !cont {
+		return nil
+	}
+
+	// hash cache
+	if !verify.hashCacheFlagValues.NoHashCache {
+		verify.hashCache, err = hashcache.NewDefaultHashCache()
+		if err != nil {
+			// run without hash cache
+			logger.WithError(err).Warn("failed to open hash cache")
+			verify.hashCache = nil
+		} else {
+			defer func() {
+				saveErr := verify.hashCache.Save()
+				if saveErr != nil {
+					logger.WithError(saveErr).Warn("failed to save hash cache")
+				}
+			}()
+		}
+	}
+
+	// handle token
+	if len(verify.tokenFlagValues.TicketString) > 0 {
+		verify.config.TicketString = verify.tokenFlagValues.TicketString
+	}
+
+	if len(verify.tokenFlagValues.Token) > 0 {
+		verify.config.Token = verify.tokenFlagValues.Token
+	}
+
+	// handle local flags
+	_, err = config.InputMissingFields()
+	if err != nil {
+		return errors.Wrapf(err, "failed to input missing fields")
+	}
+
+	if len(verify.config.Token) > 0 && len(verify.config.TicketString) == 0 {
+		verify.config.TicketString, err = mdrepo.GetMDRepoTicketStringFromToken(verify.tokenFlagValues.ServiceURL, verify.config.Token)
+		if err != nil {
+			return errors.Wrapf(err, "failed to read ticket from token %q", verify.config.Token)
+		}
+	}
+
+	if len(verify.config.TicketString) == 0 {
+		return types.NewTokenNotProvidedError()
+	}
+
+	// get ticket
+	mdRepoTickets, err := mdrepo.GetMDRepoTicketsFromString(verify.config.TicketString)
+	if err != nil {
+		return errors.Wrapf(err, "failed to retrieve tickets")
+	}
+
+	verify.targetPath = commons_path.MakeLocalPath(verify.targetPath)
+
+	targetStat, err := os.Stat(verify.targetPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return types.NewNotDirError(verify.targetPath)
+		}
+
+		return errors.Wrapf(err, "failed to stat %q", verify.targetPath)
+	}
+
+	if !targetStat.IsDir() {
+		return types.NewNotDirError(verify.targetPath)
+	}
+
+	if verify.verifyFlagValues.Repair {
+		// repair through the download path of get
+		verify.get, err = NewGetCommand(verify.command, []string{verify.targetPath})
+		if err != nil {
+			return err
+		}
+
+		verify.get.hashCache = verify.hashCache
+
+		verify.get.transferReportManager, err = transfer.NewTransferReportManager(verify.transferReportFlagValues.Report, verify.transferReportFlagValues.ReportPath, verify.transferReportFlagValues.ReportToStdout)
+		if err != nil {
+			return errors.Wrapf(err, "failed to create transfer report manager")
+		}
+		defer verify.get.transferReportManager.Release()
+	}
+
+	// group tickets by IRODSTicket to share filesystem
+	ticketGroups := make(map[string][]mdrepo.MDRepoTicket)
+	ticketGroupOrder := []string{}
+	for _, mdRepoTicket := range mdRepoTickets {
+		if _, exists := ticketGroups[mdRepoTicket.IRODSTicket]; !exists {
+			ticketGroupOrder = append(ticketGroupOrder, mdRepoTicket.IRODSTicket)
+		}
+		ticketGroups[mdRepoTicket.IRODSTicket] = append(ticketGroups[mdRepoTicket.IRODSTicket], mdRepoTicket)
+	}
+
+	outputFormatter := format.NewOutputFormatter(terminal.GetTerminalWriter())
+
+	resultTable := outputFormatter.NewTable("Verification Result")
+	resultTable.SetHeader([]string{
+		"Simulation",
+		"Path",
+		"Result",
+		"Reason",
+		"Local Size",
+		"Released Size",
+		"Checksum Algorithm",
+		"Local Checksum",
+		"Released Checksum",
+		"Repair",
+	})
+
+	summaryTable := outputFormatter.NewTable("Verification Summary")
+	summaryTable.SetHeader([]string{
+		"Simulation",
+		"Files",
+		"Missing",
+		"Corrupt",
+		"Extra",
+		"Unverified",
+		"Repair",
+	})
+
+	damagedSimulationPaths := []string{}
+	damagedFiles := 0
+
+	for _, irodsTicket := range ticketGroupOrder {
+		group := ticketGroups[irodsTicket]
+
+		results, err := verify.processTicketGroup(group)
+		if err != nil {
+			return err
+		}
+
+		for _, result := range results {
+			counts := map[mdrepo.DownloadVerifyResult]int{}
+			damaged := 0
+
+			for _, entry := range result.Entries {
+				counts[entry.Result]++
+
+				if entry.Result.IsDamaged() && !result.Repaired {
+					damaged++
+				}
+
+				localSize := ""
+				if len(entry.LocalPath) > 0 {
+					localSize = fmt.Sprintf("%d", entry.LocalSize)
+				}
+
+				irodsSize := ""
+				if len(entry.IRODSPath) > 0 {
+					irodsSize = fmt.Sprintf("%d", entry.IRODSSize)
+				}
+
+				repair := ""
+				if entry.Result.IsDamaged() && verify.verifyFlagValues.Repair {
+					repair = "downloaded"
+				}
+
+				resultTable.AppendRow([]interface{}{
+					result.Simulation,
+					entry.Path,
+					string(entry.Result),
+					entry.Reason,
+					localSize,
+					irodsSize,
+					entry.ChecksumAlgorithm,
+					entry.LocalChecksum,
+					entry.IRODSChecksum,
+					repair,
+				})
+			}
+
+			repair := ""
+			if result.Repaired {
+				repair = fmt.Sprintf("%d downloaded", counts[mdrepo.DownloadVerifyResultMissing]+counts[mdrepo.DownloadVerifyResultCorrupt])
+			}
+
+			summaryTable.AppendRow([]interface{}{
+				result.Simulation,
+				result.FileNumber,
+				counts[mdrepo.DownloadVerifyResultMissing],
+				counts[mdrepo.DownloadVerifyResultCorrupt],
+				counts[mdrepo.DownloadVerifyResultExtra],
+				counts[mdrepo.DownloadVerifyResultUnverified],
+				repair,
+			})
+
+			if damaged > 0 {
+				damagedSimulationPaths = append(damagedSimulationPaths, result.LocalPath)
+				damagedFiles += damaged
+			}
+		}
+	}
+
+	outputFormatter.Render(verify.outputFormatFlagValues.Format)
+
+	if damagedFiles > 0 {
+		return types.NewDamagedDownloadError(damagedSimulationPaths, damagedFiles)
+	}
+
+	return nil
+}
+
+// verifyResult is a result of verifying a downloaded simulation
+type verifyResult struct {
+	Simulation string
+	LocalPath  string
+	FileNumber int // released files
+	Entries    []mdrepo.DownloadVerifyEntry
+	Repaired   bool
+}
+
+func (verify *VerifyCommand) processTicketGroup(mdRepoTickets []mdrepo.MDRepoTicket) ([]*verifyResult, error) {
+	if len(mdRepoTickets) == 0 {
+		return nil, nil
+	}
+
+	// all tickets in the group share the same IRODSTicket, so they use the same account
+	account, err := mdRepoTickets[0].GetAccount()
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get iRODS Account")
+	}
+
+	verify.account = account
+
+	if verify.get != nil {
+		verify.filesystem, err = irods.GetIRODSFSClientForLargeFileIO(verify.account, verify.get.maxConnectionNum, verify.get.parallelTransferFlagValues.TCPBufferSize, true, verify.commonFlagValues.Timeout)
+	} else {
+		verify.filesystem, err = irods.GetIRODSFSClient(verify.account, true, verify.commonFlagValues.Timeout)
+	}
+
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get iRODS FS Client")
+	}
+	defer func() {
+		verify.filesystem.Release()
+		verify.filesystem = nil
+	}()
+
+	if verify.get != nil {
+		verify.get.account = verify.account
+		verify.get.filesystem = verify.filesystem
+
+		ioSession := verify.filesystem.GetIOSession()
+		verify.get.parallelTransferJobManager = parallel.NewParallelJobManager(ioSession.GetMaxConnections(), !verify.get.progressFlagValues.NoProgress, verify.get.progressFlagValues.ShowFullPath, verify.get.parallelTransferFlagValues.StopOnError)
+
+		defer func() {
+			verify.get.filesystem = nil
+			verify.get.parallelTransferJobManager = nil
+		}()
+	}
+
+	results := []*verifyResult{}
+	repairScheduled := false
+
+	for i := range mdRepoTickets {
+		mdRepoTicket := &mdRepoTickets[i]
+
+		result, scheduled, err := verify.verifyOne(mdRepoTicket)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to verify %q", mdRepoTicket.IRODSDataPath)
+		}
+
+		results = append(results, result)
+		repairScheduled = repairScheduled || scheduled
+	}
+
+	if repairScheduled {
+		terminal.Printf("start transfer to repair...\n")
+
+		transferErr := verify.get.parallelTransferJobManager.Start()
+		if transferErr != nil {
+			return nil, errors.Wrap(transferErr, "failed to perform transfer jobs")
+		}
+
+		for _, result := range results {
+			for _, entry := range result.Entries {
+				if entry.Result.IsDamaged() {
+					result.Repaired = true
+					break
+				}
+			}
+		}
+
+		terminal.Printf("done transfer...\n")
+	}
+
+	return results, nil
+}
+
+// verifyOne verifies a downloaded simulation, returns true if files are scheduled to repair
+func (verify *VerifyCommand) verifyOne(mdRepoTicket *mdrepo.MDRepoTicket) (*verifyResult, bool, error) {
+	logger := log.WithFields(log.Fields{
+		"irods_data_path": mdRepoTicket.IRODSDataPath,
+		"irods_ticket":    mdRepoTicket.IRODSTicket,
+	})
+
+	dataRelPath, err := mdrepo.GetMDRepoSimulationRelPath(mdRepoTicket.IRODSDataPath)
+	if err != nil {
+		return nil, false, errors.Wrapf(err, "failed to extract data path from %q", mdRepoTicket.IRODSDataPath)
+	}
+
+	// same layout as get
+	sourcePath := commons_path.MakeIRODSReleasePath(mdRepoTicket.IRODSDataPath)
+	targetPath := filepath.Join(verify.targetPath, filepath.FromSlash(dataRelPath))
+
+	logger.Debugf("verify %q against %q (ticket: %q)", targetPath, sourcePath, mdRepoTicket.IRODSTicket)
+
+	sourceEntry, err := verify.filesystem.Stat(sourcePath)
+	if err != nil {
+		return nil, false, errors.Wrapf(err, "failed to stat %q", sourcePath)
+	}
+
+	remoteEntries := map[string]*irodsclient_fs.Entry{}
+	remoteFiles := []mdrepo.DownloadVerifyRemoteFile{}
+
+	if sourceEntry.IsDir() {
+		remoteFiles, err = verify.listReleasedFiles(sourceEntry.Path, sourceEntry.Path, remoteEntries)
+		if err != nil {
+			return nil, false, err
+		}
+	} else {
+		remoteEntries[sourceEntry.Name] = sourceEntry
+		remoteFiles = append(remoteFiles, makeDownloadVerifyRemoteFile(sourceEntry.Name, sourceEntry))
+	}
+
+	localFiles := []mdrepo.DownloadVerifyLocalFile{}
+	if _, statErr := os.Stat(targetPath); statErr == nil {
+		localFiles, err = mdrepo.FindDownloadedFiles(targetPath)
+		if err != nil {
+			return nil, false, err
+		}
+	}
+
+	// always read file contents, cached hashes cannot detect corruption that keeps size and modification time
+	// fresh hashes are stored to the cache, so repairing does not skip corrupt files
+	hashFunc := func(localPath string, algorithm string) ([]byte, error) {
+		return verify.hashCache.RehashLocalFile(localPath, algorithm, nil)
+	}
+
+	entries, err := mdrepo.VerifyDownloadedFiles(localFiles, remoteFiles, hashFunc)
+	if err != nil {
+		return nil, false, err
+	}
+
+	result := &verifyResult{
+		Simulation: dataRelPath,
+		LocalPath:  targetPath,
+		FileNumber: len(remoteFiles),
+		Entries:    entries,
+	}
+
+	if verify.get == nil {
+		return result, false, nil
+	}
+
+	scheduled := false
+	for _, entry := range entries {
+		if !entry.Result.IsDamaged() {
+			continue
+		}
+
+		err = verify.scheduleRepair(mdRepoTicket, remoteEntries[entry.Path], entry, targetPath)
+		if err != nil {
+			return nil, false, err
+		}
+
+		scheduled = true
+	}
+
+	return result, scheduled, nil
+}
+
+// scheduleRepair schedules downloading a missing or corrupt file again
+func (verify *VerifyCommand) scheduleRepair(mdRepoTicket *mdrepo.MDRepoTicket, sourceEntry *irodsclient_fs.Entry, entry mdrepo.DownloadVerifyEntry, targetDirPath string) error {
+	targetPath := filepath.Join(targetDirPath, filepath.FromSlash(entry.Path))
+
+	targetParentPath := filepath.Dir(targetPath)
+	err := os.MkdirAll(targetParentPath, 0766)
+	if err != nil {
+		return errors.Wrapf(err, "failed to make a directory %q", targetParentPath)
+	}
+
+	// incomplete downloads are resumed, other corrupt files are downloaded from scratch
+	if entry.Result == mdrepo.DownloadVerifyResultCorrupt && !verify.get.hasTransferStatusFile(targetPath) {
+		err = os.Remove(targetPath)
+		if err != nil && !os.IsNotExist(err) {
+			return errors.Wrapf(err, "failed to remove corrupt file %q", targetPath)
+		}
+	}
+
+	terminal.Printf("download %s file %q again\n", entry.Result, targetPath)
+
+	verify.get.scheduleGet(mdRepoTicket, sourceEntry, "", targetPath)
+	return nil
+}
+
+// listReleasedFiles lists data objects under the release collection recursively
+func (verify *VerifyCommand) listReleasedFiles(releasePath string, collectionPath string, remoteEntries map[string]*irodsclient_fs.Entry) ([]mdrepo.DownloadVerifyRemoteFile, error) {
+	entries, err := verify.filesystem.List(collectionPath)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list %q", collectionPath)
+	}
+
+	remoteFiles := []mdrepo.DownloadVerifyRemoteFile{}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			subRemoteFiles, err := verify.listReleasedFiles(releasePath, entry.Path, remoteEntries)
+			if err != nil {
+				return nil, err
+			}
+
+			remoteFiles = append(remoteFiles, subRemoteFiles...)
+			continue
+		}
+
+		if irodsclient_irodsfs.IsDataObjectTransferStatusFile(entry.Name) {
+			continue
+		}
+
+		relPath := commons_path.GetIRODSRelativePath(releasePath, entry.Path)
+		remoteEntries[relPath] = entry
+		remoteFiles = append(remoteFiles, makeDownloadVerifyRemoteFile(relPath, entry))
+	}
+
+	return remoteFiles, nil
+}
+
+func makeDownloadVerifyRemoteFile(relPath string, entry *irodsclient_fs.Entry) mdrepo.DownloadVerifyRemoteFile {
+	return mdrepo.DownloadVerifyRemoteFile{
+		Path:              relPath,
+		IRODSPath:         entry.Path,
+		Size:              entry.Size,
+		ChecksumAlgorithm: string(entry.CheckSumAlgorithm),
+		Checksum:          entry.CheckSum,
+	}
+}
@@ -0,0 +1,629 @@
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/hex"
+	"encoding/json"
+	"hash"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/MD-Repo/md-repo-cli/commons/transfer"
+	"github.com/MD-Repo/md-repo-cli/commons/types"
+	"github.com/cockroachdb/errors"
+	irodsclient_types "github.com/cyverse/go-irodsclient/irods/types"
+	"github.com/klauspost/compress/zstd"
+	log "github.com/sirupsen/logrus"
+)
+
+// ArchiveFormat is a format of archive files
+type ArchiveFormat string
+
+const (
+	ArchiveFormatTar     ArchiveFormat = "tar"
+	ArchiveFormatTarGzip ArchiveFormat = "tar.gz"
+	ArchiveFormatTarZstd ArchiveFormat = "tar.zst"
+	ArchiveFormatZip     ArchiveFormat = "zip"
+)
+
+const (
+	// ArchiveIndexSuffix is appended to the archive path to make the index path
+	ArchiveIndexSuffix string = ".mdrepo-index.jsonl"
+)
+
+// GetArchiveFormat returns the archive format from the extension of the archive path
+func GetArchiveFormat(archivePath string) (ArchiveFormat, error) {
+	lowerPath := strings.ToLower(archivePath)
+
+	switch {
+	case strings.HasSuffix(lowerPath, ".tar"):
+		return ArchiveFormatTar, nil
+	case strings.HasSuffix(lowerPath, ".tar.gz"), strings.HasSuffix(lowerPath, ".tgz"):
+		return ArchiveFormatTarGzip, nil
+	case strings.HasSuffix(lowerPath, ".tar.zst"), strings.HasSuffix(lowerPath, ".tzst"):
+		return ArchiveFormatTarZstd, nil
+	case strings.HasSuffix(lowerPath, ".zip"):
+		return ArchiveFormatZip, nil
+	default:
+		return "", errors.Errorf("unknown archive format of %q, use .tar, .tar.gz, .tar.zst or .zip", archivePath)
+	}
+}
+
+// ArchiveIndexEntry is an entry completely written to the archive
+type ArchiveIndexEntry struct {
+	Name              string `json:"name"`
+	Size              int64  `json:"size"`
+	ChecksumAlgorithm string `json:"checksum_algorithm,omitempty"`
+	Checksum          string `json:"checksum,omitempty"` // hex string
+	EndOffset         int64  `json:"end_offset"`         // offset in the archive file after the entry, tar only
+}
+
+// ArchiveIndex is a set of entries completely written to the archive
+type ArchiveIndex struct {
+	entries map[string]*ArchiveIndexEntry
+}
+
+// ReadArchiveIndex reads the index of the archive without modifying the archive, for dry-run
+func ReadArchiveIndex(archivePath string) *ArchiveIndex {
+	index := &ArchiveIndex{
+		entries: map[string]*ArchiveIndexEntry{},
+	}
+
+	for _, entry := range readIndexFile(archivePath) {
+		index.entries[entry.Name] = entry
+	}
+
+	return index
+}
+
+// HasEntry returns true if the entry with the same size and checksum is in the archive
+func (index *ArchiveIndex) HasEntry(name string, size int64, checksumAlgorithm string, checksum []byte) bool {
+	entry, ok := index.entries[name]
+	if !ok {
+		return false
+	}
+
+	if entry.Size != size {
+		return false
+	}
+
+	if len(checksum) > 0 {
+		return strings.EqualFold(entry.ChecksumAlgorithm, checksumAlgorithm) && entry.Checksum == hex.EncodeToString(checksum)
+	}
+
+	return true
+}
+
+// ArchiveWriter writes data objects to an archive file, an entry at a time
+// entries written are recorded in an index file next to the archive, to resume at entry granularity
+type ArchiveWriter struct {
+	path      string
+	indexPath string
+	format    ArchiveFormat
+
+	file      *os.File
+	indexFile *os.File
+	zipWriter *zip.Writer
+
+	index *ArchiveIndex
+	mutex sync.Mutex
+}
+
+// NewArchiveWriter creates a new ArchiveWriter, entries written before are kept if the index file exists
+// an existing archive is truncated with its index if overwrite is true, otherwise only an archive with the index file is resumed
+func NewArchiveWriter(archivePath string, overwrite bool) (*ArchiveWriter, error) {
+	format, err := GetArchiveFormat(archivePath)
+	if err != nil {
+		return nil, err
+	}
+
+	if !overwrite {
+		_, archiveErr := os.Stat(archivePath)
+		_, indexErr := os.Stat(archivePath + ArchiveIndexSuffix)
+		if archiveErr == nil && indexErr != nil {
+			return nil, irodsclient_types.NewFileAlreadyExistError(archivePath)
+		}
+	}
+
+	writer := &ArchiveWriter{
+		path:      archivePath,
+		indexPath: archivePath + ArchiveIndexSuffix,
+		format:    format,
+		index: &ArchiveIndex{
+			entries: map[string]*ArchiveIndexEntry{},
+		},
+	}
+
+	previousEntries := []*ArchiveIndexEntry{}
+	if overwrite {
+		err = os.Remove(writer.indexPath)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, errors.Wrapf(err, "failed to remove index %q", writer.indexPath)
+		}
+	} else {
+		previousEntries = readIndexFile(archivePath)
+	}
+
+	switch format {
+	case ArchiveFormatTar, ArchiveFormatTarGzip, ArchiveFormatTarZstd:
+		err = writer.openTar(previousEntries)
+	case ArchiveFormatZip:
+		err = writer.openZip(previousEntries)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return writer, nil
+}
+
+// GetPath returns the archive path
+func (writer *ArchiveWriter) GetPath() string {
+	return writer.path
+}
+
+// GetFormat returns the archive format
+func (writer *ArchiveWriter) GetFormat() ArchiveFormat {
+	return writer.format
+}
+
+// readIndexFile returns entries in the index file of the archive, broken lines written by interrupted runs are ignored
+func readIndexFile(archivePath string) []*ArchiveIndexEntry {
+	indexPath := archivePath + ArchiveIndexSuffix
+
+	logger := log.WithFields(log.Fields{
+		"index_path": indexPath,
+	})
+
+	entries := []*ArchiveIndexEntry{}
+
+	if _, err := os.Stat(archivePath); err != nil {
+		// no archive, nothing to resume
+		return entries
+	}
+
+	indexBytes, err := os.ReadFile(indexPath)
+	if err != nil {
+		return entries
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(indexBytes))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		entry := ArchiveIndexEntry{}
+		err = json.Unmarshal(scanner.Bytes(), &entry)
+		if err != nil {
+			logger.WithError(err).Debug("ignore broken index entry")
+			break
+		}
+
+		entries = append(entries, &entry)
+	}
+
+	return entries
+}
+
+func (writer *ArchiveWriter) openIndex(entries []*ArchiveIndexEntry) error {
+	indexFile, err := os.OpenFile(writer.indexPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create index %q", writer.indexPath)
+	}
+
+	writer.indexFile = indexFile
+
+	for _, entry := range entries {
+		err = writer.addIndexEntry(entry)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (writer *ArchiveWriter) addIndexEntry(entry *ArchiveIndexEntry) error {
+	entryBytes, err := json.Marshal(entry)
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal index entry %q", entry.Name)
+	}
+
+	_, err = writer.indexFile.Write(append(entryBytes, '\n'))
+	if err != nil {
+		return errors.Wrapf(err, "failed to write index %q", writer.indexPath)
+	}
+
+	writer.index.entries[entry.Name] = entry
+	return nil
+}
+
+// openTar opens the tar archive and truncates it after the last entry completely written
+func (writer *ArchiveWriter) openTar(previousEntries []*ArchiveIndexEntry) error {
+	file, err := os.OpenFile(writer.path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return errors.Wrapf(err, "failed to open archive %q", writer.path)
+	}
+
+	writer.file = file
+
+	st, err := file.Stat()
+	if err != nil {
+		return errors.Wrapf(err, "failed to stat archive %q", writer.path)
+	}
+
+	validEntries := []*ArchiveIndexEntry{}
+	endOffset := int64(0)
+	for _, entry := range previousEntries {
+		if entry.EndOffset < endOffset || entry.EndOffset > st.Size() {
+			break
+		}
+
+		validEntries = append(validEntries, entry)
+		endOffset = entry.EndOffset
+	}
+
+	// removes the trailer and a partial entry
+	err = file.Truncate(endOffset)
+	if err != nil {
+		return errors.Wrapf(err, "failed to truncate archive %q", writer.path)
+	}
+
+	_, err = file.Seek(endOffset, io.SeekStart)
+	if err != nil {
+		return errors.Wrapf(err, "failed to seek archive %q", writer.path)
+	}
+
+	return writer.openIndex(validEntries)
+}
+
+// openZip opens the zip archive and copies entries completely written from the existing archive
+// the existing archive is rewritten from scratch if it has no central directory
+func (writer *ArchiveWriter) openZip(previousEntries []*ArchiveIndexEntry) error {
+	logger := log.WithFields(log.Fields{
+		"path": writer.path,
+	})
+
+	var previousReader *zip.ReadCloser
+	previousPath := writer.path + ".previous"
+
+	if len(previousEntries) > 0 {
+		err := os.Rename(writer.path, previousPath)
+		if err != nil {
+			return errors.Wrapf(err, "failed to rename archive %q", writer.path)
+		}
+
+		previousReader, err = zip.OpenReader(previousPath)
+		if err != nil {
+			logger.WithError(err).Warn("failed to read the existing archive, writing from scratch")
+			previousReader = nil
+		}
+	}
+
+	defer func() {
+		if previousReader != nil {
+			previousReader.Close()
+		}
+	}()
+
+	file, err := os.OpenFile(writer.path, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create archive %q", writer.path)
+	}
+
+	writer.file = file
+	writer.zipWriter = zip.NewWriter(file)
+
+	validEntries := []*ArchiveIndexEntry{}
+	if previousReader != nil {
+		previousEntryMap := map[string]*ArchiveIndexEntry{}
+		for _, entry := range previousEntries {
+			previousEntryMap[entry.Name] = entry
+		}
+
+		for _, previousFile := range previousReader.File {
+			entry, ok := previousEntryMap[previousFile.Name]
+			if !ok || int64(previousFile.UncompressedSize64) != entry.Size {
+				continue
+			}
+
+			err = writer.zipWriter.Copy(previousFile)
+			if err != nil {
+				return errors.Wrapf(err, "failed to copy entry %q from the existing archive", previousFile.Name)
+			}
+
+			validEntries = append(validEntries, entry)
+		}
+	}
+
+	err = writer.openIndex(validEntries)
+	if err != nil {
+		return err
+	}
+
+	os.Remove(previousPath)
+	return nil
+}
+
+// HasEntry returns true if the entry with the same size and checksum is in the archive
+func (writer *ArchiveWriter) HasEntry(name string, size int64, checksumAlgorithm string, checksum []byte) bool {
+	writer.mutex.Lock()
+	defer writer.mutex.Unlock()
+
+	return writer.index.HasEntry(name, size, checksumAlgorithm, checksum)
+}
+
+// WriteEntry writes data read from reader to the archive as an entry
+// data is verified with the checksum if given, an entry not matching is not left in the archive
+func (writer *ArchiveWriter) WriteEntry(name string, size int64, modTime time.Time, reader io.Reader, checksumAlgorithm string, checksum []byte) error {
+	writer.mutex.Lock()
+	defer writer.mutex.Unlock()
+
+	if writer.file == nil {
+		return errors.Errorf("archive %q is closed", writer.path)
+	}
+
+	dataReader := reader
+	var checksumHash hash.Hash
+
+	if len(checksum) > 0 {
+		newHash, err := transfer.NewChecksumHash(checksumAlgorithm)
+		if err != nil {
+			return err
+		}
+
+		checksumHash = newHash
+		dataReader = io.TeeReader(reader, checksumHash)
+	}
+
+	verify := func() error {
+		return writer.verifyChecksum(name, checksumAlgorithm, checksum, checksumHash)
+	}
+
+	var endOffset int64
+	var err error
+
+	switch writer.format {
+	case ArchiveFormatTar, ArchiveFormatTarGzip, ArchiveFormatTarZstd:
+		endOffset, err = writer.writeTarEntry(name, size, modTime, dataReader, verify)
+	case ArchiveFormatZip:
+		err = writer.writeZipEntry(name, size, modTime, dataReader, verify)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	entry := &ArchiveIndexEntry{
+		Name:      name,
+		Size:      size,
+		EndOffset: endOffset,
+	}
+
+	if len(checksum) > 0 {
+		entry.ChecksumAlgorithm = checksumAlgorithm
+		entry.Checksum = hex.EncodeToString(checksum)
+	}
+
+	return writer.addIndexEntry(entry)
+}
+
+func (writer *ArchiveWriter) verifyChecksum(name string, checksumAlgorithm string, checksum []byte, checksumHash hash.Hash) error {
+	if checksumHash == nil {
+		return nil
+	}
+
+	actualChecksum := checksumHash.Sum(nil)
+	if !bytes.Equal(actualChecksum, checksum) {
+		return types.NewChecksumMismatchError(name, checksumAlgorithm, hex.EncodeToString(checksum), hex.EncodeToString(actualChecksum))
+	}
+
+	return nil
+}
+
+// newTarCompressor returns a writer compressing data written to the archive file, nil for tar
+// each entry is compressed in a separate gzip member or zstd frame, so the archive can be truncated at entry boundaries
+func (writer *ArchiveWriter) newTarCompressor() (io.WriteCloser, error) {
+	switch writer.format {
+	case ArchiveFormatTarGzip:
+		return gzip.NewWriter(writer.file), nil
+	case ArchiveFormatTarZstd:
+		zstdWriter, err := zstd.NewWriter(writer.file, zstd.WithEncoderConcurrency(1))
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to create zstd writer for archive %q", writer.path)
+		}
+		return zstdWriter, nil
+	default:
+		return nil, nil
+	}
+}
+
+// writeTarEntry writes a tar entry, a gzip member or a zstd frame for each entry in tar.gz or tar.zst to truncate at entry boundaries
+// the entry is removed if writing or verification fails
+func (writer *ArchiveWriter) writeTarEntry(name string, size int64, modTime time.Time, reader io.Reader, verify func() error) (int64, error) {
+	startOffset, err := writer.file.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to get offset of archive %q", writer.path)
+	}
+
+	writeErr := func() error {
+		var entryWriter io.Writer = writer.file
+		compressor, err := writer.newTarCompressor()
+		if err != nil {
+			return err
+		}
+
+		if compressor != nil {
+			entryWriter = compressor
+		}
+
+		tarWriter := tar.NewWriter(entryWriter)
+		err = tarWriter.WriteHeader(&tar.Header{
+			Typeflag: tar.TypeReg,
+			Name:     name,
+			Size:     size,
+			Mode:     0644,
+			ModTime:  modTime,
+			Format:   tar.FormatPAX,
+		})
+		if err != nil {
+			return errors.Wrapf(err, "failed to write header of %q", name)
+		}
+
+		written, err := io.Copy(tarWriter, reader)
+		if err != nil {
+			return errors.Wrapf(err, "failed to write %q", name)
+		}
+
+		if written != size {
+			return errors.Errorf("failed to write %q, %d bytes written, expected %d bytes", name, written, size)
+		}
+
+		// pads the entry, does not write the trailer
+		err = tarWriter.Flush()
+		if err != nil {
+			return errors.Wrapf(err, "failed to write %q", name)
+		}
+
+		if compressor != nil {
+			err = compressor.Close()
+			if err != nil {
+				return errors.Wrapf(err, "failed to compress %q", name)
+			}
+		}
+
+		return verify()
+	}()
+
+	if writeErr != nil {
+		// remove the partial entry
+		truncateErr := writer.file.Truncate(startOffset)
+		if truncateErr == nil {
+			_, truncateErr = writer.file.Seek(startOffset, io.SeekStart)
+		}
+
+		if truncateErr != nil {
+			return 0, errors.Join(writeErr, errors.Wrapf(truncateErr, "failed to truncate archive %q", writer.path))
+		}
+
+		return 0, writeErr
+	}
+
+	endOffset, err := writer.file.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to get offset of archive %q", writer.path)
+	}
+
+	return endOffset, nil
+}
+
+// writeZipEntry writes a zip entry, data is stored without compression as trajectories rarely compress well
+// a zip entry can not be removed once written, so data is spooled to a temp file next to the archive and verified first
+func (writer *ArchiveWriter) writeZipEntry(name string, size int64, modTime time.Time, reader io.Reader, verify func() error) error {
+	spoolFile, err := os.CreateTemp(filepath.Dir(writer.path), filepath.Base(writer.path)+".entry-*")
+	if err != nil {
+		return errors.Wrapf(err, "failed to create a temp file for %q", name)
+	}
+
+	defer func() {
+		spoolFile.Close()
+		os.Remove(spoolFile.Name())
+	}()
+
+	written, err := io.Copy(spoolFile, reader)
+	if err != nil {
+		return errors.Wrapf(err, "failed to write %q", name)
+	}
+
+	if written != size {
+		return errors.Errorf("failed to write %q, %d bytes written, expected %d bytes", name, written, size)
+	}
+
+	err = verify()
+	if err != nil {
+		return err
+	}
+
+	_, err = spoolFile.Seek(0, io.SeekStart)
+	if err != nil {
+		return errors.Wrapf(err, "failed to seek a temp file for %q", name)
+	}
+
+	entryWriter, err := writer.zipWriter.CreateHeader(&zip.FileHeader{
+		Name:               name,
+		Method:             zip.Store,
+		Modified:           modTime,
+		UncompressedSize64: uint64(size),
+	})
+	if err != nil {
+		return errors.Wrapf(err, "failed to write header of %q", name)
+	}
+
+	_, err = io.Copy(entryWriter, spoolFile)
+	if err != nil {
+		return errors.Wrapf(err, "failed to write %q", name)
+	}
+
+	return nil
+}
+
+// writeTarTrailer writes the end of the tar archive, compressed separately like entries
+func (writer *ArchiveWriter) writeTarTrailer() error {
+	compressor, err := writer.newTarCompressor()
+	if err != nil {
+		return err
+	}
+
+	if compressor == nil {
+		return tar.NewWriter(writer.file).Close()
+	}
+
+	err = tar.NewWriter(compressor).Close()
+	if err != nil {
+		return err
+	}
+
+	return compressor.Close()
+}
+
+// Close writes the end of the archive, entries written are readable even if some entries failed
+func (writer *ArchiveWriter) Close() error {
+	writer.mutex.Lock()
+	defer writer.mutex.Unlock()
+
+	if writer.file == nil {
+		return nil
+	}
+
+	var err error
+	switch writer.format {
+	case ArchiveFormatTar, ArchiveFormatTarGzip, ArchiveFormatTarZstd:
+		err = writer.writeTarTrailer()
+	case ArchiveFormatZip:
+		err = writer.zipWriter.Close()
+	}
+
+	if err != nil {
+		return errors.Wrapf(err, "failed to write the end of archive %q", writer.path)
+	}
+
+	err = writer.file.Close()
+	writer.file = nil
+	if err != nil {
+		return errors.Wrapf(err, "failed to close archive %q", writer.path)
+	}
+
+	err = writer.indexFile.Close()
+	if err != nil {
+		return errors.Wrapf(err, "failed to close index %q", writer.indexPath)
+	}
+
+	return nil
+}
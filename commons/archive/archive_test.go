@@ -0,0 +1,324 @@
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"crypto/md5"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MD-Repo/md-repo-cli/commons/types"
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestArchive(t *testing.T) {
+	t.Run("test GetArchiveFormat", testGetArchiveFormat)
+	t.Run("test WriteTar", testWriteTar)
+	t.Run("test WriteZip", testWriteZip)
+	t.Run("test ResumeTar", testResumeTar)
+	t.Run("test ResumeZip", testResumeZip)
+	t.Run("test ChecksumMismatch", testChecksumMismatch)
+	t.Run("test Overwrite", testOverwrite)
+	t.Run("test ExistingArchive", testExistingArchive)
+}
+
+func md5Of(content string) []byte {
+	hash := md5.Sum([]byte(content))
+	return hash[:]
+}
+
+func writeTestEntry(t *testing.T, writer *ArchiveWriter, name string, content string) {
+	err := writer.WriteEntry(name, int64(len(content)), time.Now(), strings.NewReader(content), "MD5", md5Of(content))
+	assert.NoError(t, err)
+}
+
+func readTarEntries(t *testing.T, archivePath string) map[string]string {
+	file, err := os.Open(archivePath)
+	assert.NoError(t, err)
+	defer file.Close()
+
+	var reader io.Reader = file
+	switch {
+	case strings.HasSuffix(archivePath, ".gz"):
+		gzipReader, err := gzip.NewReader(file)
+		assert.NoError(t, err)
+		reader = gzipReader
+	case strings.HasSuffix(archivePath, ".zst"):
+		zstdReader, err := zstd.NewReader(file)
+		assert.NoError(t, err)
+		defer zstdReader.Close()
+		reader = zstdReader
+	}
+
+	entries := map[string]string{}
+	tarReader := tar.NewReader(reader)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+		assert.NoError(t, err)
+
+		content, err := io.ReadAll(tarReader)
+		assert.NoError(t, err)
+		entries[header.Name] = string(content)
+	}
+
+	return entries
+}
+
+func readZipEntries(t *testing.T, archivePath string) map[string]string {
+	reader, err := zip.OpenReader(archivePath)
+	assert.NoError(t, err)
+	defer reader.Close()
+
+	entries := map[string]string{}
+	for _, file := range reader.File {
+		fileReader, err := file.Open()
+		assert.NoError(t, err)
+
+		content, err := io.ReadAll(fileReader)
+		assert.NoError(t, err)
+		fileReader.Close()
+
+		entries[file.Name] = string(content)
+	}
+
+	return entries
+}
+
+func testGetArchiveFormat(t *testing.T) {
+	format, err := GetArchiveFormat("out.tar")
+	assert.NoError(t, err)
+	assert.Equal(t, ArchiveFormatTar, format)
+
+	format, err = GetArchiveFormat("OUT.TGZ")
+	assert.NoError(t, err)
+	assert.Equal(t, ArchiveFormatTarGzip, format)
+
+	format, err = GetArchiveFormat("out.zip")
+	assert.NoError(t, err)
+	assert.Equal(t, ArchiveFormatZip, format)
+
+	format, err = GetArchiveFormat("out.tar.zst")
+	assert.NoError(t, err)
+	assert.Equal(t, ArchiveFormatTarZstd, format)
+
+	format, err = GetArchiveFormat("out.tzst")
+	assert.NoError(t, err)
+	assert.Equal(t, ArchiveFormatTarZstd, format)
+
+	_, err = GetArchiveFormat("out.zst")
+	assert.Error(t, err)
+
+	_, err = GetArchiveFormat("out.rar")
+	assert.Error(t, err)
+}
+
+func testWriteTar(t *testing.T) {
+	for _, name := range []string{"out.tar", "out.tar.gz", "out.tar.zst"} {
+		archivePath := filepath.Join(t.TempDir(), name)
+
+		writer, err := NewArchiveWriter(archivePath, false)
+		assert.NoError(t, err)
+
+		writeTestEntry(t, writer, "MDR00000001/top.pdb", "structure")
+		writeTestEntry(t, writer, "MDR00000001/inputs/md.mdp", "input")
+		assert.NoError(t, writer.Close())
+
+		entries := readTarEntries(t, archivePath)
+		assert.Equal(t, map[string]string{
+			"MDR00000001/top.pdb":       "structure",
+			"MDR00000001/inputs/md.mdp": "input",
+		}, entries)
+	}
+}
+
+func testWriteZip(t *testing.T) {
+	archivePath := filepath.Join(t.TempDir(), "out.zip")
+
+	writer, err := NewArchiveWriter(archivePath, false)
+	assert.NoError(t, err)
+
+	writeTestEntry(t, writer, "MDR00000001/top.pdb", "structure")
+	writeTestEntry(t, writer, "MDR00000001/run.xtc", "trajectory")
+	assert.NoError(t, writer.Close())
+
+	entries := readZipEntries(t, archivePath)
+	assert.Equal(t, map[string]string{
+		"MDR00000001/top.pdb": "structure",
+		"MDR00000001/run.xtc": "trajectory",
+	}, entries)
+}
+
+func testResumeTar(t *testing.T) {
+	for _, name := range []string{"out.tar", "out.tar.gz", "out.tar.zst"} {
+		archivePath := filepath.Join(t.TempDir(), name)
+
+		writer, err := NewArchiveWriter(archivePath, false)
+		assert.NoError(t, err)
+		writeTestEntry(t, writer, "MDR00000001/top.pdb", "structure")
+
+		// interrupted while writing an entry, without the end of archive
+		_, err = writer.file.Write([]byte("partial entry"))
+		assert.NoError(t, err)
+		writer.file.Close()
+		writer.indexFile.Close()
+
+		assert.True(t, ReadArchiveIndex(archivePath).HasEntry("MDR00000001/top.pdb", 9, "MD5", md5Of("structure")))
+
+		writer, err = NewArchiveWriter(archivePath, false)
+		assert.NoError(t, err)
+		assert.True(t, writer.HasEntry("MDR00000001/top.pdb", 9, "MD5", md5Of("structure")))
+		assert.False(t, writer.HasEntry("MDR00000001/top.pdb", 9, "MD5", md5Of("other")))
+		assert.False(t, writer.HasEntry("MDR00000001/run.xtc", 10, "MD5", md5Of("trajectory")))
+
+		writeTestEntry(t, writer, "MDR00000001/run.xtc", "trajectory")
+		assert.NoError(t, writer.Close())
+
+		// resume a complete archive
+		writer, err = NewArchiveWriter(archivePath, false)
+		assert.NoError(t, err)
+		writeTestEntry(t, writer, "MDR00000002/top.pdb", "structure2")
+		assert.NoError(t, writer.Close())
+
+		entries := readTarEntries(t, archivePath)
+		assert.Equal(t, map[string]string{
+			"MDR00000001/top.pdb": "structure",
+			"MDR00000001/run.xtc": "trajectory",
+			"MDR00000002/top.pdb": "structure2",
+		}, entries)
+	}
+}
+
+func testResumeZip(t *testing.T) {
+	archivePath := filepath.Join(t.TempDir(), "out.zip")
+
+	writer, err := NewArchiveWriter(archivePath, false)
+	assert.NoError(t, err)
+	writeTestEntry(t, writer, "MDR00000001/top.pdb", "structure")
+	assert.NoError(t, writer.Close())
+
+	writer, err = NewArchiveWriter(archivePath, false)
+	assert.NoError(t, err)
+	assert.True(t, writer.HasEntry("MDR00000001/top.pdb", 9, "MD5", md5Of("structure")))
+
+	writeTestEntry(t, writer, "MDR00000001/run.xtc", "trajectory")
+	assert.NoError(t, writer.Close())
+
+	entries := readZipEntries(t, archivePath)
+	assert.Equal(t, map[string]string{
+		"MDR00000001/top.pdb": "structure",
+		"MDR00000001/run.xtc": "trajectory",
+	}, entries)
+
+	_, err = os.Stat(archivePath + ".previous")
+	assert.True(t, os.IsNotExist(err))
+}
+
+func testChecksumMismatch(t *testing.T) {
+	for _, name := range []string{"out.tar.gz", "out.zip"} {
+		dirPath := t.TempDir()
+		archivePath := filepath.Join(dirPath, name)
+
+		writer, err := NewArchiveWriter(archivePath, false)
+		assert.NoError(t, err)
+		writeTestEntry(t, writer, "MDR00000001/top.pdb", "structure")
+
+		err = writer.WriteEntry("MDR00000001/run.xtc", 7, time.Now(), strings.NewReader("corrupt"), "MD5", md5Of("trajectory"))
+		assert.Error(t, err)
+		assert.True(t, types.IsChecksumMismatchError(err))
+		assert.False(t, writer.HasEntry("MDR00000001/run.xtc", 7, "MD5", md5Of("trajectory")))
+
+		// short read
+		err = writer.WriteEntry("MDR00000001/run.xtc", 20, time.Now(), bytes.NewReader([]byte("short")), "", nil)
+		assert.Error(t, err)
+
+		// retried successfully
+		writeTestEntry(t, writer, "MDR00000001/run.xtc", "trajectory")
+		assert.NoError(t, writer.Close())
+
+		expected := map[string]string{
+			"MDR00000001/top.pdb": "structure",
+			"MDR00000001/run.xtc": "trajectory",
+		}
+
+		if strings.HasSuffix(name, ".zip") {
+			// corrupt entries are not written
+			zipReader, err := zip.OpenReader(archivePath)
+			assert.NoError(t, err)
+			assert.Len(t, zipReader.File, 2)
+			zipReader.Close()
+
+			entries := readZipEntries(t, archivePath)
+			assert.Equal(t, expected, entries)
+
+			// no temp files left
+			dirEntries, err := os.ReadDir(dirPath)
+			assert.NoError(t, err)
+			assert.Len(t, dirEntries, 2)
+		} else {
+			entries := readTarEntries(t, archivePath)
+			assert.Equal(t, expected, entries)
+		}
+	}
+}
+
+func testOverwrite(t *testing.T) {
+	for _, name := range []string{"out.tar", "out.zip"} {
+		archivePath := filepath.Join(t.TempDir(), name)
+
+		writer, err := NewArchiveWriter(archivePath, false)
+		assert.NoError(t, err)
+		writeTestEntry(t, writer, "MDR00000001/top.pdb", "structure")
+		writeTestEntry(t, writer, "MDR00000001/run.xtc", "trajectory")
+		assert.NoError(t, writer.Close())
+
+		// previous entries are not kept
+		writer, err = NewArchiveWriter(archivePath, true)
+		assert.NoError(t, err)
+		assert.False(t, writer.HasEntry("MDR00000001/top.pdb", 9, "MD5", md5Of("structure")))
+		writeTestEntry(t, writer, "MDR00000001/top.pdb", "structure")
+		assert.NoError(t, writer.Close())
+
+		expected := map[string]string{
+			"MDR00000001/top.pdb": "structure",
+		}
+
+		if strings.HasSuffix(name, ".zip") {
+			zipReader, err := zip.OpenReader(archivePath)
+			assert.NoError(t, err)
+			assert.Len(t, zipReader.File, 1)
+			zipReader.Close()
+
+			assert.Equal(t, expected, readZipEntries(t, archivePath))
+		} else {
+			assert.Equal(t, expected, readTarEntries(t, archivePath))
+		}
+
+		index := ReadArchiveIndex(archivePath)
+		assert.Len(t, index.entries, 1)
+	}
+}
+
+func testExistingArchive(t *testing.T) {
+	archivePath := filepath.Join(t.TempDir(), "out.tar")
+	assert.NoError(t, os.WriteFile(archivePath, []byte("not written by mdrepo"), 0644))
+
+	_, err := NewArchiveWriter(archivePath, false)
+	assert.Error(t, err)
+
+	writer, err := NewArchiveWriter(archivePath, true)
+	assert.NoError(t, err)
+	writeTestEntry(t, writer, "MDR00000001/top.pdb", "structure")
+	assert.NoError(t, writer.Close())
+
+	assert.Len(t, readTarEntries(t, archivePath), 1)
+}
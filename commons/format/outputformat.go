@@ -39,7 +39,7 @@ func GetOutputFormat(order string) OutputFormat {
 
 type OutputFormatter struct {
 	Writer io.Writer
-	Tables []OutputFormatterTable
+	Tables []*OutputFormatterTable // pointers, so tables returned by NewTable stay valid after adding more
 }
 
 type OutputFormatterTable struct {
@@ -57,19 +57,19 @@ type OutputFormatterTableObject struct {
 func NewOutputFormatter(writer io.Writer) *OutputFormatter {
 	return &OutputFormatter{
 		Writer: writer,
-		Tables: []OutputFormatterTable{},
+		Tables: []*OutputFormatterTable{},
 	}
 }
 
 func (of *OutputFormatter) NewTable(title string) *OutputFormatterTable {
-	table := OutputFormatterTable{
+	table := &OutputFormatterTable{
 		Title:    title,
 		Header:   []string{},
 		WidthMax: []int{},
 		Rows:     [][]interface{}{},
 	}
 	of.Tables = append(of.Tables, table)
-	return &of.Tables[len(of.Tables)-1]
+	return table
 }
 
 func (of *OutputFormatter) GetCurrentTable() *OutputFormatterTable {
@@ -77,7 +77,7 @@ func (of *OutputFormatter) GetCurrentTable() *OutputFormatterTable {
 		return nil
 	}
 
-	return &of.Tables[len(of.Tables)-1]
+	return of.Tables[len(of.Tables)-1]
 }
 
 func (of *OutputFormatter) Render(format OutputFormat) {
@@ -0,0 +1,37 @@
+package format
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOutputFormatter(t *testing.T) {
+	t.Run("test MultipleTables", testMultipleTables)
+}
+
+func testMultipleTables(t *testing.T) {
+	buffer := &bytes.Buffer{}
+	outputFormatter := NewOutputFormatter(buffer)
+
+	// fill tables after creating others, as callers do for nested listings
+	tables := []*OutputFormatterTable{}
+	for _, title := range []string{"first", "second", "third", "fourth", "fifth"} {
+		table := outputFormatter.NewTable(title)
+		table.SetHeader([]string{"Name"})
+		tables = append(tables, table)
+	}
+
+	for _, table := range tables {
+		table.AppendRow([]interface{}{table.Title + "-row"})
+	}
+
+	assert.Len(t, outputFormatter.Tables, 5)
+	for _, table := range outputFormatter.Tables {
+		assert.Equal(t, [][]interface{}{{table.Title + "-row"}}, table.Rows)
+	}
+
+	outputFormatter.Render(OutputFormatCSV)
+	assert.Equal(t, "Name\nfirst-row\n\nName\nsecond-row\n\nName\nthird-row\n\nName\nfourth-row\n\nName\nfifth-row\n", buffer.String())
+}
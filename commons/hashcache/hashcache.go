@@ -0,0 +1,333 @@
+package hashcache
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/cyverse/go-irodsclient/irods/common"
+	irodsclient_util "github.com/cyverse/go-irodsclient/irods/util"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	HashCacheDirname  string = "mdrepo"
+	HashCacheFilename string = "hash-cache.json"
+)
+
+// HashCacheEntry is a hash of a local file, valid while the file's size, mtime and inode are unchanged
+type HashCacheEntry struct {
+	Path      string    `json:"path"`
+	Size      int64     `json:"size"`
+	ModTime   time.Time `json:"mod_time"`
+	Inode     uint64    `json:"inode"`
+	Algorithm string    `json:"algorithm"`
+	Hash      string    `json:"hash"` // hex string
+	CachedAt  time.Time `json:"cached_at"`
+}
+
+// HashCache is a persistent cache of local file hashes
+type HashCache struct {
+	cachePath string
+	entries   map[string]*HashCacheEntry // path + algorithm -> entry
+	dirty     bool
+	mutex     sync.Mutex
+}
+
+// GetDefaultHashCachePath returns the path of the hash cache file under the user's cache dir
+func GetDefaultHashCachePath() (string, error) {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to get user cache dir")
+	}
+
+	return filepath.Join(cacheDir, HashCacheDirname, HashCacheFilename), nil
+}
+
+// NewHashCache creates a hash cache, loading existing entries from the cache file
+// a corrupted cache file is discarded
+func NewHashCache(cachePath string) (*HashCache, error) {
+	logger := log.WithFields(log.Fields{
+		"cache_path": cachePath,
+	})
+
+	cache := &HashCache{
+		cachePath: cachePath,
+		entries:   map[string]*HashCacheEntry{},
+	}
+
+	cacheBytes, err := os.ReadFile(cachePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return cache, nil
+		}
+
+		return nil, errors.Wrapf(err, "failed to read hash cache %q", cachePath)
+	}
+
+	entries := []*HashCacheEntry{}
+	err = json.Unmarshal(cacheBytes, &entries)
+	if err != nil {
+		logger.WithError(err).Warnf("discarding corrupted hash cache %q", cachePath)
+		cache.dirty = true
+		return cache, nil
+	}
+
+	for _, entry := range entries {
+		cache.entries[makeHashCacheKey(entry.Path, entry.Algorithm)] = entry
+	}
+
+	return cache, nil
+}
+
+// NewDefaultHashCache creates a hash cache stored under the user's cache dir
+func NewDefaultHashCache() (*HashCache, error) {
+	cachePath, err := GetDefaultHashCachePath()
+	if err != nil {
+		return nil, err
+	}
+
+	return NewHashCache(cachePath)
+}
+
+func makeHashCacheKey(path string, algorithm string) string {
+	return path + "\x00" + strings.ToLower(algorithm)
+}
+
+// GetPath returns the path of the cache file
+func (cache *HashCache) GetPath() string {
+	return cache.cachePath
+}
+
+// Get returns a cached hash of the file if the file is unchanged
+func (cache *HashCache) Get(path string, algorithm string) ([]byte, bool) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, false
+	}
+
+	st, err := os.Stat(absPath)
+	if err != nil {
+		return nil, false
+	}
+
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	entry, ok := cache.entries[makeHashCacheKey(absPath, algorithm)]
+	if !ok || !entry.matches(st) {
+		return nil, false
+	}
+
+	hash, err := hex.DecodeString(entry.Hash)
+	if err != nil {
+		return nil, false
+	}
+
+	return hash, true
+}
+
+// Put stores a hash of the file, st is the stat of the file taken before hashing
+func (cache *HashCache) Put(path string, algorithm string, hash []byte, st os.FileInfo) error {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return errors.Wrapf(err, "failed to get absolute path for %q", path)
+	}
+
+	entry := &HashCacheEntry{
+		Path:      absPath,
+		Size:      st.Size(),
+		ModTime:   st.ModTime(),
+		Inode:     getInode(st),
+		Algorithm: strings.ToLower(algorithm),
+		Hash:      hex.EncodeToString(hash),
+		CachedAt:  time.Now(),
+	}
+
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	cache.entries[makeHashCacheKey(absPath, algorithm)] = entry
+	cache.dirty = true
+
+	return nil
+}
+
+// HashLocalFile returns a hash of the file, computed only if not cached
+// cache can be nil, then the hash is always computed
+func (cache *HashCache) HashLocalFile(path string, algorithm string, processCallback common.TransferTrackerCallback) ([]byte, error) {
+	if cache != nil {
+		if hash, ok := cache.Get(path, algorithm); ok {
+			if processCallback != nil {
+				if st, err := os.Stat(path); err == nil {
+					processCallback("checksum", st.Size(), st.Size())
+				}
+			}
+
+			return hash, nil
+		}
+	}
+
+	return cache.RehashLocalFile(path, algorithm, processCallback)
+}
+
+// RehashLocalFile returns a hash of the file, always computed from its content and stored to the cache
+// the hash is not stored if the file is changed while hashing
+// cache can be nil, then the hash is only computed
+func (cache *HashCache) RehashLocalFile(path string, algorithm string, processCallback common.TransferTrackerCallback) ([]byte, error) {
+	if cache == nil {
+		return irodsclient_util.HashLocalFile(path, algorithm, processCallback)
+	}
+
+	logger := log.WithFields(log.Fields{
+		"path":      path,
+		"algorithm": algorithm,
+	})
+
+	stBefore, err := os.Stat(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to stat %q", path)
+	}
+
+	hash, err := irodsclient_util.HashLocalFile(path, algorithm, processCallback)
+	if err != nil {
+		return nil, err
+	}
+
+	stAfter, err := os.Stat(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to stat %q", path)
+	}
+
+	if stBefore.Size() != stAfter.Size() || !stBefore.ModTime().Equal(stAfter.ModTime()) || getInode(stBefore) != getInode(stAfter) {
+		logger.Debug("file changed while hashing, not caching the hash")
+		return hash, nil
+	}
+
+	err = cache.Put(path, algorithm, hash, stBefore)
+	if err != nil {
+		return nil, err
+	}
+
+	return hash, nil
+}
+
+// List returns all entries sorted by path
+func (cache *HashCache) List() []*HashCacheEntry {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	entries := make([]*HashCacheEntry, 0, len(cache.entries))
+	for _, entry := range cache.entries {
+		entries = append(entries, entry)
+	}
+
+	sort.Slice(entries, func(i int, j int) bool {
+		if entries[i].Path == entries[j].Path {
+			return entries[i].Algorithm < entries[j].Algorithm
+		}
+		return entries[i].Path < entries[j].Path
+	})
+
+	return entries
+}
+
+// Prune removes entries of files that are missing or changed, returns the number of entries removed
+func (cache *HashCache) Prune() int {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	removed := 0
+	for key, entry := range cache.entries {
+		st, err := os.Stat(entry.Path)
+		if err != nil || !entry.matches(st) {
+			delete(cache.entries, key)
+			removed++
+		}
+	}
+
+	if removed > 0 {
+		cache.dirty = true
+	}
+
+	return removed
+}
+
+// Clear removes all entries, returns the number of entries removed
+func (cache *HashCache) Clear() int {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	removed := len(cache.entries)
+	cache.entries = map[string]*HashCacheEntry{}
+	cache.dirty = true
+
+	return removed
+}
+
+// Save writes entries to the cache file if changed
+func (cache *HashCache) Save() error {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	if !cache.dirty {
+		return nil
+	}
+
+	entries := make([]*HashCacheEntry, 0, len(cache.entries))
+	for _, entry := range cache.entries {
+		entries = append(entries, entry)
+	}
+
+	cacheBytes, err := json.Marshal(entries)
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal hash cache")
+	}
+
+	cacheDir := filepath.Dir(cache.cachePath)
+	err = os.MkdirAll(cacheDir, 0700)
+	if err != nil {
+		return errors.Wrapf(err, "failed to make hash cache dir %q", cacheDir)
+	}
+
+	// write to a temp file and rename to avoid leaving a partial cache file
+	tempFile, err := os.CreateTemp(cacheDir, HashCacheFilename+".*.tmp")
+	if err != nil {
+		return errors.Wrapf(err, "failed to create temp file in %q", cacheDir)
+	}
+
+	tempPath := tempFile.Name()
+	_, err = tempFile.Write(cacheBytes)
+	closeErr := tempFile.Close()
+	if err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		os.Remove(tempPath)
+		return errors.Wrapf(err, "failed to write hash cache %q", tempPath)
+	}
+
+	err = os.Rename(tempPath, cache.cachePath)
+	if err != nil {
+		os.Remove(tempPath)
+		return errors.Wrapf(err, "failed to rename %q to %q", tempPath, cache.cachePath)
+	}
+
+	cache.dirty = false
+	return nil
+}
+
+func (entry *HashCacheEntry) matches(st os.FileInfo) bool {
+	if st.IsDir() {
+		return false
+	}
+
+	return entry.Size == st.Size() && entry.ModTime.Equal(st.ModTime()) && entry.Inode == getInode(st)
+}
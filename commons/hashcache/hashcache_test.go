@@ -0,0 +1,196 @@
+package hashcache
+
+import (
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHashCache(t *testing.T) {
+	t.Run("test HashLocalFile", testHashLocalFile)
+	t.Run("test RehashLocalFile", testRehashLocalFile)
+	t.Run("test FileChangedWhileHashing", testFileChangedWhileHashing)
+	t.Run("test SaveAndLoad", testSaveAndLoad)
+	t.Run("test PruneAndClear", testPruneAndClear)
+}
+
+func testHashLocalFile(t *testing.T) {
+	dirPath := t.TempDir()
+	filePath := filepath.Join(dirPath, "data.bin")
+
+	err := os.WriteFile(filePath, []byte("hello"), 0644)
+	assert.NoError(t, err)
+
+	cache, err := NewHashCache(filepath.Join(dirPath, "cache", HashCacheFilename))
+	assert.NoError(t, err)
+
+	_, ok := cache.Get(filePath, "md5")
+	assert.False(t, ok)
+
+	hash, err := cache.HashLocalFile(filePath, "md5", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "5d41402abc4b2a76b9719d911017c592", hex.EncodeToString(hash))
+
+	cachedHash, ok := cache.Get(filePath, "MD5")
+	assert.True(t, ok)
+	assert.Equal(t, hash, cachedHash)
+
+	// other algorithms are cached separately
+	_, ok = cache.Get(filePath, "sha256")
+	assert.False(t, ok)
+
+	// changed file invalidates the entry
+	err = os.WriteFile(filePath, []byte("world!"), 0644)
+	assert.NoError(t, err)
+
+	_, ok = cache.Get(filePath, "md5")
+	assert.False(t, ok)
+
+	// nil cache computes hash
+	var nilCache *HashCache
+	hash, err = nilCache.HashLocalFile(filePath, "md5", nil)
+	assert.NoError(t, err)
+	assert.Len(t, hash, 16)
+}
+
+func testRehashLocalFile(t *testing.T) {
+	dirPath := t.TempDir()
+	filePath := filepath.Join(dirPath, "data.bin")
+
+	err := os.WriteFile(filePath, []byte("hello"), 0644)
+	assert.NoError(t, err)
+
+	cache, err := NewHashCache(filepath.Join(dirPath, "cache", HashCacheFilename))
+	assert.NoError(t, err)
+
+	_, err = cache.HashLocalFile(filePath, "md5", nil)
+	assert.NoError(t, err)
+
+	// corrupt the content without changing the size and modification time
+	st, err := os.Stat(filePath)
+	assert.NoError(t, err)
+
+	err = os.WriteFile(filePath, []byte("jello"), 0644)
+	assert.NoError(t, err)
+
+	err = os.Chtimes(filePath, st.ModTime(), st.ModTime())
+	assert.NoError(t, err)
+
+	// cached hash is stale
+	hash, err := cache.HashLocalFile(filePath, "md5", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "5d41402abc4b2a76b9719d911017c592", hex.EncodeToString(hash))
+
+	hash, err = cache.RehashLocalFile(filePath, "md5", nil)
+	assert.NoError(t, err)
+	assert.NotEqual(t, "5d41402abc4b2a76b9719d911017c592", hex.EncodeToString(hash))
+
+	// cache is updated
+	cachedHash, ok := cache.Get(filePath, "md5")
+	assert.True(t, ok)
+	assert.Equal(t, hash, cachedHash)
+}
+
+func testFileChangedWhileHashing(t *testing.T) {
+	dirPath := t.TempDir()
+	filePath := filepath.Join(dirPath, "data.bin")
+
+	err := os.WriteFile(filePath, []byte("hello"), 0644)
+	assert.NoError(t, err)
+
+	cache, err := NewHashCache(filepath.Join(dirPath, "cache", HashCacheFilename))
+	assert.NoError(t, err)
+
+	changed := false
+	_, err = cache.HashLocalFile(filePath, "md5", func(taskName string, processed int64, total int64) {
+		if !changed {
+			changed = true
+			assert.NoError(t, os.WriteFile(filePath, []byte("hello world"), 0644))
+		}
+	})
+	assert.NoError(t, err)
+	assert.True(t, changed)
+
+	_, ok := cache.Get(filePath, "md5")
+	assert.False(t, ok)
+
+	// unchanged file is cached
+	hash, err := cache.HashLocalFile(filePath, "md5", nil)
+	assert.NoError(t, err)
+
+	cachedHash, ok := cache.Get(filePath, "md5")
+	assert.True(t, ok)
+	assert.Equal(t, hash, cachedHash)
+}
+
+func testSaveAndLoad(t *testing.T) {
+	dirPath := t.TempDir()
+	filePath := filepath.Join(dirPath, "data.bin")
+	cachePath := filepath.Join(dirPath, "cache", HashCacheFilename)
+
+	err := os.WriteFile(filePath, []byte("hello"), 0644)
+	assert.NoError(t, err)
+
+	cache, err := NewHashCache(cachePath)
+	assert.NoError(t, err)
+
+	_, err = cache.HashLocalFile(filePath, "md5", nil)
+	assert.NoError(t, err)
+
+	err = cache.Save()
+	assert.NoError(t, err)
+
+	loadedCache, err := NewHashCache(cachePath)
+	assert.NoError(t, err)
+
+	entries := loadedCache.List()
+	assert.Len(t, entries, 1)
+	assert.Equal(t, filePath, entries[0].Path)
+
+	_, ok := loadedCache.Get(filePath, "md5")
+	assert.True(t, ok)
+
+	// corrupted cache file is discarded
+	err = os.WriteFile(cachePath, []byte("{not json"), 0644)
+	assert.NoError(t, err)
+
+	corruptedCache, err := NewHashCache(cachePath)
+	assert.NoError(t, err)
+	assert.Len(t, corruptedCache.List(), 0)
+}
+
+func testPruneAndClear(t *testing.T) {
+	dirPath := t.TempDir()
+	filePath1 := filepath.Join(dirPath, "data1.bin")
+	filePath2 := filepath.Join(dirPath, "data2.bin")
+
+	err := os.WriteFile(filePath1, []byte("hello"), 0644)
+	assert.NoError(t, err)
+
+	err = os.WriteFile(filePath2, []byte("world"), 0644)
+	assert.NoError(t, err)
+
+	cache, err := NewHashCache(filepath.Join(dirPath, HashCacheFilename))
+	assert.NoError(t, err)
+
+	_, err = cache.HashLocalFile(filePath1, "md5", nil)
+	assert.NoError(t, err)
+
+	_, err = cache.HashLocalFile(filePath2, "md5", nil)
+	assert.NoError(t, err)
+
+	// touching a file invalidates the entry
+	future := time.Now().Add(time.Hour)
+	err = os.Chtimes(filePath2, future, future)
+	assert.NoError(t, err)
+
+	assert.Equal(t, 1, cache.Prune())
+	assert.Len(t, cache.List(), 1)
+
+	assert.Equal(t, 1, cache.Clear())
+	assert.Len(t, cache.List(), 0)
+}
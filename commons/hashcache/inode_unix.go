@@ -0,0 +1,17 @@
+//go:build !windows
+
+package hashcache
+
+import (
+	"os"
+	"syscall"
+)
+
+// getInode returns inode number of the file, 0 if not available
+func getInode(st os.FileInfo) uint64 {
+	if sys, ok := st.Sys().(*syscall.Stat_t); ok {
+		return uint64(sys.Ino)
+	}
+
+	return 0
+}
@@ -0,0 +1,13 @@
+//go:build windows
+
+package hashcache
+
+import (
+	"os"
+)
+
+// getInode returns inode number of the file, 0 if not available
+// file index is not exposed through os.FileInfo on windows, so size and mtime are used alone
+func getInode(st os.FileInfo) uint64 {
+	return 0
+}
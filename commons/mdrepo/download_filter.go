@@ -0,0 +1,87 @@
+package mdrepo
+
+import (
+	"fmt"
+	"path"
+
+	"github.com/MD-Repo/md-repo-cli/commons/types"
+	"github.com/cockroachdb/errors"
+)
+
+// DownloadFilter selects released files to download
+type DownloadFilter struct {
+	Include     []string // glob patterns of files to include, matched against relative path or name
+	Exclude     []string // glob patterns of files or directories to exclude, matched against relative path or name
+	MaxFileSize int64    // 0 for unlimited
+}
+
+// NewDownloadFilter creates a new DownloadFilter, returns error if a pattern is invalid
+func NewDownloadFilter(include []string, exclude []string, maxFileSize int64) (*DownloadFilter, error) {
+	for _, pattern := range append(append([]string{}, include...), exclude...) {
+		_, err := path.Match(pattern, "")
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid pattern %q", pattern)
+		}
+	}
+
+	if maxFileSize < 0 {
+		return nil, errors.Errorf("invalid max file size %d", maxFileSize)
+	}
+
+	return &DownloadFilter{
+		Include:     include,
+		Exclude:     exclude,
+		MaxFileSize: maxFileSize,
+	}, nil
+}
+
+// IsEmpty returns true if the filter selects all files
+func (filter *DownloadFilter) IsEmpty() bool {
+	return len(filter.Include) == 0 && len(filter.Exclude) == 0 && filter.MaxFileSize == 0
+}
+
+func (filter *DownloadFilter) matchAny(patterns []string, relPath string) string {
+	name := path.Base(relPath)
+
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(pattern, relPath); matched {
+			return pattern
+		}
+
+		if matched, _ := path.Match(pattern, name); matched {
+			return pattern
+		}
+	}
+
+	return ""
+}
+
+// FilterDir returns a reason if the directory is excluded, empty string otherwise
+// relPath is relative to the simulation, with '/' as a separator
+func (filter *DownloadFilter) FilterDir(relPath string) string {
+	excludePattern := filter.matchAny(filter.Exclude, relPath)
+	if len(excludePattern) > 0 {
+		return fmt.Sprintf("excluded by pattern %q", excludePattern)
+	}
+
+	return ""
+}
+
+// FilterFile returns a reason if the file is not selected, empty string otherwise
+// relPath is relative to the simulation, with '/' as a separator
+func (filter *DownloadFilter) FilterFile(relPath string, size int64) string {
+	excludePattern := filter.matchAny(filter.Exclude, relPath)
+	if len(excludePattern) > 0 {
+		return fmt.Sprintf("excluded by pattern %q", excludePattern)
+	}
+
+	if len(filter.Include) > 0 && len(filter.matchAny(filter.Include, relPath)) == 0 {
+		return "not matching include patterns"
+	}
+
+	if filter.MaxFileSize > 0 && size > filter.MaxFileSize {
+		return fmt.Sprintf("larger than %s", types.SizeString(filter.MaxFileSize))
+	}
+
+	return ""
+}
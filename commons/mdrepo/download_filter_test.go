@@ -0,0 +1,49 @@
+package mdrepo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDownloadFilter(t *testing.T) {
+	t.Run("test FilterFile", testFilterFile)
+	t.Run("test FilterDir", testFilterDir)
+	t.Run("test InvalidFilter", testInvalidFilter)
+}
+
+func testFilterFile(t *testing.T) {
+	filter, err := NewDownloadFilter([]string{"*.pdb", "*.top", "inputs/*"}, []string{"scratch.*"}, 1024)
+	assert.NoError(t, err)
+	assert.False(t, filter.IsEmpty())
+
+	assert.Empty(t, filter.FilterFile("structure.pdb", 100))
+	assert.Empty(t, filter.FilterFile("sub/topology.top", 100))
+	assert.Empty(t, filter.FilterFile("inputs/md.mdp", 100))
+	assert.Equal(t, "not matching include patterns", filter.FilterFile("run.xtc", 100))
+	assert.Equal(t, "excluded by pattern \"scratch.*\"", filter.FilterFile("scratch.pdb", 100))
+	assert.NotEmpty(t, filter.FilterFile("structure.pdb", 2048))
+
+	emptyFilter, err := NewDownloadFilter(nil, nil, 0)
+	assert.NoError(t, err)
+	assert.True(t, emptyFilter.IsEmpty())
+	assert.Empty(t, emptyFilter.FilterFile("run.xtc", 1<<40))
+}
+
+func testFilterDir(t *testing.T) {
+	filter, err := NewDownloadFilter([]string{"*.pdb"}, []string{"analysis"}, 0)
+	assert.NoError(t, err)
+
+	assert.NotEmpty(t, filter.FilterDir("analysis"))
+	assert.NotEmpty(t, filter.FilterDir("rep1/analysis"))
+	// include patterns apply to files only
+	assert.Empty(t, filter.FilterDir("inputs"))
+}
+
+func testInvalidFilter(t *testing.T) {
+	_, err := NewDownloadFilter([]string{"[a-"}, nil, 0)
+	assert.Error(t, err)
+
+	_, err = NewDownloadFilter(nil, nil, -1)
+	assert.Error(t, err)
+}
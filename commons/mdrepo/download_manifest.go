@@ -0,0 +1,228 @@
+package mdrepo
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cockroachdb/errors"
+)
+
+const (
+	DownloadManifestFilename       string = "mdrepo-manifest.json"
+	DownloadManifestFilenamePrefix string = "mdrepo-manifest."
+)
+
+// DownloadManifestFile is a downloaded file recorded in the manifest
+type DownloadManifestFile struct {
+	Path              string    `json:"path"` // relative to the simulation dir, with '/' as a separator
+	Size              int64     `json:"size"`
+	ChecksumAlgorithm string    `json:"checksum_algorithm"`
+	Checksum          string    `json:"checksum"` // hex string
+	IRODSPath         string    `json:"irods_path"`
+	DownloadedAt      time.Time `json:"downloaded_at"`
+}
+
+// DownloadManifest lists files downloaded to a simulation dir, to verify them without network access
+type DownloadManifest struct {
+	RootPath   string                  `json:"-"` // local simulation dir
+	Simulation string                  `json:"simulation"`
+	IRODSPath  string                  `json:"irods_path"`
+	UpdatedAt  time.Time               `json:"updated_at"`
+	Files      []*DownloadManifestFile `json:"files"`
+
+	files         map[string]*DownloadManifestFile // files of this run
+	previousFiles map[string]*DownloadManifestFile // files in the manifest saved before
+	mutex         sync.Mutex
+}
+
+// IsDownloadManifestFile returns true if the path relative to the simulation dir is a manifest file
+func IsDownloadManifestFile(relPath string) bool {
+	return !strings.Contains(relPath, "/") && strings.HasPrefix(relPath, DownloadManifestFilenamePrefix)
+}
+
+// GetDownloadManifestChecksumFilename returns the name of md5sum-compatible manifest for the checksum algorithm
+// e.g., mdrepo-manifest.md5 for MD5, mdrepo-manifest.sha256 for SHA-256
+func GetDownloadManifestChecksumFilename(checksumAlgorithm string) string {
+	algorithm := strings.ToLower(strings.ReplaceAll(checksumAlgorithm, "-", ""))
+	return DownloadManifestFilenamePrefix + algorithm
+}
+
+// NewDownloadManifest creates a new DownloadManifest, files in the existing manifest are kept to be reused
+func NewDownloadManifest(rootPath string, simulation string, irodsPath string) *DownloadManifest {
+	manifest := &DownloadManifest{
+		RootPath:      rootPath,
+		Simulation:    simulation,
+		IRODSPath:     irodsPath,
+		Files:         []*DownloadManifestFile{},
+		files:         map[string]*DownloadManifestFile{},
+		previousFiles: map[string]*DownloadManifestFile{},
+	}
+
+	manifestBytes, err := os.ReadFile(filepath.Join(rootPath, DownloadManifestFilename))
+	if err != nil {
+		return manifest
+	}
+
+	previous := DownloadManifest{}
+	err = json.Unmarshal(manifestBytes, &previous)
+	if err != nil {
+		// ignore broken manifest, it is rewritten
+		return manifest
+	}
+
+	for _, file := range previous.Files {
+		manifest.previousFiles[file.Path] = file
+	}
+
+	return manifest
+}
+
+func (manifest *DownloadManifest) getRelPath(localPath string) (string, error) {
+	relPath, err := filepath.Rel(manifest.RootPath, localPath)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to get relative path for %q", localPath)
+	}
+
+	relPath = path.Clean(filepath.ToSlash(relPath))
+	if relPath == ".." || strings.HasPrefix(relPath, "../") {
+		return "", errors.Errorf("file %q is not under %q", localPath, manifest.RootPath)
+	}
+
+	return relPath, nil
+}
+
+// AddFile records a file downloaded
+func (manifest *DownloadManifest) AddFile(localPath string, irodsPath string, size int64, checksumAlgorithm string, checksum string, downloadedAt time.Time) error {
+	relPath, err := manifest.getRelPath(localPath)
+	if err != nil {
+		return err
+	}
+
+	manifest.mutex.Lock()
+	defer manifest.mutex.Unlock()
+
+	manifest.files[relPath] = &DownloadManifestFile{
+		Path:              relPath,
+		Size:              size,
+		ChecksumAlgorithm: checksumAlgorithm,
+		Checksum:          checksum,
+		IRODSPath:         irodsPath,
+		DownloadedAt:      downloadedAt.UTC(),
+	}
+
+	return nil
+}
+
+// AddUnchangedFile records a file not downloaded as the same file exists
+// the download time recorded before is kept
+func (manifest *DownloadManifest) AddUnchangedFile(localPath string, irodsPath string, size int64, checksumAlgorithm string, checksum string) error {
+	relPath, err := manifest.getRelPath(localPath)
+	if err != nil {
+		return err
+	}
+
+	downloadedAt := time.Now()
+
+	manifest.mutex.Lock()
+	if previousFile, ok := manifest.previousFiles[relPath]; ok && previousFile.ChecksumAlgorithm == checksumAlgorithm && previousFile.Checksum == checksum {
+		downloadedAt = previousFile.DownloadedAt
+	}
+	manifest.mutex.Unlock()
+
+	return manifest.AddFile(localPath, irodsPath, size, checksumAlgorithm, checksum, downloadedAt)
+}
+
+// Save writes the JSON manifest and md5sum-compatible manifests, a manifest for each checksum algorithm
+// files saved before are kept if they still exist with the same size, e.g., files not selected in this run
+func (manifest *DownloadManifest) Save() error {
+	manifest.mutex.Lock()
+	defer manifest.mutex.Unlock()
+
+	for relPath, previousFile := range manifest.previousFiles {
+		if _, ok := manifest.files[relPath]; ok {
+			continue
+		}
+
+		st, err := os.Stat(filepath.Join(manifest.RootPath, filepath.FromSlash(relPath)))
+		if err != nil || st.IsDir() || st.Size() != previousFile.Size {
+			continue
+		}
+
+		manifest.files[relPath] = previousFile
+	}
+
+	relPaths := make([]string, 0, len(manifest.files))
+	for relPath := range manifest.files {
+		relPaths = append(relPaths, relPath)
+	}
+
+	sort.Strings(relPaths)
+
+	manifest.Files = []*DownloadManifestFile{}
+	checksumLines := map[string][]string{} // algorithm -> lines
+	for _, relPath := range relPaths {
+		file := manifest.files[relPath]
+		manifest.Files = append(manifest.Files, file)
+
+		if len(file.Checksum) > 0 {
+			// format of md5sum: "<checksum>  <path>"
+			checksumLines[file.ChecksumAlgorithm] = append(checksumLines[file.ChecksumAlgorithm], fmt.Sprintf("%s  %s", file.Checksum, file.Path))
+		}
+	}
+
+	manifest.UpdatedAt = time.Now().UTC()
+
+	manifestBytes, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal manifest")
+	}
+
+	err = os.WriteFile(filepath.Join(manifest.RootPath, DownloadManifestFilename), manifestBytes, 0644)
+	if err != nil {
+		return errors.Wrapf(err, "failed to write manifest in %q", manifest.RootPath)
+	}
+
+	checksumFilenames := map[string]bool{}
+	for checksumAlgorithm, lines := range checksumLines {
+		checksumFilename := GetDownloadManifestChecksumFilename(checksumAlgorithm)
+		checksumFilenames[checksumFilename] = true
+
+		checksumFilePath := filepath.Join(manifest.RootPath, checksumFilename)
+		err = os.WriteFile(checksumFilePath, []byte(strings.Join(lines, "\n")+"\n"), 0644)
+		if err != nil {
+			return errors.Wrapf(err, "failed to write manifest %q", checksumFilePath)
+		}
+	}
+
+	return manifest.removeStaleChecksumFiles(checksumFilenames)
+}
+
+// removeStaleChecksumFiles removes md5sum-compatible manifests of checksum algorithms no longer used
+func (manifest *DownloadManifest) removeStaleChecksumFiles(checksumFilenames map[string]bool) error {
+	dirEntries, err := os.ReadDir(manifest.RootPath)
+	if err != nil {
+		return errors.Wrapf(err, "failed to read dir %q", manifest.RootPath)
+	}
+
+	for _, dirEntry := range dirEntries {
+		name := dirEntry.Name()
+		if dirEntry.IsDir() || name == DownloadManifestFilename || !IsDownloadManifestFile(name) || checksumFilenames[name] {
+			continue
+		}
+
+		checksumFilePath := filepath.Join(manifest.RootPath, name)
+		err = os.Remove(checksumFilePath)
+		if err != nil && !os.IsNotExist(err) {
+			return errors.Wrapf(err, "failed to remove manifest %q", checksumFilePath)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,108 @@
+package mdrepo
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDownloadManifest(t *testing.T) {
+	t.Run("test ManifestSave", testManifestSave)
+	t.Run("test ManifestUnchangedFile", testManifestUnchangedFile)
+	t.Run("test ManifestPreviousFiles", testManifestPreviousFiles)
+}
+
+func testManifestSave(t *testing.T) {
+	rootPath := t.TempDir()
+	downloadedAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	manifest := NewDownloadManifest(rootPath, "MDR00000001", "/release/MDR00000001")
+	assert.NoError(t, manifest.AddFile(filepath.Join(rootPath, "sub", "run.xtc"), "/release/MDR00000001/sub/run.xtc", 100, "MD5", "22fe845f9c32e865956963a503087067", downloadedAt))
+	assert.NoError(t, manifest.AddFile(filepath.Join(rootPath, "top.pdb"), "/release/MDR00000001/top.pdb", 10, "SHA-256", "abcd", downloadedAt))
+	assert.NoError(t, manifest.AddFile(filepath.Join(rootPath, "a.mdp"), "/release/MDR00000001/a.mdp", 5, "MD5", "1234", downloadedAt))
+	assert.Error(t, manifest.AddFile(filepath.Join(filepath.Dir(rootPath), "other"), "/release/other", 1, "MD5", "5678", downloadedAt))
+	assert.NoError(t, manifest.Save())
+
+	md5Bytes, err := os.ReadFile(filepath.Join(rootPath, "mdrepo-manifest.md5"))
+	assert.NoError(t, err)
+	assert.Equal(t, "1234  a.mdp\n22fe845f9c32e865956963a503087067  sub/run.xtc\n", string(md5Bytes))
+
+	sha256Bytes, err := os.ReadFile(filepath.Join(rootPath, "mdrepo-manifest.sha256"))
+	assert.NoError(t, err)
+	assert.Equal(t, "abcd  top.pdb\n", string(sha256Bytes))
+
+	jsonBytes, err := os.ReadFile(filepath.Join(rootPath, DownloadManifestFilename))
+	assert.NoError(t, err)
+
+	saved := DownloadManifest{}
+	assert.NoError(t, json.Unmarshal(jsonBytes, &saved))
+	assert.Equal(t, "MDR00000001", saved.Simulation)
+	assert.Len(t, saved.Files, 3)
+	assert.Equal(t, "sub/run.xtc", saved.Files[1].Path)
+	assert.Equal(t, int64(100), saved.Files[1].Size)
+	assert.Equal(t, downloadedAt, saved.Files[1].DownloadedAt)
+
+	assert.True(t, IsDownloadManifestFile("mdrepo-manifest.md5"))
+	assert.True(t, IsDownloadManifestFile(DownloadManifestFilename))
+	assert.False(t, IsDownloadManifestFile("sub/mdrepo-manifest.md5"))
+	assert.False(t, IsDownloadManifestFile("run.xtc"))
+}
+
+func testManifestUnchangedFile(t *testing.T) {
+	rootPath := t.TempDir()
+	downloadedAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	manifest := NewDownloadManifest(rootPath, "MDR00000001", "/release/MDR00000001")
+	assert.NoError(t, manifest.AddFile(filepath.Join(rootPath, "run.xtc"), "/release/MDR00000001/run.xtc", 100, "MD5", "1234", downloadedAt))
+	assert.NoError(t, manifest.AddFile(filepath.Join(rootPath, "old.xtc"), "/release/MDR00000001/old.xtc", 100, "MD5", "5678", downloadedAt))
+	assert.NoError(t, manifest.Save())
+
+	// next run skips run.xtc, old.xtc is no longer released
+	manifest = NewDownloadManifest(rootPath, "MDR00000001", "/release/MDR00000001")
+	assert.NoError(t, manifest.AddUnchangedFile(filepath.Join(rootPath, "run.xtc"), "/release/MDR00000001/run.xtc", 100, "MD5", "1234"))
+	assert.NoError(t, manifest.Save())
+
+	assert.Len(t, manifest.Files, 1)
+	assert.Equal(t, downloadedAt, manifest.Files[0].DownloadedAt)
+}
+
+func testManifestPreviousFiles(t *testing.T) {
+	rootPath := t.TempDir()
+	downloadedAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	writeFile := func(relPath string, content string) string {
+		localPath := filepath.Join(rootPath, relPath)
+		assert.NoError(t, os.WriteFile(localPath, []byte(content), 0644))
+		return localPath
+	}
+
+	manifest := NewDownloadManifest(rootPath, "MDR00000001", "/release/MDR00000001")
+	assert.NoError(t, manifest.AddFile(writeFile("top.pdb", "structure"), "/release/MDR00000001/top.pdb", 9, "SHA-256", "abcd", downloadedAt))
+	assert.NoError(t, manifest.AddFile(writeFile("run.xtc", "trajectory"), "/release/MDR00000001/run.xtc", 10, "MD5", "1234", downloadedAt))
+	assert.NoError(t, manifest.AddFile(writeFile("old.xtc", "old"), "/release/MDR00000001/old.xtc", 3, "MD5", "5678", downloadedAt))
+	assert.NoError(t, manifest.Save())
+
+	// next run downloads only md.mdp, run.xtc is kept, old.xtc is changed and top.pdb is removed
+	writeFile("old.xtc", "changed")
+	assert.NoError(t, os.Remove(filepath.Join(rootPath, "top.pdb")))
+
+	manifest = NewDownloadManifest(rootPath, "MDR00000001", "/release/MDR00000001")
+	assert.NoError(t, manifest.AddFile(writeFile("md.mdp", "input"), "/release/MDR00000001/md.mdp", 5, "MD5", "9abc", downloadedAt))
+	assert.NoError(t, manifest.Save())
+
+	assert.Len(t, manifest.Files, 2)
+	assert.Equal(t, "md.mdp", manifest.Files[0].Path)
+	assert.Equal(t, "run.xtc", manifest.Files[1].Path)
+
+	md5Bytes, err := os.ReadFile(filepath.Join(rootPath, "mdrepo-manifest.md5"))
+	assert.NoError(t, err)
+	assert.Equal(t, "9abc  md.mdp\n1234  run.xtc\n", string(md5Bytes))
+
+	// no SHA-256 files are left
+	_, err = os.Stat(filepath.Join(rootPath, "mdrepo-manifest.sha256"))
+	assert.True(t, os.IsNotExist(err))
+}
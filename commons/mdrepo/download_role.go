@@ -0,0 +1,114 @@
+package mdrepo
+
+import (
+	"path"
+	"strings"
+
+	"github.com/cockroachdb/errors"
+)
+
+const (
+	DownloadRoleTrajectory string = "trajectory"
+	DownloadRoleStructure  string = "structure"
+	DownloadRoleTopology   string = "topology"
+	DownloadRoleAdditional string = "additional"
+)
+
+// DownloadRole is a role of files described in metadata, FileType is only for additional files
+type DownloadRole struct {
+	Role     string
+	FileType string // empty for all types
+}
+
+// DownloadRoleSelection is a set of files selected by roles, relative to the simulation with '/' as a separator
+type DownloadRoleSelection map[string]bool
+
+// ParseDownloadRoles parses a comma-separated list of roles
+// e.g., "structure,topology,additional:pdb"
+func ParseDownloadRoles(rolesString string) ([]DownloadRole, error) {
+	roles := []DownloadRole{}
+
+	for _, roleString := range strings.Split(rolesString, ",") {
+		roleString = strings.TrimSpace(roleString)
+		if len(roleString) == 0 {
+			continue
+		}
+
+		role, fileType, _ := strings.Cut(roleString, ":")
+		role = strings.ToLower(strings.TrimSpace(role))
+		fileType = strings.TrimSpace(fileType)
+
+		switch role {
+		case DownloadRoleTrajectory, DownloadRoleStructure, DownloadRoleTopology:
+			if len(fileType) > 0 {
+				return nil, errors.Errorf("file type %q is only allowed for %q role", fileType, DownloadRoleAdditional)
+			}
+		case DownloadRoleAdditional:
+		default:
+			return nil, errors.Errorf("unknown role %q, must be one of %q, %q, %q or %q", role, DownloadRoleTrajectory, DownloadRoleStructure, DownloadRoleTopology, DownloadRoleAdditional)
+		}
+
+		roles = append(roles, DownloadRole{
+			Role:     role,
+			FileType: fileType,
+		})
+	}
+
+	if len(roles) == 0 {
+		return nil, errors.Errorf("no roles are given in %q", rolesString)
+	}
+
+	return roles, nil
+}
+
+// SelectDownloadFilesByRoles returns files of the given roles described in metadata
+// the metadata file is always selected
+func SelectDownloadFilesByRoles(metadata *MDRepoSubmitMetadata, roles []DownloadRole) DownloadRoleSelection {
+	selection := DownloadRoleSelection{}
+
+	add := func(filePath string) {
+		if len(filePath) > 0 {
+			selection[path.Clean(filePath)] = true
+		}
+	}
+
+	add(SubmissionMetadataFilename)
+
+	for _, role := range roles {
+		switch role.Role {
+		case DownloadRoleTrajectory:
+			for _, trajectoryFile := range metadata.TrajectoryFileNames {
+				add(trajectoryFile)
+			}
+		case DownloadRoleStructure:
+			add(metadata.StructureFileName)
+		case DownloadRoleTopology:
+			add(metadata.TopologyFileName)
+		case DownloadRoleAdditional:
+			for _, additionalFile := range metadata.AdditionalFiles {
+				if len(role.FileType) == 0 || strings.EqualFold(role.FileType, additionalFile.FileType) {
+					add(additionalFile.FileName)
+				}
+			}
+		}
+	}
+
+	return selection
+}
+
+// HasFile returns true if the file is selected
+func (selection DownloadRoleSelection) HasFile(relPath string) bool {
+	return selection[path.Clean(relPath)]
+}
+
+// HasDir returns true if the dir contains a selected file
+func (selection DownloadRoleSelection) HasDir(relPath string) bool {
+	prefix := path.Clean(relPath) + "/"
+	for filePath := range selection {
+		if strings.HasPrefix(filePath, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
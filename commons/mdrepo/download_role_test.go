@@ -0,0 +1,70 @@
+package mdrepo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDownloadRole(t *testing.T) {
+	t.Run("test ParseDownloadRoles", testParseDownloadRoles)
+	t.Run("test SelectDownloadFilesByRoles", testSelectDownloadFilesByRoles)
+}
+
+func testParseDownloadRoles(t *testing.T) {
+	roles, err := ParseDownloadRoles("structure, Topology,additional:PDB")
+	assert.NoError(t, err)
+	assert.Equal(t, []DownloadRole{
+		{Role: DownloadRoleStructure},
+		{Role: DownloadRoleTopology},
+		{Role: DownloadRoleAdditional, FileType: "PDB"},
+	}, roles)
+
+	_, err = ParseDownloadRoles("structures")
+	assert.Error(t, err)
+
+	_, err = ParseDownloadRoles("topology:psf")
+	assert.Error(t, err)
+
+	_, err = ParseDownloadRoles(" , ")
+	assert.Error(t, err)
+}
+
+func testSelectDownloadFilesByRoles(t *testing.T) {
+	metadata, err := ParseSubmitMetadataString(`
+trajectory_file_names = ["run1.xtc", "run2.xtc"]
+structure_file_name = "structure.pdb"
+topology_file_name = "inputs/topol.top"
+
+[[additional_files]]
+file_type = "Input"
+file_name = "inputs/md.mdp"
+
+[[additional_files]]
+file_type = "pdb"
+file_name = "analysis/frame.pdb"
+`)
+	assert.NoError(t, err)
+
+	roles, err := ParseDownloadRoles("topology,additional:input")
+	assert.NoError(t, err)
+
+	selection := SelectDownloadFilesByRoles(metadata, roles)
+	assert.Equal(t, DownloadRoleSelection{
+		"mdrepo-metadata.toml": true,
+		"inputs/topol.top":     true,
+		"inputs/md.mdp":        true,
+	}, selection)
+
+	assert.True(t, selection.HasFile("inputs/md.mdp"))
+	assert.False(t, selection.HasFile("run1.xtc"))
+	assert.True(t, selection.HasDir("inputs"))
+	assert.False(t, selection.HasDir("analysis"))
+
+	roles, err = ParseDownloadRoles("trajectory,additional")
+	assert.NoError(t, err)
+
+	selection = SelectDownloadFilesByRoles(metadata, roles)
+	assert.Len(t, selection, 5)
+	assert.True(t, selection.HasFile("analysis/frame.pdb"))
+}
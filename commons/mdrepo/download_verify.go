@@ -0,0 +1,204 @@
+package mdrepo
+
+import (
+	"bytes"
+	"encoding/hex"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"sort"
+
+	"github.com/cockroachdb/errors"
+	irodsclient_irodsfs "github.com/cyverse/go-irodsclient/irods/fs"
+)
+
+// DownloadVerifyResult is a result of verifying a downloaded file
+type DownloadVerifyResult string
+
+const (
+	// DownloadVerifyResultMissing is for a released file not found in local
+	DownloadVerifyResultMissing DownloadVerifyResult = "missing"
+	// DownloadVerifyResultCorrupt is for a local file having different size or checksum
+	DownloadVerifyResultCorrupt DownloadVerifyResult = "corrupt"
+	// DownloadVerifyResultExtra is for a local file not found in the release
+	DownloadVerifyResultExtra DownloadVerifyResult = "extra"
+	// DownloadVerifyResultUnverified is for a local file having the same size, but checksum is not available in iRODS
+	DownloadVerifyResultUnverified DownloadVerifyResult = "unverified"
+)
+
+// IsDamaged returns true if the local copy must be downloaded again
+func (result DownloadVerifyResult) IsDamaged() bool {
+	return result == DownloadVerifyResultMissing || result == DownloadVerifyResultCorrupt
+}
+
+// DownloadVerifyLocalFile is a downloaded file
+type DownloadVerifyLocalFile struct {
+	Path       string // relative to the simulation dir, with '/' as a separator
+	LocalPath  string
+	Size       int64
+	Incomplete bool // has a transfer status file of incomplete download
+}
+
+// DownloadVerifyRemoteFile is a released file
+type DownloadVerifyRemoteFile struct {
+	Path              string // relative to the release collection
+	IRODSPath         string
+	Size              int64
+	ChecksumAlgorithm string
+	Checksum          []byte
+}
+
+// DownloadVerifyEntry is a file that does not match
+type DownloadVerifyEntry struct {
+	Path              string
+	Result            DownloadVerifyResult
+	Reason            string
+	LocalPath         string
+	LocalSize         int64
+	LocalChecksum     string
+	IRODSPath         string
+	IRODSSize         int64
+	IRODSChecksum     string
+	ChecksumAlgorithm string
+}
+
+// DownloadVerifyHashFunc computes a hash of a local file with the given algorithm
+type DownloadVerifyHashFunc func(localPath string, algorithm string) ([]byte, error)
+
+// FindDownloadedFiles returns files under the local simulation dir
+// manifests are not returned, transfer status files are not returned, but mark files incomplete
+func FindDownloadedFiles(localDirPath string) ([]DownloadVerifyLocalFile, error) {
+	localFiles := []DownloadVerifyLocalFile{}
+	incompletePaths := map[string]bool{}
+
+	err := filepath.WalkDir(localDirPath, func(localPath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if entry.IsDir() {
+			return nil
+		}
+
+		relPath, err := filepath.Rel(localDirPath, localPath)
+		if err != nil {
+			return errors.Wrapf(err, "failed to get relative path for %q", localPath)
+		}
+
+		if irodsclient_irodsfs.IsDataObjectTransferStatusFile(localPath) {
+			incompletePaths[irodsclient_irodsfs.GetDataObjectTransferStatusFilePath(localPath)] = true
+			return nil
+		}
+
+		if IsDownloadManifestFile(filepath.ToSlash(relPath)) {
+			return nil
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return errors.Wrapf(err, "failed to stat %q", localPath)
+		}
+
+		localFiles = append(localFiles, DownloadVerifyLocalFile{
+			Path:      path.Clean(filepath.ToSlash(relPath)),
+			LocalPath: localPath,
+			Size:      info.Size(),
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to find files in %q", localDirPath)
+	}
+
+	for idx := range localFiles {
+		localFiles[idx].Incomplete = incompletePaths[irodsclient_irodsfs.GetDataObjectTransferStatusFilePath(localFiles[idx].LocalPath)]
+	}
+
+	return localFiles, nil
+}
+
+// VerifyDownloadedFiles compares downloaded files with released files by names, sizes and checksums
+// returns files not matching, sorted by path
+func VerifyDownloadedFiles(localFiles []DownloadVerifyLocalFile, remoteFiles []DownloadVerifyRemoteFile, hashFunc DownloadVerifyHashFunc) ([]DownloadVerifyEntry, error) {
+	localFileMap := map[string]DownloadVerifyLocalFile{}
+	for _, localFile := range localFiles {
+		localFileMap[localFile.Path] = localFile
+	}
+
+	entries := []DownloadVerifyEntry{}
+	remoteFileMap := map[string]bool{}
+
+	for _, remoteFile := range remoteFiles {
+		remoteFileMap[remoteFile.Path] = true
+
+		entry := DownloadVerifyEntry{
+			Path:      remoteFile.Path,
+			IRODSPath: remoteFile.IRODSPath,
+			IRODSSize: remoteFile.Size,
+		}
+
+		localFile, ok := localFileMap[remoteFile.Path]
+		if !ok {
+			entry.Result = DownloadVerifyResultMissing
+			entries = append(entries, entry)
+			continue
+		}
+
+		entry.LocalPath = localFile.LocalPath
+		entry.LocalSize = localFile.Size
+
+		if localFile.Incomplete {
+			entry.Result = DownloadVerifyResultCorrupt
+			entry.Reason = "incomplete download"
+			entries = append(entries, entry)
+			continue
+		}
+
+		if localFile.Size != remoteFile.Size {
+			entry.Result = DownloadVerifyResultCorrupt
+			entry.Reason = "size mismatch"
+			entries = append(entries, entry)
+			continue
+		}
+
+		if len(remoteFile.Checksum) == 0 {
+			entry.Result = DownloadVerifyResultUnverified
+			entry.Reason = "no checksum"
+			entries = append(entries, entry)
+			continue
+		}
+
+		localChecksum, err := hashFunc(localFile.LocalPath, remoteFile.ChecksumAlgorithm)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get hash of %q", localFile.LocalPath)
+		}
+
+		if !bytes.Equal(localChecksum, remoteFile.Checksum) {
+			entry.Result = DownloadVerifyResultCorrupt
+			entry.Reason = "checksum mismatch"
+			entry.ChecksumAlgorithm = remoteFile.ChecksumAlgorithm
+			entry.LocalChecksum = hex.EncodeToString(localChecksum)
+			entry.IRODSChecksum = hex.EncodeToString(remoteFile.Checksum)
+			entries = append(entries, entry)
+		}
+	}
+
+	for _, localFile := range localFiles {
+		if remoteFileMap[localFile.Path] {
+			continue
+		}
+
+		entries = append(entries, DownloadVerifyEntry{
+			Path:      localFile.Path,
+			Result:    DownloadVerifyResultExtra,
+			LocalPath: localFile.LocalPath,
+			LocalSize: localFile.Size,
+		})
+	}
+
+	sort.SliceStable(entries, func(i int, j int) bool {
+		return entries[i].Path < entries[j].Path
+	})
+
+	return entries, nil
+}
@@ -0,0 +1,99 @@
+package mdrepo
+
+import (
+	"crypto/md5"
+	"os"
+	"path/filepath"
+	"testing"
+
+	irodsclient_irodsfs "github.com/cyverse/go-irodsclient/irods/fs"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDownloadVerify(t *testing.T) {
+	t.Run("test FindDownloadedFiles", testFindDownloadedFiles)
+	t.Run("test VerifyDownloadedFiles", testVerifyDownloadedFiles)
+}
+
+func testFindDownloadedFiles(t *testing.T) {
+	dirPath := t.TempDir()
+
+	files := map[string]string{
+		"top.pdb":      "top",
+		"sub/run1.xtc": "run1",
+		"sub/run2.xtc": "run2",
+	}
+
+	for relPath, content := range files {
+		localPath := filepath.Join(dirPath, filepath.FromSlash(relPath))
+		assert.NoError(t, os.MkdirAll(filepath.Dir(localPath), 0755))
+		assert.NoError(t, os.WriteFile(localPath, []byte(content), 0644))
+	}
+
+	// run2.xtc is being downloaded
+	statusFilePath := irodsclient_irodsfs.GetDataObjectTransferStatusFilePath(filepath.Join(dirPath, "sub", "run2.xtc"))
+	assert.NoError(t, os.WriteFile(statusFilePath, []byte("{}"), 0644))
+
+	localFiles, err := FindDownloadedFiles(dirPath)
+	assert.NoError(t, err)
+	assert.Len(t, localFiles, 3)
+
+	incomplete := map[string]bool{}
+	for _, localFile := range localFiles {
+		incomplete[localFile.Path] = localFile.Incomplete
+		assert.Equal(t, int64(len(files[localFile.Path])), localFile.Size)
+	}
+
+	assert.Equal(t, map[string]bool{"top.pdb": false, "sub/run1.xtc": false, "sub/run2.xtc": true}, incomplete)
+}
+
+func testVerifyDownloadedFiles(t *testing.T) {
+	md5Of := func(content string) []byte {
+		hash := md5.Sum([]byte(content))
+		return hash[:]
+	}
+
+	// local files are named after their content
+	hashFunc := func(localPath string, algorithm string) ([]byte, error) {
+		return md5Of(localPath), nil
+	}
+
+	localFiles := []DownloadVerifyLocalFile{
+		{Path: "top.pdb", LocalPath: "top", Size: 30},
+		{Path: "run1.xtc", LocalPath: "run1", Size: 100},
+		{Path: "run2.xtc", LocalPath: "run2", Size: 200},
+		{Path: "run3.xtc", LocalPath: "run3", Size: 300, Incomplete: true},
+		{Path: "md.mdp", LocalPath: "mdp", Size: 5},
+		{Path: "notes.txt", LocalPath: "notes", Size: 7},
+	}
+
+	remoteFiles := []DownloadVerifyRemoteFile{
+		{Path: "top.pdb", IRODSPath: "/release/MDR1/top.pdb", Size: 30, ChecksumAlgorithm: "MD5", Checksum: md5Of("top")},
+		{Path: "run1.xtc", IRODSPath: "/release/MDR1/run1.xtc", Size: 150, ChecksumAlgorithm: "MD5", Checksum: md5Of("run1")},
+		{Path: "run2.xtc", IRODSPath: "/release/MDR1/run2.xtc", Size: 200, ChecksumAlgorithm: "MD5", Checksum: md5Of("other")},
+		{Path: "run3.xtc", IRODSPath: "/release/MDR1/run3.xtc", Size: 300, ChecksumAlgorithm: "MD5", Checksum: md5Of("run3")},
+		{Path: "md.mdp", IRODSPath: "/release/MDR1/md.mdp", Size: 5},
+		{Path: "struct.gro", IRODSPath: "/release/MDR1/struct.gro", Size: 6},
+	}
+
+	entries, err := VerifyDownloadedFiles(localFiles, remoteFiles, hashFunc)
+	assert.NoError(t, err)
+
+	results := map[string]string{}
+	for _, entry := range entries {
+		results[entry.Path] = string(entry.Result) + ":" + entry.Reason
+	}
+
+	assert.Equal(t, map[string]string{
+		"md.mdp":     "unverified:no checksum",
+		"notes.txt":  "extra:",
+		"run1.xtc":   "corrupt:size mismatch",
+		"run2.xtc":   "corrupt:checksum mismatch",
+		"run3.xtc":   "corrupt:incomplete download",
+		"struct.gro": "missing:",
+	}, results)
+
+	assert.True(t, DownloadVerifyResultMissing.IsDamaged())
+	assert.True(t, DownloadVerifyResultCorrupt.IsDamaged())
+	assert.False(t, DownloadVerifyResultExtra.IsDamaged())
+}
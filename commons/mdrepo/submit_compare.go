@@ -0,0 +1,138 @@
+package mdrepo
+
+import (
+	"bytes"
+	"encoding/hex"
+	"sort"
+
+	"github.com/cockroachdb/errors"
+)
+
+// SubmitCompareResult is a result of comparing a local file with a submitted file
+type SubmitCompareResult string
+
+const (
+	// SubmitCompareResultMissing is for a local file not found in the landing collection
+	SubmitCompareResultMissing SubmitCompareResult = "missing"
+	// SubmitCompareResultExtra is for a submitted file not found in local
+	SubmitCompareResultExtra SubmitCompareResult = "extra"
+	// SubmitCompareResultSizeMismatch is for files having different sizes
+	SubmitCompareResultSizeMismatch SubmitCompareResult = "size mismatch"
+	// SubmitCompareResultChecksumMismatch is for files having different checksums
+	SubmitCompareResultChecksumMismatch SubmitCompareResult = "checksum mismatch"
+	// SubmitCompareResultUnverified is for files having the same size, but checksum is not available in iRODS
+	SubmitCompareResultUnverified SubmitCompareResult = "unverified"
+)
+
+// IsDifference returns true if files differ
+func (result SubmitCompareResult) IsDifference() bool {
+	return result != SubmitCompareResultUnverified
+}
+
+// SubmitCompareLocalFile is a local file to be compared
+type SubmitCompareLocalFile struct {
+	Path      string // relative to the simulation dir, with '/' as a separator
+	LocalPath string
+	Size      int64
+}
+
+// SubmitCompareRemoteFile is a submitted file to be compared
+type SubmitCompareRemoteFile struct {
+	Path              string // relative to the landing collection
+	IRODSPath         string
+	Size              int64
+	ChecksumAlgorithm string
+	Checksum          []byte
+}
+
+// SubmitCompareEntry is a file that does not match
+type SubmitCompareEntry struct {
+	Path              string
+	Result            SubmitCompareResult
+	LocalPath         string
+	LocalSize         int64
+	LocalChecksum     string
+	IRODSPath         string
+	IRODSSize         int64
+	IRODSChecksum     string
+	ChecksumAlgorithm string
+}
+
+// SubmitCompareHashFunc computes a hash of a local file with the given algorithm
+type SubmitCompareHashFunc func(localPath string, algorithm string) ([]byte, error)
+
+// CompareSubmissionFiles compares local files with submitted files by names, sizes and checksums
+// returns files not matching, sorted by path
+func CompareSubmissionFiles(localFiles []SubmitCompareLocalFile, remoteFiles []SubmitCompareRemoteFile, hashFunc SubmitCompareHashFunc) ([]SubmitCompareEntry, error) {
+	remoteFileMap := map[string]SubmitCompareRemoteFile{}
+	for _, remoteFile := range remoteFiles {
+		remoteFileMap[remoteFile.Path] = remoteFile
+	}
+
+	entries := []SubmitCompareEntry{}
+	localFileMap := map[string]bool{}
+
+	for _, localFile := range localFiles {
+		localFileMap[localFile.Path] = true
+
+		entry := SubmitCompareEntry{
+			Path:      localFile.Path,
+			LocalPath: localFile.LocalPath,
+			LocalSize: localFile.Size,
+		}
+
+		remoteFile, ok := remoteFileMap[localFile.Path]
+		if !ok {
+			entry.Result = SubmitCompareResultMissing
+			entries = append(entries, entry)
+			continue
+		}
+
+		entry.IRODSPath = remoteFile.IRODSPath
+		entry.IRODSSize = remoteFile.Size
+
+		if localFile.Size != remoteFile.Size {
+			entry.Result = SubmitCompareResultSizeMismatch
+			entries = append(entries, entry)
+			continue
+		}
+
+		if len(remoteFile.Checksum) == 0 {
+			entry.Result = SubmitCompareResultUnverified
+			entries = append(entries, entry)
+			continue
+		}
+
+		localChecksum, err := hashFunc(localFile.LocalPath, remoteFile.ChecksumAlgorithm)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get hash of %q", localFile.LocalPath)
+		}
+
+		if !bytes.Equal(localChecksum, remoteFile.Checksum) {
+			entry.Result = SubmitCompareResultChecksumMismatch
+			entry.ChecksumAlgorithm = remoteFile.ChecksumAlgorithm
+			entry.LocalChecksum = hex.EncodeToString(localChecksum)
+			entry.IRODSChecksum = hex.EncodeToString(remoteFile.Checksum)
+			entries = append(entries, entry)
+		}
+	}
+
+	for _, remoteFile := range remoteFiles {
+		if localFileMap[remoteFile.Path] {
+			continue
+		}
+
+		entries = append(entries, SubmitCompareEntry{
+			Path:      remoteFile.Path,
+			Result:    SubmitCompareResultExtra,
+			IRODSPath: remoteFile.IRODSPath,
+			IRODSSize: remoteFile.Size,
+		})
+	}
+
+	sort.SliceStable(entries, func(i int, j int) bool {
+		return entries[i].Path < entries[j].Path
+	})
+
+	return entries, nil
+}
@@ -0,0 +1,76 @@
+package mdrepo
+
+import (
+	"crypto/md5"
+	"testing"
+
+	"github.com/cockroachdb/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSubmitCompare(t *testing.T) {
+	t.Run("test CompareSubmissionFiles", testCompareSubmissionFiles)
+}
+
+func testCompareSubmissionFiles(t *testing.T) {
+	md5Of := func(content string) []byte {
+		hash := md5.Sum([]byte(content))
+		return hash[:]
+	}
+
+	// local files are named after their content
+	hashFunc := func(localPath string, algorithm string) ([]byte, error) {
+		if algorithm != "MD5" {
+			return nil, errors.Errorf("unexpected algorithm %q", algorithm)
+		}
+		return md5Of(localPath), nil
+	}
+
+	localFiles := []SubmitCompareLocalFile{
+		{Path: "mdrepo-metadata.toml", LocalPath: "metadata", Size: 10},
+		{Path: "run1.xtc", LocalPath: "run1", Size: 100},
+		{Path: "sub/run2.xtc", LocalPath: "run2", Size: 200},
+		{Path: "top.pdb", LocalPath: "top", Size: 30},
+		{Path: "md.mdp", LocalPath: "mdp", Size: 5},
+		{Path: "struct.gro", LocalPath: "gro", Size: 6},
+	}
+
+	remoteFiles := []SubmitCompareRemoteFile{
+		{Path: "mdrepo-metadata.toml", IRODSPath: "/landing/MDR1/mdrepo-metadata.toml", Size: 10, ChecksumAlgorithm: "MD5", Checksum: md5Of("metadata")},
+		{Path: "run1.xtc", IRODSPath: "/landing/MDR1/run1.xtc", Size: 50, ChecksumAlgorithm: "MD5", Checksum: md5Of("run1")},
+		{Path: "sub/run2.xtc", IRODSPath: "/landing/MDR1/sub/run2.xtc", Size: 200, ChecksumAlgorithm: "MD5", Checksum: md5Of("corrupt")},
+		{Path: "md.mdp", IRODSPath: "/landing/MDR1/md.mdp", Size: 5},
+		{Path: "struct.gro", IRODSPath: "/landing/MDR1/struct.gro", Size: 6, ChecksumAlgorithm: "MD5", Checksum: md5Of("gro")},
+		{Path: "old.xtc", IRODSPath: "/landing/MDR1/old.xtc", Size: 7},
+	}
+
+	entries, err := CompareSubmissionFiles(localFiles, remoteFiles, hashFunc)
+	assert.NoError(t, err)
+
+	results := map[string]SubmitCompareResult{}
+	for _, entry := range entries {
+		results[entry.Path] = entry.Result
+	}
+
+	assert.Equal(t, map[string]SubmitCompareResult{
+		"md.mdp":       SubmitCompareResultUnverified,
+		"old.xtc":      SubmitCompareResultExtra,
+		"run1.xtc":     SubmitCompareResultSizeMismatch,
+		"sub/run2.xtc": SubmitCompareResultChecksumMismatch,
+		"top.pdb":      SubmitCompareResultMissing,
+	}, results)
+
+	// sorted by path
+	assert.Equal(t, "md.mdp", entries[0].Path)
+	assert.Equal(t, "top.pdb", entries[len(entries)-1].Path)
+
+	for _, entry := range entries {
+		if entry.Path == "sub/run2.xtc" {
+			assert.NotEqual(t, entry.LocalChecksum, entry.IRODSChecksum)
+			assert.Equal(t, "MD5", entry.ChecksumAlgorithm)
+		}
+	}
+
+	assert.False(t, SubmitCompareResultUnverified.IsDifference())
+	assert.True(t, SubmitCompareResultExtra.IsDifference())
+}
@@ -0,0 +1,204 @@
+package mdrepo
+
+import (
+	"bufio"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/cockroachdb/errors"
+)
+
+const (
+	SubmissionIgnoreFilename string = ".mdrepoignore"
+)
+
+type submissionIgnoreRule struct {
+	pattern  string
+	regex    *regexp.Regexp
+	negate   bool
+	onlyDirs bool
+}
+
+// SubmissionIgnore matches files against gitignore-style patterns in .mdrepoignore
+type SubmissionIgnore struct {
+	rules []submissionIgnoreRule
+}
+
+// NewSubmissionIgnore creates SubmissionIgnore from lines of gitignore-style patterns
+func NewSubmissionIgnore(lines []string) (*SubmissionIgnore, error) {
+	ignore := &SubmissionIgnore{
+		rules: []submissionIgnoreRule{},
+	}
+
+	for _, line := range lines {
+		line = strings.TrimRight(line, "\r")
+
+		// trailing spaces are ignored unless escaped
+		if !strings.HasSuffix(line, "\\ ") {
+			line = strings.TrimRight(line, " \t")
+		}
+
+		if len(line) == 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		rule := submissionIgnoreRule{
+			pattern: line,
+		}
+
+		if strings.HasPrefix(line, "!") {
+			rule.negate = true
+			line = line[1:]
+		} else if strings.HasPrefix(line, "\\!") || strings.HasPrefix(line, "\\#") {
+			line = line[1:]
+		}
+
+		if strings.HasSuffix(line, "/") {
+			rule.onlyDirs = true
+			line = strings.TrimRight(line, "/")
+		}
+
+		// a pattern with a slash is relative to the directory having .mdrepoignore
+		anchored := strings.Contains(line, "/")
+		line = strings.TrimPrefix(line, "/")
+
+		if len(line) == 0 {
+			continue
+		}
+
+		regex, err := compileSubmissionIgnorePattern(line, anchored)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to compile pattern %q", rule.pattern)
+		}
+
+		rule.regex = regex
+		ignore.rules = append(ignore.rules, rule)
+	}
+
+	return ignore, nil
+}
+
+// LoadSubmissionIgnore reads .mdrepoignore in the given dir, returns an empty SubmissionIgnore if not exist
+func LoadSubmissionIgnore(dirPath string) (*SubmissionIgnore, error) {
+	ignorePath := filepath.Join(dirPath, SubmissionIgnoreFilename)
+
+	ignoreFile, err := os.Open(ignorePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return NewSubmissionIgnore(nil)
+		}
+
+		return nil, errors.Wrapf(err, "failed to open %q", ignorePath)
+	}
+	defer ignoreFile.Close()
+
+	lines := []string{}
+	scanner := bufio.NewScanner(ignoreFile)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Wrapf(err, "failed to read %q", ignorePath)
+	}
+
+	ignore, err := NewSubmissionIgnore(lines)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse %q", ignorePath)
+	}
+
+	return ignore, nil
+}
+
+// IsIgnored returns true if the given path relative to the simulation dir is ignored
+// a path is also ignored if any of its parent directories is ignored
+func (ignore *SubmissionIgnore) IsIgnored(relPath string, isDir bool) bool {
+	relPath = path.Clean(filepath.ToSlash(relPath))
+
+	parts := strings.Split(relPath, "/")
+	for i := 1; i < len(parts); i++ {
+		if ignore.match(strings.Join(parts[:i], "/"), true) {
+			return true
+		}
+	}
+
+	return ignore.match(relPath, isDir)
+}
+
+func (ignore *SubmissionIgnore) match(relPath string, isDir bool) bool {
+	ignored := false
+
+	// the last matching rule wins
+	for _, rule := range ignore.rules {
+		if rule.onlyDirs && !isDir {
+			continue
+		}
+
+		if rule.regex.MatchString(relPath) {
+			ignored = !rule.negate
+		}
+	}
+
+	return ignored
+}
+
+// compileSubmissionIgnorePattern converts a gitignore-style glob pattern to regex
+func compileSubmissionIgnorePattern(pattern string, anchored bool) (*regexp.Regexp, error) {
+	sb := strings.Builder{}
+	sb.WriteString("^")
+	if !anchored {
+		// match at any level
+		sb.WriteString("(?:.*/)?")
+	}
+
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+
+		switch c {
+		case '*':
+			if i+1 < len(pattern) && pattern[i+1] == '*' {
+				// '**'
+				i++
+				if i+1 < len(pattern) && pattern[i+1] == '/' {
+					// '**/' matches zero or more directories
+					i++
+					sb.WriteString("(?:.*/)?")
+				} else {
+					sb.WriteString(".*")
+				}
+			} else {
+				sb.WriteString("[^/]*")
+			}
+		case '?':
+			sb.WriteString("[^/]")
+		case '[':
+			end := strings.IndexByte(pattern[i+1:], ']')
+			if end < 0 {
+				sb.WriteString(regexp.QuoteMeta(string(c)))
+				continue
+			}
+
+			class := pattern[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+
+			sb.WriteString("[" + class + "]")
+			i += end + 1
+		case '\\':
+			if i+1 < len(pattern) {
+				i++
+				sb.WriteString(regexp.QuoteMeta(string(pattern[i])))
+			}
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+
+	sb.WriteString("$")
+
+	return regexp.Compile(sb.String())
+}
@@ -0,0 +1,51 @@
+package mdrepo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSubmitIgnore(t *testing.T) {
+	t.Run("test SubmissionIgnore", testSubmissionIgnore)
+}
+
+func testSubmissionIgnore(t *testing.T) {
+	ignore, err := NewSubmissionIgnore([]string{
+		"# comment",
+		"",
+		"*.log",
+		"!keep.log",
+		"scratch/",
+		"/top.txt",
+		"analysis/**/*.tmp",
+		"**/cache",
+		"data?.bin",
+	})
+	assert.NoError(t, err)
+
+	assert.True(t, ignore.IsIgnored("run.log", false))
+	assert.True(t, ignore.IsIgnored("inputs/run.log", false))
+	assert.False(t, ignore.IsIgnored("keep.log", false))
+	assert.False(t, ignore.IsIgnored("inputs/keep.log", false))
+
+	assert.True(t, ignore.IsIgnored("scratch", true))
+	assert.False(t, ignore.IsIgnored("scratch", false))
+	assert.True(t, ignore.IsIgnored("scratch/a.xtc", false))
+	assert.True(t, ignore.IsIgnored("inputs/scratch/a.xtc", false))
+
+	assert.True(t, ignore.IsIgnored("top.txt", false))
+	assert.False(t, ignore.IsIgnored("inputs/top.txt", false))
+
+	assert.True(t, ignore.IsIgnored("analysis/a.tmp", false))
+	assert.True(t, ignore.IsIgnored("analysis/x/y/a.tmp", false))
+	assert.False(t, ignore.IsIgnored("other/a.tmp", false))
+
+	assert.True(t, ignore.IsIgnored("cache/file", false))
+	assert.True(t, ignore.IsIgnored("a/b/cache", true))
+
+	assert.True(t, ignore.IsIgnored("data1.bin", false))
+	assert.False(t, ignore.IsIgnored("data10.bin", false))
+
+	assert.False(t, ignore.IsIgnored("run.xtc", false))
+}
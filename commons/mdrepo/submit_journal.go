@@ -0,0 +1,286 @@
+package mdrepo
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cockroachdb/errors"
+)
+
+const (
+	SubmitJournalFilename string = "submit-journal.json"
+)
+
+// SubmitJournalFileState is a state of a file in the submit journal
+type SubmitJournalFileState string
+
+const (
+	// SubmitJournalFileStatePending is for a file not uploaded yet
+	SubmitJournalFileStatePending SubmitJournalFileState = "pending"
+	// SubmitJournalFileStateUploaded is for a file uploaded, but not verified
+	SubmitJournalFileStateUploaded SubmitJournalFileState = "uploaded"
+	// SubmitJournalFileStateVerified is for a file uploaded and its checksum is verified
+	SubmitJournalFileStateVerified SubmitJournalFileState = "verified"
+)
+
+// IsDone returns true if the file does not need to be uploaded again
+func (state SubmitJournalFileState) IsDone() bool {
+	return state == SubmitJournalFileStateUploaded || state == SubmitJournalFileStateVerified
+}
+
+// SubmitJournalFile is a file recorded in the submit journal
+type SubmitJournalFile struct {
+	Path      string                 `json:"path"` // relative to the submission root path
+	IRODSPath string                 `json:"irods_path"`
+	Size      int64                  `json:"size"`
+	ModTime   time.Time              `json:"mod_time"`
+	MD5Hash   string                 `json:"md5_hash"`
+	State     SubmitJournalFileState `json:"state"`
+	UpdatedAt time.Time              `json:"updated_at"`
+}
+
+// SubmitJournal records progress of submission locally to resume after crash
+type SubmitJournal struct {
+	RootPath         string                        `json:"-"`
+	TokenFingerprint string                        `json:"token_fingerprint"`
+	Mapping          map[string]string             `json:"mapping"` // simulation dir -> submission ID
+	Files            map[string]*SubmitJournalFile `json:"files"`   // path -> file
+	CreatedAt        time.Time                     `json:"created_at"`
+	UpdatedAt        time.Time                     `json:"updated_at"`
+
+	mutex     sync.Mutex
+	saveMutex sync.Mutex // serializes writes so an older state never overwrites a newer one
+}
+
+// GetTokenFingerprint returns a fingerprint identifying the token without revealing it
+func GetTokenFingerprint(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:8])
+}
+
+// GetSubmitJournalPath returns the path of the journal file under the submission root path
+func GetSubmitJournalPath(rootPath string) string {
+	return filepath.Join(rootPath, SubmitStateDirname, SubmitJournalFilename)
+}
+
+// NewSubmitJournal creates a new SubmitJournal
+func NewSubmitJournal(rootPath string, tokenFingerprint string, mapping *SubmitMapping) *SubmitJournal {
+	now := time.Now().UTC()
+
+	simulations := map[string]string{}
+	if mapping != nil {
+		for relPath, submissionID := range mapping.Simulations {
+			simulations[relPath] = submissionID
+		}
+	}
+
+	return &SubmitJournal{
+		RootPath:         rootPath,
+		TokenFingerprint: tokenFingerprint,
+		Mapping:          simulations,
+		Files:            map[string]*SubmitJournalFile{},
+		CreatedAt:        now,
+		UpdatedAt:        now,
+	}
+}
+
+// LoadSubmitJournal loads the journal under the submission root path, returns nil if not exist
+func LoadSubmitJournal(rootPath string) (*SubmitJournal, error) {
+	journalPath := GetSubmitJournalPath(rootPath)
+
+	journalBytes, err := os.ReadFile(journalPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+
+		return nil, errors.Wrapf(err, "failed to read journal %q", journalPath)
+	}
+
+	journal := &SubmitJournal{}
+	err = json.Unmarshal(journalBytes, journal)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse journal %q", journalPath)
+	}
+
+	journal.RootPath = rootPath
+	if journal.Mapping == nil {
+		journal.Mapping = map[string]string{}
+	}
+
+	if journal.Files == nil {
+		journal.Files = map[string]*SubmitJournalFile{}
+	}
+
+	return journal, nil
+}
+
+// IsCompatible returns true if the journal is made with the same token and mapping
+func (journal *SubmitJournal) IsCompatible(tokenFingerprint string, mapping *SubmitMapping) bool {
+	journal.mutex.Lock()
+	defer journal.mutex.Unlock()
+
+	if journal.TokenFingerprint != tokenFingerprint {
+		return false
+	}
+
+	if mapping == nil {
+		return len(journal.Mapping) == 0
+	}
+
+	if len(journal.Mapping) != len(mapping.Simulations) {
+		return false
+	}
+
+	for relPath, submissionID := range mapping.Simulations {
+		if journal.Mapping[relPath] != submissionID {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (journal *SubmitJournal) getRelPath(localPath string) (string, error) {
+	absLocalPath, err := filepath.Abs(localPath)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to get absolute path for %q", localPath)
+	}
+
+	relPath, err := filepath.Rel(journal.RootPath, absLocalPath)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to get relative path for %q", localPath)
+	}
+
+	relPath = path.Clean(filepath.ToSlash(relPath))
+	if relPath == ".." || strings.HasPrefix(relPath, "../") {
+		return "", errors.Errorf("file %q is not under %q", localPath, journal.RootPath)
+	}
+
+	return relPath, nil
+}
+
+// IsDone returns true if the file was uploaded and is unchanged since
+func (journal *SubmitJournal) IsDone(localPath string, irodsPath string, stat os.FileInfo, md5Hash string) bool {
+	relPath, err := journal.getRelPath(localPath)
+	if err != nil {
+		return false
+	}
+
+	journal.mutex.Lock()
+	defer journal.mutex.Unlock()
+
+	file, ok := journal.Files[relPath]
+	if !ok {
+		return false
+	}
+
+	if file.IRODSPath != irodsPath || file.Size != stat.Size() || !file.ModTime.Equal(stat.ModTime()) || file.MD5Hash != md5Hash {
+		return false
+	}
+
+	return file.State.IsDone()
+}
+
+// AddPending records the file to be uploaded, replacing the previous record
+func (journal *SubmitJournal) AddPending(localPath string, irodsPath string, stat os.FileInfo, md5Hash string) error {
+	relPath, err := journal.getRelPath(localPath)
+	if err != nil {
+		return err
+	}
+
+	journal.mutex.Lock()
+	defer journal.mutex.Unlock()
+
+	now := time.Now().UTC()
+	journal.UpdatedAt = now
+
+	journal.Files[relPath] = &SubmitJournalFile{
+		Path:      relPath,
+		IRODSPath: irodsPath,
+		Size:      stat.Size(),
+		ModTime:   stat.ModTime(),
+		MD5Hash:   md5Hash,
+		State:     SubmitJournalFileStatePending,
+		UpdatedAt: now,
+	}
+
+	return nil
+}
+
+// SetState sets the state of the file recorded
+func (journal *SubmitJournal) SetState(localPath string, state SubmitJournalFileState) error {
+	relPath, err := journal.getRelPath(localPath)
+	if err != nil {
+		return err
+	}
+
+	journal.mutex.Lock()
+	defer journal.mutex.Unlock()
+
+	file, ok := journal.Files[relPath]
+	if !ok {
+		return errors.Errorf("file %q is not in the journal", relPath)
+	}
+
+	now := time.Now().UTC()
+	file.State = state
+	file.UpdatedAt = now
+	journal.UpdatedAt = now
+
+	return nil
+}
+
+// GetFiles returns files recorded, sorted by path
+func (journal *SubmitJournal) GetFiles() []*SubmitJournalFile {
+	journal.mutex.Lock()
+	defer journal.mutex.Unlock()
+
+	files := make([]*SubmitJournalFile, 0, len(journal.Files))
+	for _, file := range journal.Files {
+		fileCopy := *file
+		files = append(files, &fileCopy)
+	}
+
+	sort.Slice(files, func(i int, j int) bool {
+		return files[i].Path < files[j].Path
+	})
+
+	return files
+}
+
+// GetRemainingFiles returns files not uploaded yet, sorted by path
+func (journal *SubmitJournal) GetRemainingFiles() []*SubmitJournalFile {
+	remainingFiles := []*SubmitJournalFile{}
+	for _, file := range journal.GetFiles() {
+		if !file.State.IsDone() {
+			remainingFiles = append(remainingFiles, file)
+		}
+	}
+
+	return remainingFiles
+}
+
+// Save writes the journal under the submission root path
+func (journal *SubmitJournal) Save() error {
+	journal.saveMutex.Lock()
+	defer journal.saveMutex.Unlock()
+
+	journal.mutex.Lock()
+	journalBytes, err := json.MarshalIndent(journal, "", "  ")
+	journal.mutex.Unlock()
+
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal journal")
+	}
+
+	return writeSubmitStateFile(GetSubmitJournalPath(journal.RootPath), journalBytes)
+}
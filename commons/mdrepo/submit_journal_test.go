@@ -0,0 +1,85 @@
+package mdrepo
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSubmitJournal(t *testing.T) {
+	t.Run("test JournalState", testJournalState)
+	t.Run("test JournalSaveAndLoad", testJournalSaveAndLoad)
+}
+
+func testJournalState(t *testing.T) {
+	rootPath := t.TempDir()
+	filePath := filepath.Join(rootPath, "sim1", "run.xtc")
+
+	err := os.MkdirAll(filepath.Dir(filePath), 0755)
+	assert.NoError(t, err)
+
+	err = os.WriteFile(filePath, []byte("run"), 0644)
+	assert.NoError(t, err)
+
+	stat, err := os.Stat(filePath)
+	assert.NoError(t, err)
+
+	journal := NewSubmitJournal(rootPath, GetTokenFingerprint("token"), nil)
+	assert.False(t, journal.IsDone(filePath, "/landing/MDR1/run.xtc", stat, "hash"))
+
+	assert.NoError(t, journal.AddPending(filePath, "/landing/MDR1/run.xtc", stat, "hash"))
+	assert.False(t, journal.IsDone(filePath, "/landing/MDR1/run.xtc", stat, "hash"))
+	assert.Len(t, journal.GetRemainingFiles(), 1)
+	assert.Equal(t, "sim1/run.xtc", journal.GetRemainingFiles()[0].Path)
+
+	assert.NoError(t, journal.SetState(filePath, SubmitJournalFileStateVerified))
+	assert.True(t, journal.IsDone(filePath, "/landing/MDR1/run.xtc", stat, "hash"))
+	assert.Len(t, journal.GetRemainingFiles(), 0)
+
+	// changed file must be uploaded again
+	assert.False(t, journal.IsDone(filePath, "/landing/MDR1/run.xtc", stat, "otherhash"))
+	assert.False(t, journal.IsDone(filePath, "/landing/MDR2/run.xtc", stat, "hash"))
+
+	assert.Error(t, journal.SetState(filepath.Join(rootPath, "unknown"), SubmitJournalFileStateUploaded))
+}
+
+func testJournalSaveAndLoad(t *testing.T) {
+	rootPath := t.TempDir()
+
+	journal, err := LoadSubmitJournal(rootPath)
+	assert.NoError(t, err)
+	assert.Nil(t, journal)
+
+	mapping, err := NewSubmitMappingByOrder(rootPath, []string{filepath.Join(rootPath, "sim1")}, makeTestTickets("MDR00000001"))
+	assert.NoError(t, err)
+
+	filePath := filepath.Join(rootPath, "sim1.xtc")
+	err = os.WriteFile(filePath, []byte("run"), 0644)
+	assert.NoError(t, err)
+
+	stat, err := os.Stat(filePath)
+	assert.NoError(t, err)
+
+	journal = NewSubmitJournal(rootPath, GetTokenFingerprint("token"), mapping)
+	assert.NoError(t, journal.AddPending(filePath, "/landing/sim1.xtc", stat, "hash"))
+	assert.NoError(t, journal.SetState(filePath, SubmitJournalFileStateUploaded))
+	assert.NoError(t, journal.Save())
+
+	loadedJournal, err := LoadSubmitJournal(rootPath)
+	assert.NoError(t, err)
+	assert.True(t, loadedJournal.IsDone(filePath, "/landing/sim1.xtc", stat, "hash"))
+
+	assert.True(t, loadedJournal.IsCompatible(GetTokenFingerprint("token"), mapping))
+	assert.False(t, loadedJournal.IsCompatible(GetTokenFingerprint("other token"), mapping))
+
+	otherMapping, err := NewSubmitMappingByOrder(rootPath, []string{filepath.Join(rootPath, "sim1")}, makeTestTickets("MDR00000002"))
+	assert.NoError(t, err)
+	assert.False(t, loadedJournal.IsCompatible(GetTokenFingerprint("token"), otherMapping))
+
+	// token is never stored
+	journalBytes, err := os.ReadFile(GetSubmitJournalPath(rootPath))
+	assert.NoError(t, err)
+	assert.NotContains(t, string(journalBytes), "\"token\"")
+}
@@ -0,0 +1,390 @@
+package mdrepo
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/MD-Repo/md-repo-cli/commons/types"
+	"github.com/cockroachdb/errors"
+)
+
+const (
+	SubmitStateDirname    string = ".mdrepo"
+	SubmitMappingFilename string = "submit-mapping.json"
+)
+
+// SubmitMapping ties simulation directories to submission IDs of tickets
+// directories are stored relative to the submission root path with '/' as a separator
+type SubmitMapping struct {
+	RootPath    string            `json:"-"`
+	Simulations map[string]string `json:"simulations"` // simulation dir -> submission ID (e.g., MDR00001234)
+}
+
+// GetSubmissionRootPath returns the common parent of the given paths, where submit state is stored
+func GetSubmissionRootPath(sourcePaths []string) (string, error) {
+	rootPath := ""
+	for _, sourcePath := range sourcePaths {
+		absSourcePath, err := filepath.Abs(sourcePath)
+		if err != nil {
+			return "", errors.Wrapf(err, "failed to get absolute path for %q", sourcePath)
+		}
+
+		if len(rootPath) == 0 {
+			rootPath = absSourcePath
+			continue
+		}
+
+		for {
+			relPath, err := filepath.Rel(rootPath, absSourcePath)
+			if err == nil && relPath != ".." && !strings.HasPrefix(relPath, ".."+string(filepath.Separator)) {
+				break
+			}
+
+			parentPath := filepath.Dir(rootPath)
+			if parentPath == rootPath {
+				break
+			}
+
+			rootPath = parentPath
+		}
+	}
+
+	if len(rootPath) == 0 {
+		return "", errors.Errorf("no source paths are given")
+	}
+
+	return rootPath, nil
+}
+
+// GetSubmitMappingPath returns the path of the mapping file persisted under the submission root path
+func GetSubmitMappingPath(rootPath string) string {
+	return filepath.Join(rootPath, SubmitStateDirname, SubmitMappingFilename)
+}
+
+// NewSubmitMapping creates an empty SubmitMapping
+func NewSubmitMapping(rootPath string) *SubmitMapping {
+	return &SubmitMapping{
+		RootPath:    rootPath,
+		Simulations: map[string]string{},
+	}
+}
+
+// NewSubmitMappingByOrder creates SubmitMapping pairing simulation dirs with tickets in order
+func NewSubmitMappingByOrder(rootPath string, sourcePaths []string, tickets []MDRepoTicket) (*SubmitMapping, error) {
+	if len(sourcePaths) != len(tickets) {
+		return nil, types.NewSubmitMappingError(GetSubmitMappingPath(rootPath), []string{
+			fmt.Sprintf("found %d simulations, but got %d tickets", len(sourcePaths), len(tickets)),
+		})
+	}
+
+	mapping := NewSubmitMapping(rootPath)
+	for idx, sourcePath := range sourcePaths {
+		err := mapping.Add(sourcePath, tickets[idx].GetSubmissionID())
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return mapping, nil
+}
+
+// ReadSubmitMappingFile reads a mapping file
+// the file is a JSON object of simulation dir to submission ID, or the format persisted by Save
+// relative simulation dirs are relative to rootPath
+func ReadSubmitMappingFile(mappingPath string, rootPath string) (*SubmitMapping, error) {
+	mappingBytes, err := os.ReadFile(mappingPath)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read mapping file %q", mappingPath)
+	}
+
+	simulations := map[string]string{}
+
+	persisted := SubmitMapping{}
+	err = json.Unmarshal(mappingBytes, &persisted)
+	if err == nil && persisted.Simulations != nil {
+		simulations = persisted.Simulations
+	} else {
+		err = json.Unmarshal(mappingBytes, &simulations)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to parse mapping file %q", mappingPath)
+		}
+	}
+
+	mapping := NewSubmitMapping(rootPath)
+	for sourcePath, submissionID := range simulations {
+		if !filepath.IsAbs(sourcePath) {
+			sourcePath = filepath.Join(rootPath, filepath.FromSlash(sourcePath))
+		}
+
+		err = mapping.Add(sourcePath, submissionID)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read mapping file %q", mappingPath)
+		}
+	}
+
+	return mapping, nil
+}
+
+// LoadSubmitMapping loads the mapping persisted under the submission root path, returns nil if not exist
+func LoadSubmitMapping(rootPath string) (*SubmitMapping, error) {
+	mappingPath := GetSubmitMappingPath(rootPath)
+
+	_, err := os.Stat(mappingPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+
+		return nil, errors.Wrapf(err, "failed to stat %q", mappingPath)
+	}
+
+	return ReadSubmitMappingFile(mappingPath, rootPath)
+}
+
+// GetSubmitMapping returns the mapping of simulation dirs to tickets
+// the mapping file given is used first, then the mapping persisted, otherwise dirs are paired with tickets in order
+// the persisted mapping is not used if ignorePersisted is set or none of its submissions are in the tickets, e.g., a new token is given
+// returns the persisted mapping being replaced, nil if it is used or not exist
+func GetSubmitMapping(rootPath string, mappingFilePath string, sourcePaths []string, tickets []MDRepoTicket, ignorePersisted bool) (*SubmitMapping, *SubmitMapping, error) {
+	persistedMapping, err := LoadSubmitMapping(rootPath)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "failed to load simulation mapping")
+	}
+
+	var mapping *SubmitMapping
+	if len(mappingFilePath) > 0 {
+		mapping, err = ReadSubmitMappingFile(mappingFilePath, rootPath)
+		if err != nil {
+			return nil, nil, err
+		}
+	} else if persistedMapping != nil && !ignorePersisted && persistedMapping.HasAnySubmission(tickets) {
+		return persistedMapping, nil, nil
+	} else {
+		mapping, err = NewSubmitMappingByOrder(rootPath, sourcePaths, tickets)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if persistedMapping != nil && !persistedMapping.Equal(mapping) {
+		return mapping, persistedMapping, nil
+	}
+
+	return mapping, nil, nil
+}
+
+// Add adds a simulation dir mapped to the submission ID
+func (mapping *SubmitMapping) Add(sourcePath string, submissionID string) error {
+	relPath, err := mapping.getRelPath(sourcePath)
+	if err != nil {
+		return err
+	}
+
+	submissionID = strings.TrimSpace(submissionID)
+	if len(submissionID) == 0 {
+		return errors.Errorf("submission ID for %q is empty", relPath)
+	}
+
+	if existingID, ok := mapping.Simulations[relPath]; ok && existingID != submissionID {
+		return errors.Errorf("simulation dir %q is mapped to both %q and %q", relPath, existingID, submissionID)
+	}
+
+	mapping.Simulations[relPath] = submissionID
+	return nil
+}
+
+func (mapping *SubmitMapping) getRelPath(sourcePath string) (string, error) {
+	absSourcePath, err := filepath.Abs(sourcePath)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to get absolute path for %q", sourcePath)
+	}
+
+	relPath, err := filepath.Rel(mapping.RootPath, absSourcePath)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to get relative path for %q", sourcePath)
+	}
+
+	relPath = path.Clean(filepath.ToSlash(relPath))
+	if relPath == ".." || strings.HasPrefix(relPath, "../") {
+		return "", errors.Errorf("simulation dir %q is not under %q", sourcePath, mapping.RootPath)
+	}
+
+	return relPath, nil
+}
+
+// Equal returns true if both map the same simulation dirs to the same submission IDs
+func (mapping *SubmitMapping) Equal(other *SubmitMapping) bool {
+	if len(mapping.Simulations) != len(other.Simulations) {
+		return false
+	}
+
+	for relPath, submissionID := range mapping.Simulations {
+		if other.Simulations[relPath] != submissionID {
+			return false
+		}
+	}
+
+	return true
+}
+
+// HasAnySubmission returns true if any of the submission IDs mapped is in the tickets
+func (mapping *SubmitMapping) HasAnySubmission(tickets []MDRepoTicket) bool {
+	submissionIDs := map[string]bool{}
+	for _, submissionID := range mapping.Simulations {
+		submissionIDs[submissionID] = true
+	}
+
+	for _, ticket := range tickets {
+		if submissionIDs[ticket.GetSubmissionID()] {
+			return true
+		}
+	}
+
+	return false
+}
+
+// GetDifferences returns human-readable differences from the mapping to the other (newer) mapping
+func (mapping *SubmitMapping) GetDifferences(other *SubmitMapping) []string {
+	relPaths := map[string]bool{}
+	for relPath := range mapping.Simulations {
+		relPaths[relPath] = true
+	}
+
+	for relPath := range other.Simulations {
+		relPaths[relPath] = true
+	}
+
+	describe := func(submissionID string, ok bool) string {
+		if !ok {
+			return "nothing"
+		}
+		return fmt.Sprintf("%q", submissionID)
+	}
+
+	differences := []string{}
+	for _, relPath := range sortedKeys(relPaths) {
+		submissionID, ok := mapping.Simulations[relPath]
+		otherSubmissionID, otherOk := other.Simulations[relPath]
+
+		if ok != otherOk || submissionID != otherSubmissionID {
+			differences = append(differences, fmt.Sprintf("simulation dir %q was mapped to %s, but now to %s", relPath, describe(submissionID, ok), describe(otherSubmissionID, otherOk)))
+		}
+	}
+
+	return differences
+}
+
+// ResolveTickets returns the ticket mapped to each simulation dir, in the same order as sourcePaths
+// returns SubmitMappingError if counts or mappings disagree
+func (mapping *SubmitMapping) ResolveTickets(sourcePaths []string, tickets []MDRepoTicket) ([]MDRepoTicket, error) {
+	problems := []string{}
+
+	if len(sourcePaths) != len(tickets) {
+		problems = append(problems, fmt.Sprintf("found %d simulations, but got %d tickets", len(sourcePaths), len(tickets)))
+	}
+
+	if len(mapping.Simulations) != len(sourcePaths) {
+		problems = append(problems, fmt.Sprintf("found %d simulations, but the mapping has %d simulations", len(sourcePaths), len(mapping.Simulations)))
+	}
+
+	ticketsByID := map[string]MDRepoTicket{}
+	for _, ticket := range tickets {
+		ticketsByID[ticket.GetSubmissionID()] = ticket
+	}
+
+	resolvedTickets := []MDRepoTicket{}
+	usedIDs := map[string]string{} // submission ID -> simulation dir
+	for _, sourcePath := range sourcePaths {
+		relPath, err := mapping.getRelPath(sourcePath)
+		if err != nil {
+			return nil, err
+		}
+
+		submissionID, ok := mapping.Simulations[relPath]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("simulation dir %q is not in the mapping", relPath))
+			continue
+		}
+
+		if usedRelPath, ok := usedIDs[submissionID]; ok {
+			problems = append(problems, fmt.Sprintf("simulation dirs %q and %q are mapped to the same submission %q", usedRelPath, relPath, submissionID))
+			continue
+		}
+
+		usedIDs[submissionID] = relPath
+
+		ticket, ok := ticketsByID[submissionID]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("simulation dir %q is mapped to submission %q, but no ticket is given for it", relPath, submissionID))
+			continue
+		}
+
+		resolvedTickets = append(resolvedTickets, ticket)
+	}
+
+	if len(problems) > 0 {
+		return nil, types.NewSubmitMappingError(GetSubmitMappingPath(mapping.RootPath), problems)
+	}
+
+	return resolvedTickets, nil
+}
+
+// Save persists the mapping under the submission root path
+func (mapping *SubmitMapping) Save() error {
+	mappingBytes, err := json.MarshalIndent(mapping, "", "  ")
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal mapping")
+	}
+
+	return writeSubmitStateFile(GetSubmitMappingPath(mapping.RootPath), mappingBytes)
+}
+
+// writeSubmitStateFile writes a file under the submit state dir atomically
+func writeSubmitStateFile(filePath string, data []byte) error {
+	dirPath := filepath.Dir(filePath)
+	err := os.MkdirAll(dirPath, 0755)
+	if err != nil {
+		return errors.Wrapf(err, "failed to make a directory %q", dirPath)
+	}
+
+	// write to a temp file and rename to avoid leaving a partial file
+	tempFile, err := os.CreateTemp(dirPath, filepath.Base(filePath)+".*.tmp")
+	if err != nil {
+		return errors.Wrapf(err, "failed to create temp file in %q", dirPath)
+	}
+
+	tempPath := tempFile.Name()
+	_, err = tempFile.Write(data)
+	closeErr := tempFile.Close()
+	if err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		os.Remove(tempPath)
+		return errors.Wrapf(err, "failed to write %q", tempPath)
+	}
+
+	err = os.Rename(tempPath, filePath)
+	if err != nil {
+		os.Remove(tempPath)
+		return errors.Wrapf(err, "failed to rename %q to %q", tempPath, filePath)
+	}
+
+	return nil
+}
+
+func sortedKeys(values map[string]bool) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+	return keys
+}
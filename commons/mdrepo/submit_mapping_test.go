@@ -0,0 +1,165 @@
+package mdrepo
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/MD-Repo/md-repo-cli/commons/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSubmitMapping(t *testing.T) {
+	t.Run("test GetSubmissionRootPath", testGetSubmissionRootPath)
+	t.Run("test ResolveTickets", testResolveTickets)
+	t.Run("test SaveAndLoad", testSubmitMappingSaveAndLoad)
+	t.Run("test GetSubmitMapping", testGetSubmitMapping)
+}
+
+func makeTestTickets(submissionIDs ...string) []MDRepoTicket {
+	tickets := []MDRepoTicket{}
+	for _, submissionID := range submissionIDs {
+		tickets = append(tickets, MDRepoTicket{
+			IRODSTicket:   "ticket_" + submissionID,
+			IRODSDataPath: "/zone/home/landing/" + submissionID,
+		})
+	}
+	return tickets
+}
+
+func testGetSubmissionRootPath(t *testing.T) {
+	rootPath, err := GetSubmissionRootPath([]string{"/data/sys/sim1", "/data/sys/sim2", "/data/other/sim3"})
+	assert.NoError(t, err)
+	assert.Equal(t, "/data", rootPath)
+
+	rootPath, err = GetSubmissionRootPath([]string{"/data/sys"})
+	assert.NoError(t, err)
+	assert.Equal(t, "/data/sys", rootPath)
+
+	_, err = GetSubmissionRootPath(nil)
+	assert.Error(t, err)
+}
+
+func testResolveTickets(t *testing.T) {
+	rootPath := "/data"
+	sourcePaths := []string{"/data/sim1", "/data/sim2"}
+	tickets := makeTestTickets("MDR00000001", "MDR00000002")
+
+	assert.Equal(t, "MDR00000001", tickets[0].GetSubmissionID())
+
+	// by order
+	mapping, err := NewSubmitMappingByOrder(rootPath, sourcePaths, tickets)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"sim1": "MDR00000001", "sim2": "MDR00000002"}, mapping.Simulations)
+
+	_, err = NewSubmitMappingByOrder(rootPath, sourcePaths, tickets[:1])
+	assert.True(t, types.IsSubmitMappingError(err))
+
+	// explicit mapping is independent of the order of tickets
+	mapping = NewSubmitMapping(rootPath)
+	assert.NoError(t, mapping.Add("/data/sim1", "MDR00000002"))
+	assert.NoError(t, mapping.Add("/data/sim2", "MDR00000001"))
+
+	mappedTickets, err := mapping.ResolveTickets(sourcePaths, tickets)
+	assert.NoError(t, err)
+	assert.Equal(t, []MDRepoTicket{tickets[1], tickets[0]}, mappedTickets)
+
+	// renamed dir
+	_, err = mapping.ResolveTickets([]string{"/data/sim1", "/data/sim2_renamed"}, tickets)
+	assert.True(t, types.IsSubmitMappingError(err))
+	assert.ErrorContains(t, err, "sim2_renamed")
+
+	// missing ticket
+	_, err = mapping.ResolveTickets(sourcePaths, makeTestTickets("MDR00000001", "MDR00000003"))
+	assert.True(t, types.IsSubmitMappingError(err))
+
+	// dir outside of the root
+	assert.Error(t, mapping.Add("/other/sim3", "MDR00000003"))
+}
+
+func testSubmitMappingSaveAndLoad(t *testing.T) {
+	rootPath := t.TempDir()
+
+	mapping, err := LoadSubmitMapping(rootPath)
+	assert.NoError(t, err)
+	assert.Nil(t, mapping)
+
+	mapping, err = NewSubmitMappingByOrder(rootPath, []string{filepath.Join(rootPath, "sim1"), filepath.Join(rootPath, "sim2")}, makeTestTickets("MDR00000001", "MDR00000002"))
+	assert.NoError(t, err)
+	assert.NoError(t, mapping.Save())
+
+	loadedMapping, err := LoadSubmitMapping(rootPath)
+	assert.NoError(t, err)
+	assert.True(t, mapping.Equal(loadedMapping))
+
+	// user-provided mapping file is a plain JSON object
+	userMappingPath := filepath.Join(rootPath, "mapping.json")
+	err = os.WriteFile(userMappingPath, []byte(`{"sim1": "MDR00000002", "sim2": "MDR00000001"}`), 0644)
+	assert.NoError(t, err)
+
+	userMapping, err := ReadSubmitMappingFile(userMappingPath, rootPath)
+	assert.NoError(t, err)
+	assert.False(t, mapping.Equal(userMapping))
+	assert.Equal(t, []string{
+		`simulation dir "sim1" was mapped to "MDR00000001", but now to "MDR00000002"`,
+		`simulation dir "sim2" was mapped to "MDR00000002", but now to "MDR00000001"`,
+	}, mapping.GetDifferences(userMapping))
+}
+
+func testGetSubmitMapping(t *testing.T) {
+	rootPath := t.TempDir()
+	sourcePaths := []string{filepath.Join(rootPath, "sim1"), filepath.Join(rootPath, "sim2")}
+	tickets := makeTestTickets("MDR00000001", "MDR00000002")
+
+	// by order, nothing persisted
+	mapping, replacedMapping, err := GetSubmitMapping(rootPath, "", sourcePaths, tickets, false)
+	assert.NoError(t, err)
+	assert.Nil(t, replacedMapping)
+	assert.Equal(t, map[string]string{"sim1": "MDR00000001", "sim2": "MDR00000002"}, mapping.Simulations)
+
+	persistedMapping := NewSubmitMapping(rootPath)
+	assert.NoError(t, persistedMapping.Add(sourcePaths[0], "MDR00000002"))
+	assert.NoError(t, persistedMapping.Add(sourcePaths[1], "MDR00000001"))
+	assert.NoError(t, persistedMapping.Save())
+
+	// persisted mapping is reused
+	mapping, replacedMapping, err = GetSubmitMapping(rootPath, "", sourcePaths, tickets, false)
+	assert.NoError(t, err)
+	assert.Nil(t, replacedMapping)
+	assert.True(t, persistedMapping.Equal(mapping))
+
+	// ignored
+	mapping, replacedMapping, err = GetSubmitMapping(rootPath, "", sourcePaths, tickets, true)
+	assert.NoError(t, err)
+	assert.True(t, persistedMapping.Equal(replacedMapping))
+	assert.Equal(t, map[string]string{"sim1": "MDR00000001", "sim2": "MDR00000002"}, mapping.Simulations)
+
+	// mapping file given replaces the persisted mapping
+	userMappingPath := filepath.Join(rootPath, "mapping.json")
+	err = os.WriteFile(userMappingPath, []byte(`{"sim1": "MDR00000001", "sim2": "MDR00000002"}`), 0644)
+	assert.NoError(t, err)
+
+	mapping, replacedMapping, err = GetSubmitMapping(rootPath, userMappingPath, sourcePaths, tickets, false)
+	assert.NoError(t, err)
+	assert.True(t, persistedMapping.Equal(replacedMapping))
+	assert.Equal(t, map[string]string{"sim1": "MDR00000001", "sim2": "MDR00000002"}, mapping.Simulations)
+
+	// new token, none of the persisted submissions match
+	newTickets := makeTestTickets("MDR00000003", "MDR00000004")
+	mapping, replacedMapping, err = GetSubmitMapping(rootPath, "", sourcePaths, newTickets, false)
+	assert.NoError(t, err)
+	assert.True(t, persistedMapping.Equal(replacedMapping))
+
+	mappedTickets, err := mapping.ResolveTickets(sourcePaths, newTickets)
+	assert.NoError(t, err)
+	assert.Equal(t, newTickets, mappedTickets)
+
+	// some persisted submissions match, so the persisted mapping is kept to report the disagreement
+	partialTickets := makeTestTickets("MDR00000001", "MDR00000003")
+	mapping, _, err = GetSubmitMapping(rootPath, "", sourcePaths, partialTickets, false)
+	assert.NoError(t, err)
+	assert.True(t, persistedMapping.Equal(mapping))
+
+	_, err = mapping.ResolveTickets(sourcePaths, partialTickets)
+	assert.True(t, types.IsSubmitMappingError(err))
+}
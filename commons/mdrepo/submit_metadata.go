@@ -3,8 +3,10 @@ package mdrepo
 import (
 	"encoding/json"
 	"io"
+	"io/fs"
 	"net/http"
 	"os"
+	"path"
 	"path/filepath"
 	"strings"
 
@@ -25,11 +27,68 @@ type MDRepoSubmitMetadata struct {
 	MetadataFilePath string `toml:"-"`
 	SubmissionPath   string `toml:"-"`
 
-	LeadContributorOrcid string              `toml:"lead_contributor_orcid"`
-	TrajectoryFileNames  []string            `toml:"trajectory_file_names"`
-	StructureFileName    string              `toml:"structure_file_name"`
-	TopologyFileName     string              `toml:"topology_file_name"`
-	AdditionalFiles      []map[string]string `toml:"additional_files"`
+	LeadContributorOrcid  string   `toml:"lead_contributor_orcid"`
+	ShortDescription      string   `toml:"short_description"`
+	SoftwareName          string   `toml:"software_name"`
+	SoftwareVersion       string   `toml:"software_version"`
+	ReplicateID           string   `toml:"replicate_id"`
+	PDBID                 string   `toml:"pdb_id"`
+	UniprotIDs            []string `toml:"uniprot_ids"`
+	Forcefield            string   `toml:"forcefield"`
+	ProtonationMethod     string   `toml:"protonation_method"`
+	TemperatureKelvin     float64  `toml:"temperature_kelvin"`
+	IntegrationTimeStepFs float64  `toml:"integration_time_step_fs"`
+
+	TrajectoryFileNames []string                     `toml:"trajectory_file_names"`
+	StructureFileName   string                       `toml:"structure_file_name"`
+	TopologyFileName    string                       `toml:"topology_file_name"`
+	AdditionalFiles     []MDRepoSubmitAdditionalFile `toml:"additional_files"`
+
+	Water        *MDRepoSubmitWater        `toml:"water"`
+	Ligands      []MDRepoSubmitMolecule    `toml:"ligands"`
+	Solutes      []MDRepoSubmitMolecule    `toml:"solutes"`
+	Contributors []MDRepoSubmitContributor `toml:"contributors"`
+	Papers       []MDRepoSubmitPaper       `toml:"papers"`
+
+	// keys found while decoding, used to check required and unknown fields
+	tomlMetadata toml.MetaData
+	tomlText     string
+	typeErrors   []SubmitMetadataTypeError
+}
+
+type MDRepoSubmitAdditionalFile struct {
+	FileType    string `toml:"file_type"`
+	FileName    string `toml:"file_name"`
+	Description string `toml:"description"`
+}
+
+type MDRepoSubmitWater struct {
+	Model             string  `toml:"model"`
+	Density           float64 `toml:"density"`
+	WaterDensityUnits string  `toml:"water_density_units"`
+}
+
+type MDRepoSubmitMolecule struct {
+	Name   string `toml:"name"`
+	Smiles string `toml:"smiles"`
+}
+
+type MDRepoSubmitContributor struct {
+	Name        string `toml:"name"`
+	Orcid       string `toml:"orcid"`
+	Email       string `toml:"email"`
+	Institution string `toml:"institution"`
+}
+
+type MDRepoSubmitPaper struct {
+	Title   string `toml:"title"`
+	Authors string `toml:"authors"`
+	Journal string `toml:"journal"`
+	Volume  int    `toml:"volume"`
+	Number  string `toml:"number"`
+	Year    int    `toml:"year"`
+	Pages   string `toml:"pages"`
+	DOI     string `toml:"doi"`
 }
 
 type MDRepoVerifySubmitMetadataRequest struct {
@@ -82,57 +141,103 @@ func ValidateSubmissionSourcePath(sourcePath string) error {
 		return errors.Errorf("source %q must have submit metadata", sourcePath)
 	}
 
-	entries, err := os.ReadDir(sourcePath)
+	// sub-directories are allowed, but not another simulation in them
+	err = filepath.WalkDir(sourcePath, func(entryPath string, entry fs.DirEntry, walkErr error) error {
+		if walkErr != nil {
+			return walkErr
+		}
+
+		if entry.IsDir() && entryPath != sourcePath && HasSubmitMetadataInDir(entryPath) {
+			return errors.Errorf("source %q has another submit metadata in sub-directory %q", sourcePath, entryPath)
+		}
+
+		return nil
+	})
 	if err != nil {
-		return errors.Wrapf(err, "Failed to readdir source %q", sourcePath)
+		return errors.Wrapf(err, "Failed to walk source %q", sourcePath)
+	}
+
+	return nil
+}
+
+// ValidateSubmitFilePath checks if the file path described in submit metadata is relative to the simulation dir
+// sub-directories must be separated by '/'
+func ValidateSubmitFilePath(filePath string) error {
+	if len(filePath) == 0 {
+		return errors.Errorf("file path is empty")
+	}
+
+	if strings.Contains(filePath, "\\") {
+		return errors.Errorf("file path %q must use '/' as a path separator", filePath)
 	}
 
-	for _, entry := range entries {
-		if entry.IsDir() {
-			// found dir
-			return types.NewNotFileError(filepath.Join(sourcePath, entry.Name()))
+	if path.IsAbs(filePath) || filepath.IsAbs(filePath) {
+		return errors.Errorf("file path %q must be relative to the simulation directory", filePath)
+	}
+
+	for _, part := range strings.Split(filePath, "/") {
+		if part == ".." {
+			return errors.Errorf("file path %q must not refer to a parent directory", filePath)
 		}
 	}
 
 	return nil
 }
 
+// GetSubmitFileLocalPath returns local path of the file described in submit metadata
+func (meta *MDRepoSubmitMetadata) GetSubmitFileLocalPath(filePath string) string {
+	return filepath.Join(meta.SubmissionPath, filepath.FromSlash(filePath))
+}
+
 func ParseSubmitMetadataFile(filePath string) (*MDRepoSubmitMetadata, error) {
-	metadata := MDRepoSubmitMetadata{
-		MetadataFilePath: filePath,
-		SubmissionPath:   filepath.Dir(filePath),
+	metadataBytes, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Failed to read submission metadata at %q", filePath)
 	}
 
-	_, err := toml.DecodeFile(filePath, &metadata)
+	metadata, err := ParseSubmitMetadataString(string(metadataBytes))
 	if err != nil {
 		return nil, errors.Wrapf(err, "Failed to parse submission metadata at %q", filePath)
 	}
 
-	return &metadata, nil
+	metadata.MetadataFilePath = filePath
+	metadata.SubmissionPath = filepath.Dir(filePath)
+
+	return metadata, nil
 }
 
 func ParseSubmitMetadataDir(dirPath string) (*MDRepoSubmitMetadata, error) {
-	metadata := MDRepoSubmitMetadata{
-		MetadataFilePath: filepath.Join(dirPath, SubmissionMetadataFilename),
-		SubmissionPath:   dirPath,
+	metadataPath := filepath.Join(dirPath, SubmissionMetadataFilename)
+
+	metadataBytes, err := os.ReadFile(metadataPath)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read submission metadata at %q", metadataPath)
 	}
 
-	_, err := toml.DecodeFile(metadata.MetadataFilePath, &metadata)
+	metadata, err := ParseSubmitMetadataString(string(metadataBytes))
 	if err != nil {
-		return nil, errors.Wrapf(err, "failed to parse submission metadata at %q", metadata.MetadataFilePath)
+		return nil, errors.Wrapf(err, "failed to parse submission metadata at %q", metadataPath)
 	}
 
-	return &metadata, nil
+	metadata.MetadataFilePath = metadataPath
+	metadata.SubmissionPath = dirPath
+
+	return metadata, nil
 }
 
 func ParseSubmitMetadataString(metadataString string) (*MDRepoSubmitMetadata, error) {
 	metadata := MDRepoSubmitMetadata{}
 
-	_, err := toml.Decode(metadataString, &metadata)
+	// fields of wrong types are reported when validating
+	tomlMetadata, typeErrors, err := decodeSubmitMetadataTOML(metadataString, &metadata)
 	if err != nil {
 		return nil, errors.Wrapf(err, "failed to parse submission metadata")
 	}
 
+	metadata.tomlMetadata = tomlMetadata
+	metadata.tomlText = metadataString
+	metadata.typeErrors = typeErrors
+
 	return &metadata, nil
 }
 
@@ -164,8 +269,22 @@ func (meta *MDRepoSubmitMetadata) ValidateFiles() error {
 
 	totalFileSize := int64(0)
 
+	for _, file := range meta.GetFiles() {
+		if len(file) == 0 {
+			// reported as a missing field
+			continue
+		}
+
+		err := ValidateSubmitFilePath(file)
+		if err != nil {
+			newErr := errors.Wrapf(err, "invalid file path described in metadata")
+			logger.Error(newErr)
+			invalidSubmitMetadataError.Add(newErr)
+		}
+	}
+
 	for _, file := range meta.TrajectoryFileNames {
-		absFilepath := filepath.Join(meta.SubmissionPath, file)
+		absFilepath := meta.GetSubmitFileLocalPath(file)
 
 		st, err := os.Stat(absFilepath)
 		if err != nil {
@@ -198,7 +317,7 @@ func (meta *MDRepoSubmitMetadata) ValidateFiles() error {
 	}
 
 	for filekey, file := range requiredFiles {
-		absFilepath := filepath.Join(meta.SubmissionPath, file)
+		absFilepath := meta.GetSubmitFileLocalPath(file)
 
 		st, err := os.Stat(absFilepath)
 		if err != nil {
@@ -262,40 +381,42 @@ func (meta *MDRepoSubmitMetadata) ValidateFiles() error {
 	}
 
 	for _, additionalFile := range meta.AdditionalFiles {
-		for filekey, file := range additionalFile {
-			if filekey == "file_name" {
-				absFilepath := filepath.Join(meta.SubmissionPath, file)
+		if len(additionalFile.FileName) == 0 {
+			// reported by schema validation
+			continue
+		}
 
-				st, err := os.Stat(absFilepath)
-				if err != nil {
-					newErr := errors.Wrapf(err, "cannot access additional file %q described in metadata %q", absFilepath, filekey)
-					logger.Error(newErr)
-					invalidSubmitMetadataError.Add(newErr)
-					continue
-				}
+		absFilepath := meta.GetSubmitFileLocalPath(additionalFile.FileName)
 
-				if st == nil {
-					newErr := errors.Errorf("cannot stat additional file %q described in metadata %q", absFilepath, filekey)
-					logger.Error(newErr)
-					invalidSubmitMetadataError.Add(newErr)
-					continue
-				}
+		st, err := os.Stat(absFilepath)
+		if err != nil {
+			newErr := errors.Wrapf(err, "cannot access additional file %q described in metadata %q", absFilepath, "file_name")
+			logger.Error(newErr)
+			invalidSubmitMetadataError.Add(newErr)
+			continue
+		}
 
-				if st.IsDir() {
-					newErr := errors.Errorf("additional file %q described in metadata %q is a directory", absFilepath, filekey)
-					logger.Error(newErr)
-					invalidSubmitMetadataError.Add(newErr)
-					continue
-				}
+		if st == nil {
+			newErr := errors.Errorf("cannot stat additional file %q described in metadata %q", absFilepath, "file_name")
+			logger.Error(newErr)
+			invalidSubmitMetadataError.Add(newErr)
+			continue
+		}
 
-				totalFileSize += st.Size()
-				allFiles = append(allFiles, file)
-			}
+		if st.IsDir() {
+			newErr := errors.Errorf("additional file %q described in metadata %q is a directory", absFilepath, "file_name")
+			logger.Error(newErr)
+			invalidSubmitMetadataError.Add(newErr)
+			continue
 		}
+
+		totalFileSize += st.Size()
+		allFiles = append(allFiles, additionalFile.FileName)
 	}
 
 	allFilesMap := map[string]bool{}
 	for _, f := range allFiles {
+		f = path.Clean(f)
 		if _, ok := allFilesMap[f]; ok {
 			// exist
 			newErr := errors.Errorf("the file %q is used multiple times in the metadata", f)
@@ -317,8 +438,11 @@ func (meta *MDRepoSubmitMetadata) ValidateFiles() error {
 		invalidSubmitMetadataError.Add(newErr)
 	}
 
+	// validate fields other than files
+	meta.validateSchema(invalidSubmitMetadataError)
+
 	if invalidSubmitMetadataError.ErrorLen() > 0 {
-		return errors.Wrapf(invalidSubmitMetadataError, "failed to validate required and additional files listed in submission metadata")
+		return errors.Wrapf(invalidSubmitMetadataError, "failed to validate submission metadata")
 	}
 
 	return nil
@@ -329,10 +453,8 @@ func (meta *MDRepoSubmitMetadata) GetFiles() []string {
 	files = append(files, meta.StructureFileName, meta.TopologyFileName)
 
 	for _, additionalFile := range meta.AdditionalFiles {
-		for k, v := range additionalFile {
-			if k == "file_name" {
-				files = append(files, v)
-			}
+		if len(additionalFile.FileName) > 0 {
+			files = append(files, additionalFile.FileName)
 		}
 	}
 	return files
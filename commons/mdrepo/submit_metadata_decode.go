@@ -0,0 +1,174 @@
+package mdrepo
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+var (
+	tomlPrimitiveType = reflect.TypeOf(toml.Primitive{})
+)
+
+// SubmitMetadataTypeError is a field in the submission metadata whose value has a wrong type
+type SubmitMetadataTypeError struct {
+	Key          string
+	ExpectedType string
+	GivenType    string
+}
+
+func (typeErr *SubmitMetadataTypeError) String() string {
+	return fmt.Sprintf("field '%s' has an invalid type, expected %s but got %s", typeErr.Key, typeErr.ExpectedType, typeErr.GivenType)
+}
+
+// decodeSubmitMetadataTOML decodes the TOML text into metadata field by field
+// a field of a wrong type is left empty and returned as SubmitMetadataTypeError, not to stop decoding other fields
+func decodeSubmitMetadataTOML(metadataString string, metadata *MDRepoSubmitMetadata) (toml.MetaData, []SubmitMetadataTypeError, error) {
+	var root toml.Primitive
+	tomlMetadata, err := toml.Decode(metadataString, &root)
+	if err != nil {
+		return tomlMetadata, nil, err
+	}
+
+	typeErrors := []SubmitMetadataTypeError{}
+	decodeTOMLPrimitive(&tomlMetadata, root, reflect.ValueOf(metadata).Elem(), nil, "", &typeErrors)
+	return tomlMetadata, typeErrors, nil
+}
+
+// decodeTOMLPrimitive decodes the primitive into target, descending into tables and arrays of tables
+// tables are decoded into shadow structs of primitives so that unknown keys stay undecoded
+// keyPath is the TOML key without array indices, key is the one reported with array indices
+// returns false if the primitive itself has a wrong type
+func decodeTOMLPrimitive(tomlMetadata *toml.MetaData, primitive toml.Primitive, target reflect.Value, keyPath []string, key string, typeErrors *[]SubmitMetadataTypeError) bool {
+	addTypeError := func() bool {
+		target.Set(reflect.Zero(target.Type()))
+		*typeErrors = append(*typeErrors, SubmitMetadataTypeError{
+			Key:          key,
+			ExpectedType: getTOMLTypeName(target.Type()),
+			GivenType:    strings.ToLower(tomlMetadata.Type(keyPath...)),
+		})
+		return false
+	}
+
+	targetType := target.Type()
+
+	switch {
+	case targetType.Kind() == reflect.Pointer && targetType.Elem().Kind() == reflect.Struct:
+		value := reflect.New(targetType.Elem())
+		if !decodeTOMLPrimitive(tomlMetadata, primitive, value.Elem(), keyPath, key, typeErrors) {
+			return false
+		}
+
+		target.Set(value)
+	case targetType.Kind() == reflect.Struct:
+		shadow := reflect.New(getTOMLShadowStructType(targetType))
+		err := tomlMetadata.PrimitiveDecode(primitive, shadow.Interface())
+		if err != nil {
+			return addTypeError()
+		}
+
+		for fieldIdx := 0; fieldIdx < targetType.NumField(); fieldIdx++ {
+			name := getTOMLFieldName(targetType.Field(fieldIdx))
+			if len(name) == 0 {
+				continue
+			}
+
+			fieldPrimitive := shadow.Elem().FieldByName(targetType.Field(fieldIdx).Name)
+			if fieldPrimitive.IsNil() {
+				// not given
+				continue
+			}
+
+			fieldKey := name
+			if len(key) > 0 {
+				fieldKey = key + "." + name
+			}
+
+			fieldKeyPath := append(append([]string{}, keyPath...), name)
+			decodeTOMLPrimitive(tomlMetadata, fieldPrimitive.Elem().Interface().(toml.Primitive), target.Field(fieldIdx), fieldKeyPath, fieldKey, typeErrors)
+		}
+	case targetType.Kind() == reflect.Slice && targetType.Elem().Kind() == reflect.Struct:
+		elemPrimitives := []toml.Primitive{}
+		err := tomlMetadata.PrimitiveDecode(primitive, &elemPrimitives)
+		if err != nil {
+			return addTypeError()
+		}
+
+		values := reflect.MakeSlice(targetType, len(elemPrimitives), len(elemPrimitives))
+		for elemIdx, elemPrimitive := range elemPrimitives {
+			decodeTOMLPrimitive(tomlMetadata, elemPrimitive, values.Index(elemIdx), keyPath, fmt.Sprintf("%s[%d]", key, elemIdx), typeErrors)
+		}
+
+		target.Set(values)
+	default:
+		err := tomlMetadata.PrimitiveDecode(primitive, target.Addr().Interface())
+		if err != nil {
+			return addTypeError()
+		}
+	}
+
+	return true
+}
+
+// getTOMLShadowStructType returns a struct type having a primitive pointer for each TOML field of the given struct type
+// nil pointers after decoding are the fields not given
+func getTOMLShadowStructType(structType reflect.Type) reflect.Type {
+	fields := []reflect.StructField{}
+	for fieldIdx := 0; fieldIdx < structType.NumField(); fieldIdx++ {
+		field := structType.Field(fieldIdx)
+		name := getTOMLFieldName(field)
+		if len(name) == 0 {
+			continue
+		}
+
+		fields = append(fields, reflect.StructField{
+			Name: field.Name,
+			Type: reflect.PointerTo(tomlPrimitiveType),
+			Tag:  reflect.StructTag(fmt.Sprintf("toml:%q", name)),
+		})
+	}
+
+	return reflect.StructOf(fields)
+}
+
+// getTOMLFieldName returns the TOML key of the struct field, empty if not decoded from TOML
+func getTOMLFieldName(field reflect.StructField) string {
+	if !field.IsExported() {
+		return ""
+	}
+
+	name, _, _ := strings.Cut(field.Tag.Get("toml"), ",")
+	if name == "-" {
+		return ""
+	}
+
+	return name
+}
+
+// getTOMLTypeName returns the TOML type name decoded into the Go type
+func getTOMLTypeName(targetType reflect.Type) string {
+	switch targetType.Kind() {
+	case reflect.Pointer:
+		return getTOMLTypeName(targetType.Elem())
+	case reflect.Struct:
+		return "table"
+	case reflect.Slice:
+		if targetType.Elem().Kind() == reflect.Struct {
+			return "array of tables"
+		}
+		return "array of " + getTOMLTypeName(targetType.Elem()) + "s"
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "bool"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		// integers are accepted too
+		return "number"
+	default:
+		return targetType.String()
+	}
+}
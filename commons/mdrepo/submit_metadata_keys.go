@@ -0,0 +1,228 @@
+package mdrepo
+
+import (
+	"bufio"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/MD-Repo/md-repo-cli/commons/types"
+	"github.com/cockroachdb/errors"
+	"golang.org/x/exp/slices"
+)
+
+// SubmitMetadataUnknownKey is a key found in the submission metadata that is not in the schema
+type SubmitMetadataUnknownKey struct {
+	Key        string
+	Line       int    // 0 if not found
+	Suggestion string // empty if no similar key is found
+}
+
+func (key *SubmitMetadataUnknownKey) String() string {
+	message := fmt.Sprintf("unknown field '%s'", key.Key)
+	if key.Line > 0 {
+		message += fmt.Sprintf(" at line %d", key.Line)
+	}
+
+	if len(key.Suggestion) > 0 {
+		message += fmt.Sprintf(", did you mean '%s'?", key.Suggestion)
+	}
+
+	return message
+}
+
+// GetUnknownKeys returns keys in the submission metadata that are not in the schema
+func (meta *MDRepoSubmitMetadata) GetUnknownKeys() []SubmitMetadataUnknownKey {
+	knownKeys := getSubmitMetadataKnownKeys(reflect.TypeOf(MDRepoSubmitMetadata{}), "")
+	undecodedKeys := meta.tomlMetadata.Undecoded()
+
+	undecodedKeyStrings := map[string]bool{}
+	for _, undecodedKey := range undecodedKeys {
+		undecodedKeyStrings[undecodedKey.String()] = true
+	}
+
+	unknownKeys := []SubmitMetadataUnknownKey{}
+	occurrences := map[string]int{}
+	for _, undecodedKey := range undecodedKeys {
+		keyString := undecodedKey.String()
+
+		// report only the top-most unknown table
+		parentKeyString := strings.Join(undecodedKey[:len(undecodedKey)-1], ".")
+		if undecodedKeyStrings[parentKeyString] {
+			continue
+		}
+
+		occurrences[keyString]++
+
+		unknownKeys = append(unknownKeys, SubmitMetadataUnknownKey{
+			Key:        keyString,
+			Line:       findTOMLKeyLine(meta.tomlText, undecodedKey, occurrences[keyString]),
+			Suggestion: suggestSubmitMetadataKey(knownKeys[parentKeyString], undecodedKey[len(undecodedKey)-1]),
+		})
+	}
+
+	return unknownKeys
+}
+
+// ValidateKeys returns InvalidSubmitMetadataError if the submission metadata has unknown keys
+func (meta *MDRepoSubmitMetadata) ValidateKeys() error {
+	invalidSubmitMetadataError := &types.InvalidSubmitMetadataError{}
+
+	for _, unknownKey := range meta.GetUnknownKeys() {
+		invalidSubmitMetadataError.Add(errors.New(unknownKey.String()))
+	}
+
+	if invalidSubmitMetadataError.ErrorLen() > 0 {
+		return errors.Wrapf(invalidSubmitMetadataError, "failed to validate keys in submission metadata")
+	}
+
+	return nil
+}
+
+// getSubmitMetadataKnownKeys returns known keys grouped by their parent key, read from toml tags
+func getSubmitMetadataKnownKeys(structType reflect.Type, parent string) map[string][]string {
+	knownKeys := map[string][]string{}
+
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		tag := field.Tag.Get("toml")
+		if !field.IsExported() || len(tag) == 0 || tag == "-" {
+			continue
+		}
+
+		knownKeys[parent] = append(knownKeys[parent], tag)
+
+		fieldType := field.Type
+		for fieldType.Kind() == reflect.Pointer || fieldType.Kind() == reflect.Slice {
+			fieldType = fieldType.Elem()
+		}
+
+		if fieldType.Kind() == reflect.Struct {
+			child := tag
+			if len(parent) > 0 {
+				child = parent + "." + tag
+			}
+
+			for k, v := range getSubmitMetadataKnownKeys(fieldType, child) {
+				knownKeys[k] = append(knownKeys[k], v...)
+			}
+		}
+	}
+
+	return knownKeys
+}
+
+// suggestSubmitMetadataKey returns the known key nearest to the given key by edit distance
+func suggestSubmitMetadataKey(knownKeys []string, key string) string {
+	// allow roughly one typo for every three characters
+	maxDistance := len(key) / 3
+	if maxDistance < 2 {
+		maxDistance = 2
+	}
+
+	suggestion := ""
+	suggestionDistance := maxDistance + 1
+	for _, knownKey := range knownKeys {
+		distance := getEditDistance(strings.ToLower(key), knownKey)
+		if distance < suggestionDistance {
+			suggestion = knownKey
+			suggestionDistance = distance
+		}
+	}
+
+	return suggestion
+}
+
+// getEditDistance returns Levenshtein distance between two strings
+func getEditDistance(a string, b string) int {
+	ra := []rune(a)
+	rb := []rune(b)
+
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+
+		prev, curr = curr, prev
+	}
+
+	return prev[len(rb)]
+}
+
+// findTOMLKeyLine returns the line number of the nth occurrence of the key in TOML text, 0 if not found
+// this is not a full TOML parser, it only tracks table headers and key-value lines
+func findTOMLKeyLine(tomlText string, key []string, nth int) int {
+	scanner := bufio.NewScanner(strings.NewReader(tomlText))
+
+	currentTable := []string{}
+	inMultilineString := false
+	lineNo := 0
+	found := 0
+
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+
+		if strings.Count(line, `"""`)%2 == 1 || strings.Count(line, `'''`)%2 == 1 {
+			wasInMultilineString := inMultilineString
+			inMultilineString = !inMultilineString
+			if wasInMultilineString {
+				continue
+			}
+		} else if inMultilineString {
+			continue
+		}
+
+		if len(line) == 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		var lineKey []string
+		if strings.HasPrefix(line, "[") {
+			header := strings.TrimLeft(line, "[")
+			if idx := strings.Index(header, "]"); idx >= 0 {
+				header = header[:idx]
+			}
+
+			currentTable = splitTOMLKey(header)
+			lineKey = currentTable
+		} else if idx := strings.Index(line, "="); idx > 0 {
+			lineKey = append(append([]string{}, currentTable...), splitTOMLKey(line[:idx])...)
+		} else {
+			continue
+		}
+
+		if slices.Equal(lineKey, key) {
+			found++
+			if found == nth {
+				return lineNo
+			}
+		}
+	}
+
+	return 0
+}
+
+// splitTOMLKey splits a dotted TOML key into its parts
+func splitTOMLKey(key string) []string {
+	parts := []string{}
+	for _, part := range strings.Split(key, ".") {
+		part = strings.TrimSpace(part)
+		part = strings.Trim(part, `"'`)
+		parts = append(parts, part)
+	}
+
+	return parts
+}
@@ -0,0 +1,230 @@
+package mdrepo
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+
+	"github.com/MD-Repo/md-repo-cli/commons/types"
+	"github.com/cockroachdb/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	minTemperatureKelvin     float64 = 0
+	maxTemperatureKelvin     float64 = 2000
+	minIntegrationTimeStepFs float64 = 0
+	maxIntegrationTimeStepFs float64 = 100
+	minPaperYear             int     = 1900
+)
+
+var (
+	orcIDRegex   = regexp.MustCompile(`^\d{4}-\d{4}-\d{4}-\d{3}[\dX]$`)
+	pdbIDRegex   = regexp.MustCompile(`^([0-9][A-Za-z0-9]{3}|pdb_[0-9]{4}[A-Za-z0-9]{4})$`)
+	uniprotRegex = regexp.MustCompile(`^([OPQ][0-9][A-Z0-9]{3}[0-9]|[A-NR-Z][0-9]([A-Z][A-Z0-9]{2}[0-9]){1,2})(-[0-9]+)?$`)
+	doiRegex     = regexp.MustCompile(`^10\.\d{4,9}/\S+$`)
+	emailRegex   = regexp.MustCompile(`^[^@\s]+@[^@\s]+\.[^@\s]+$`)
+)
+
+// ValidateOrcID checks the format and the checksum digit of ORCID
+func ValidateOrcID(orcID string) error {
+	if !orcIDRegex.MatchString(orcID) {
+		return errors.Errorf("ORCID %q must be in the form of '0000-0000-0000-0000'", orcID)
+	}
+
+	// ISO 7064 11,2 checksum
+	digits := strings.ReplaceAll(orcID, "-", "")
+	total := 0
+	for _, digit := range digits[:len(digits)-1] {
+		total = (total + int(digit-'0')) * 2
+	}
+
+	result := (12 - total%11) % 11
+	checksum := fmt.Sprintf("%d", result)
+	if result == 10 {
+		checksum = "X"
+	}
+
+	if digits[len(digits)-1:] != checksum {
+		return errors.Errorf("ORCID %q has an invalid checksum digit", orcID)
+	}
+
+	return nil
+}
+
+// validateSchema validates fields other than files listed in the submission metadata
+// only rules enforced by MD-Repo are checked, formats are checked by GetFormatWarnings
+func (meta *MDRepoSubmitMetadata) validateSchema(invalidSubmitMetadataError *types.InvalidSubmitMetadataError) {
+	logger := log.WithFields(log.Fields{})
+
+	addError := func(format string, args ...interface{}) {
+		newErr := errors.Errorf(format, args...)
+		logger.Error(newErr)
+		invalidSubmitMetadataError.Add(newErr)
+	}
+
+	// fields of wrong types are left empty, so are not checked again
+	for _, typeErr := range meta.typeErrors {
+		addError("%s", typeErr.String())
+	}
+
+	// required fields
+	requiredStringFields := []struct {
+		key   string
+		value string
+	}{
+		{"lead_contributor_orcid", meta.LeadContributorOrcid},
+		{"short_description", meta.ShortDescription},
+		{"software_name", meta.SoftwareName},
+		{"forcefield", meta.Forcefield},
+	}
+
+	for _, field := range requiredStringFields {
+		if meta.hasTypeError(field.key) {
+			continue
+		}
+
+		if len(strings.TrimSpace(field.value)) == 0 {
+			addError("field '%s' not found or empty", field.key)
+		}
+	}
+
+	if meta.hasTypeError("temperature_kelvin") {
+		// reported
+	} else if !meta.tomlMetadata.IsDefined("temperature_kelvin") {
+		addError("field 'temperature_kelvin' not found")
+	} else if math.IsNaN(meta.TemperatureKelvin) || meta.TemperatureKelvin <= minTemperatureKelvin || meta.TemperatureKelvin > maxTemperatureKelvin {
+		addError("field 'temperature_kelvin' must be greater than %g and at most %g, got %g", minTemperatureKelvin, maxTemperatureKelvin, meta.TemperatureKelvin)
+	}
+
+	// optional fields
+	if meta.tomlMetadata.IsDefined("integration_time_step_fs") && !meta.hasTypeError("integration_time_step_fs") {
+		if math.IsNaN(meta.IntegrationTimeStepFs) || meta.IntegrationTimeStepFs <= minIntegrationTimeStepFs || meta.IntegrationTimeStepFs > maxIntegrationTimeStepFs {
+			addError("field 'integration_time_step_fs' must be greater than %g and at most %g, got %g", minIntegrationTimeStepFs, maxIntegrationTimeStepFs, meta.IntegrationTimeStepFs)
+		}
+	}
+
+	if meta.Water != nil && meta.tomlMetadata.IsDefined("water", "density") && !meta.hasTypeError("water.density") && !math.IsNaN(meta.Water.Density) {
+		if meta.Water.Density <= 0 {
+			addError("field 'water.density' must be greater than 0, got %g", meta.Water.Density)
+		}
+
+		if len(meta.Water.WaterDensityUnits) == 0 {
+			addError("field 'water.water_density_units' is required when 'water.density' is given")
+		}
+	}
+
+	for idx, additionalFile := range meta.AdditionalFiles {
+		if len(additionalFile.FileName) == 0 {
+			addError("field 'additional_files[%d].file_name' not found or empty", idx)
+		}
+	}
+
+	moleculeTables := map[string][]MDRepoSubmitMolecule{
+		"ligands": meta.Ligands,
+		"solutes": meta.Solutes,
+	}
+
+	for _, key := range []string{"ligands", "solutes"} {
+		for idx, molecule := range moleculeTables[key] {
+			if len(molecule.Name) == 0 {
+				addError("field '%s[%d].name' not found or empty", key, idx)
+			}
+		}
+	}
+
+	for idx, paper := range meta.Papers {
+		if len(paper.Title) == 0 {
+			addError("field 'papers[%d].title' not found or empty", idx)
+		}
+
+		if paper.Year != 0 && paper.Year < minPaperYear {
+			addError("field 'papers[%d].year' must be %d or later, got %d", idx, minPaperYear, paper.Year)
+		}
+	}
+}
+
+// GetFormatWarnings returns fields in the submission metadata that are not in the expected formats
+// MD-Repo does not reject them, so they are reported as warnings
+func (meta *MDRepoSubmitMetadata) GetFormatWarnings() []string {
+	warnings := []string{}
+
+	addWarning := func(format string, args ...interface{}) {
+		warnings = append(warnings, fmt.Sprintf(format, args...))
+	}
+
+	if len(meta.LeadContributorOrcid) > 0 {
+		err := ValidateOrcID(meta.LeadContributorOrcid)
+		if err != nil {
+			addWarning("field 'lead_contributor_orcid' is invalid: %s", err.Error())
+		}
+	}
+
+	if len(meta.PDBID) > 0 && !pdbIDRegex.MatchString(meta.PDBID) {
+		addWarning("field 'pdb_id' has an invalid PDB ID %q", meta.PDBID)
+	}
+
+	for _, uniprotID := range meta.UniprotIDs {
+		if !uniprotRegex.MatchString(uniprotID) {
+			addWarning("field 'uniprot_ids' has an invalid UniProt accession %q", uniprotID)
+		}
+	}
+
+	for idx, additionalFile := range meta.AdditionalFiles {
+		if len(additionalFile.FileType) == 0 {
+			addWarning("field 'additional_files[%d].file_type' not found or empty", idx)
+		}
+	}
+
+	for idx, contributor := range meta.Contributors {
+		if len(contributor.Name) == 0 {
+			addWarning("field 'contributors[%d].name' not found or empty", idx)
+		}
+
+		if len(contributor.Orcid) > 0 {
+			err := ValidateOrcID(contributor.Orcid)
+			if err != nil {
+				addWarning("field 'contributors[%d].orcid' is invalid: %s", idx, err.Error())
+			}
+		}
+
+		if len(contributor.Email) > 0 && !emailRegex.MatchString(contributor.Email) {
+			addWarning("field 'contributors[%d].email' has an invalid email address %q", idx, contributor.Email)
+		}
+	}
+
+	for idx, paper := range meta.Papers {
+		if len(paper.DOI) > 0 && !doiRegex.MatchString(paper.DOI) {
+			addWarning("field 'papers[%d].doi' has an invalid DOI %q", idx, paper.DOI)
+		}
+	}
+
+	return warnings
+}
+
+// ValidateFormats returns InvalidSubmitMetadataError if the submission metadata has fields not in the expected formats
+func (meta *MDRepoSubmitMetadata) ValidateFormats() error {
+	invalidSubmitMetadataError := &types.InvalidSubmitMetadataError{}
+
+	for _, warning := range meta.GetFormatWarnings() {
+		invalidSubmitMetadataError.Add(errors.New(warning))
+	}
+
+	if invalidSubmitMetadataError.ErrorLen() > 0 {
+		return errors.Wrapf(invalidSubmitMetadataError, "failed to validate formats in submission metadata")
+	}
+
+	return nil
+}
+
+// hasTypeError returns true if the field has a wrong type in the submission metadata
+func (meta *MDRepoSubmitMetadata) hasTypeError(key string) bool {
+	for _, typeErr := range meta.typeErrors {
+		if typeErr.Key == key {
+			return true
+		}
+	}
+
+	return false
+}
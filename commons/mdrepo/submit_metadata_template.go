@@ -0,0 +1,212 @@
+package mdrepo
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/fs"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/cockroachdb/errors"
+	"golang.org/x/exp/slices"
+)
+
+type SubmitFileRole string
+
+const (
+	SubmitFileRoleTrajectory SubmitFileRole = "trajectory"
+	SubmitFileRoleStructure  SubmitFileRole = "structure"
+	SubmitFileRoleTopology   SubmitFileRole = "topology"
+	SubmitFileRoleAdditional SubmitFileRole = "additional"
+)
+
+var (
+	// file extensions in the order of preference
+	trajectoryFileExtensions = []string{".xtc", ".dcd", ".trr", ".nc"}
+	structureFileExtensions  = []string{".pdb", ".gro"}
+	topologyFileExtensions   = []string{".prmtop", ".psf", ".top", ".tpr"}
+)
+
+// GetSubmitFileRoleByExtension returns the role of the file guessed from its extension
+func GetSubmitFileRoleByExtension(filename string) SubmitFileRole {
+	ext := strings.ToLower(filepath.Ext(filename))
+
+	switch {
+	case slices.Contains(trajectoryFileExtensions, ext):
+		return SubmitFileRoleTrajectory
+	case slices.Contains(structureFileExtensions, ext):
+		return SubmitFileRoleStructure
+	case slices.Contains(topologyFileExtensions, ext):
+		return SubmitFileRoleTopology
+	default:
+		return SubmitFileRoleAdditional
+	}
+}
+
+// NewSubmitMetadataFromDir creates submit metadata by classifying files in the given dir
+func NewSubmitMetadataFromDir(dirPath string) (*MDRepoSubmitMetadata, error) {
+	metadata := MDRepoSubmitMetadata{
+		MetadataFilePath: GetSubmitMetadataPath(dirPath),
+		SubmissionPath:   dirPath,
+
+		TrajectoryFileNames: []string{},
+		AdditionalFiles:     []MDRepoSubmitAdditionalFile{},
+	}
+
+	structureFiles := []string{}
+	topologyFiles := []string{}
+	additionalFiles := []string{}
+
+	// include files in sub-directories, using '/' as a path separator
+	err := filepath.WalkDir(dirPath, func(entryPath string, entry fs.DirEntry, walkErr error) error {
+		if walkErr != nil {
+			return walkErr
+		}
+
+		if entryPath == dirPath {
+			return nil
+		}
+
+		name := entry.Name()
+		if entry.IsDir() {
+			if strings.HasPrefix(name, ".") || HasSubmitMetadataInDir(entryPath) {
+				// hidden dir or another simulation
+				return filepath.SkipDir
+			}
+
+			return nil
+		}
+
+		if strings.HasPrefix(name, ".") || name == SubmissionMetadataFilename || IsStatusFile(name) {
+			return nil
+		}
+
+		relPath, err := filepath.Rel(dirPath, entryPath)
+		if err != nil {
+			return errors.Wrapf(err, "failed to get relative path of %q", entryPath)
+		}
+
+		relPath = filepath.ToSlash(relPath)
+
+		switch GetSubmitFileRoleByExtension(name) {
+		case SubmitFileRoleTrajectory:
+			metadata.TrajectoryFileNames = append(metadata.TrajectoryFileNames, relPath)
+		case SubmitFileRoleStructure:
+			structureFiles = append(structureFiles, relPath)
+		case SubmitFileRoleTopology:
+			topologyFiles = append(topologyFiles, relPath)
+		default:
+			additionalFiles = append(additionalFiles, relPath)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read dir %q", dirPath)
+	}
+
+	// pick one structure and one topology file, the rest become additional files
+	metadata.StructureFileName, structureFiles = pickPreferredFile(structureFiles, structureFileExtensions)
+	metadata.TopologyFileName, topologyFiles = pickPreferredFile(topologyFiles, topologyFileExtensions)
+
+	additionalFiles = append(additionalFiles, structureFiles...)
+	additionalFiles = append(additionalFiles, topologyFiles...)
+	slices.Sort(additionalFiles)
+
+	for _, additionalFile := range additionalFiles {
+		metadata.AdditionalFiles = append(metadata.AdditionalFiles, MDRepoSubmitAdditionalFile{
+			FileName: additionalFile,
+		})
+	}
+
+	return &metadata, nil
+}
+
+// pickPreferredFile returns the file with the most preferred extension and the rest
+func pickPreferredFile(files []string, extensions []string) (string, []string) {
+	for _, ext := range extensions {
+		for idx, file := range files {
+			if strings.ToLower(filepath.Ext(file)) == ext {
+				rest := append([]string{}, files[:idx]...)
+				rest = append(rest, files[idx+1:]...)
+				return file, rest
+			}
+		}
+	}
+
+	return "", files
+}
+
+const submitMetadataTemplate = `# MD-Repo submission metadata
+# generated by mdrepo init, fill in all fields marked as required before submitting
+
+# required: ORCID of the lead contributor (e.g., "0000-0000-0000-0000")
+lead_contributor_orcid = ""
+# required: a short description of the simulation
+short_description = ""
+# required: name of the simulation software (e.g., "GROMACS", "AMBER")
+software_name = ""
+# required: force field used in the simulation
+forcefield = ""
+# required: simulation temperature in Kelvin
+temperature_kelvin = 0
+# optional: integration time step in femtoseconds
+# integration_time_step_fs = 2
+# optional: protonation method
+protonation_method = ""
+# optional: PDB ID of the simulated structure
+pdb_id = ""
+# optional: UniProt IDs of the simulated proteins
+uniprot_ids = []
+# optional: replicate identifier
+replicate_id = ""
+
+# required: trajectory files
+trajectory_file_names = [{{ range $idx, $file := .TrajectoryFileNames }}{{ if $idx }}, {{ end }}{{ quote $file }}{{ end }}]
+# required: structure file
+structure_file_name = {{ quote .StructureFileName }}
+# required: topology file
+topology_file_name = {{ quote .TopologyFileName }}
+{{ range .AdditionalFiles }}
+[[additional_files]]
+# required: type of the file (e.g., "Input", "Output", "Trajectory")
+file_type = {{ quote .FileType }}
+file_name = {{ quote .FileName }}
+description = {{ quote .Description }}
+{{ end }}`
+
+// MakeSubmitMetadataTemplate returns TOML text of the submit metadata with placeholders for fields to fill in
+func MakeSubmitMetadataTemplate(metadata *MDRepoSubmitMetadata) (string, error) {
+	funcs := template.FuncMap{
+		"quote": quoteTOMLString,
+	}
+
+	tmpl, err := template.New("metadata").Funcs(funcs).Parse(submitMetadataTemplate)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to parse submit metadata template")
+	}
+
+	buffer := bytes.Buffer{}
+	err = tmpl.Execute(&buffer, metadata)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to execute submit metadata template")
+	}
+
+	return buffer.String(), nil
+}
+
+// quoteTOMLString returns a TOML basic string
+// JSON string escapes are valid in TOML basic strings
+func quoteTOMLString(str string) (string, error) {
+	buffer := bytes.Buffer{}
+	encoder := json.NewEncoder(&buffer)
+	encoder.SetEscapeHTML(false)
+
+	err := encoder.Encode(str)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to quote string %q", str)
+	}
+
+	return strings.TrimRight(buffer.String(), "\n"), nil
+}
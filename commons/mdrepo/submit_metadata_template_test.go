@@ -0,0 +1,83 @@
+package mdrepo
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSubmitMetadataTemplate(t *testing.T) {
+	t.Run("test GetSubmitFileRoleByExtension", testGetSubmitFileRoleByExtension)
+	t.Run("test MakeSubmitMetadataTemplate", testMakeSubmitMetadataTemplate)
+}
+
+func testGetSubmitFileRoleByExtension(t *testing.T) {
+	assert.Equal(t, SubmitFileRoleTrajectory, GetSubmitFileRoleByExtension("run.xtc"))
+	assert.Equal(t, SubmitFileRoleTrajectory, GetSubmitFileRoleByExtension("run.DCD"))
+	assert.Equal(t, SubmitFileRoleStructure, GetSubmitFileRoleByExtension("protein.gro"))
+	assert.Equal(t, SubmitFileRoleTopology, GetSubmitFileRoleByExtension("system.prmtop"))
+	assert.Equal(t, SubmitFileRoleAdditional, GetSubmitFileRoleByExtension("params.mdp"))
+	assert.Equal(t, SubmitFileRoleAdditional, GetSubmitFileRoleByExtension("README"))
+}
+
+func testMakeSubmitMetadataTemplate(t *testing.T) {
+	dirPath := t.TempDir()
+
+	files := []string{
+		"run1.xtc",
+		"run2.xtc",
+		"start.gro",
+		"filtered.pdb",
+		"system.prmtop",
+		"topol.top",
+		"params \"v2\".mdp",
+		".hidden",
+		"mdrepo-submission.completed.json",
+	}
+
+	for _, file := range files {
+		err := os.WriteFile(filepath.Join(dirPath, file), []byte(file), 0644)
+		assert.NoError(t, err)
+	}
+
+	err := os.Mkdir(filepath.Join(dirPath, "inputs"), 0755)
+	assert.NoError(t, err)
+
+	err = os.WriteFile(filepath.Join(dirPath, "inputs", "md.in"), []byte("md.in"), 0644)
+	assert.NoError(t, err)
+
+	// another simulation in a sub-directory must be skipped
+	err = os.Mkdir(filepath.Join(dirPath, "other"), 0755)
+	assert.NoError(t, err)
+
+	err = os.WriteFile(filepath.Join(dirPath, "other", SubmissionMetadataFilename), []byte("x"), 0644)
+	assert.NoError(t, err)
+
+	err = os.WriteFile(filepath.Join(dirPath, "other", "other.xtc"), []byte("other.xtc"), 0644)
+	assert.NoError(t, err)
+
+	metadata, err := NewSubmitMetadataFromDir(dirPath)
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{"run1.xtc", "run2.xtc"}, metadata.TrajectoryFileNames)
+	assert.Equal(t, "filtered.pdb", metadata.StructureFileName)
+	assert.Equal(t, "system.prmtop", metadata.TopologyFileName)
+	assert.Len(t, metadata.AdditionalFiles, 4)
+
+	metadataString, err := MakeSubmitMetadataTemplate(metadata)
+	assert.NoError(t, err)
+
+	parsedMetadata, err := ParseSubmitMetadataString(metadataString)
+	assert.NoError(t, err)
+
+	assert.Equal(t, "", parsedMetadata.LeadContributorOrcid)
+	assert.Equal(t, metadata.TrajectoryFileNames, parsedMetadata.TrajectoryFileNames)
+	assert.Equal(t, metadata.StructureFileName, parsedMetadata.StructureFileName)
+	assert.Equal(t, metadata.TopologyFileName, parsedMetadata.TopologyFileName)
+	assert.ElementsMatch(t, []string{
+		"run1.xtc", "run2.xtc", "filtered.pdb", "system.prmtop",
+		"inputs/md.in", "params \"v2\".mdp", "start.gro", "topol.top",
+	}, parsedMetadata.GetFiles())
+}
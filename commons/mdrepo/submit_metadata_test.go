@@ -1,13 +1,25 @@
 package mdrepo
 
 import (
+	"fmt"
+	"os"
+	"path/filepath"
 	"testing"
 
+	"github.com/MD-Repo/md-repo-cli/commons/types"
+	"github.com/cockroachdb/errors"
 	"github.com/stretchr/testify/assert"
 )
 
 func TestSubmitMetadata(t *testing.T) {
 	t.Run("test ReadSubmitMetadata", testReadSubmitMetadata)
+	t.Run("test ValidateOrcID", testValidateOrcID)
+	t.Run("test ValidateSchema", testValidateSchema)
+	t.Run("test TypeErrors", testTypeErrors)
+	t.Run("test UnknownKeys", testUnknownKeys)
+	t.Run("test NestedFiles", testNestedFiles)
+	t.Run("test OrphanFiles", testOrphanFiles)
+	t.Run("test HashSubmissionFilesParallel", testHashSubmissionFilesParallel)
 }
 
 func testReadSubmitMetadata(t *testing.T) {
@@ -63,4 +75,315 @@ func testReadSubmitMetadata(t *testing.T) {
 	assert.Contains(t, files, "i1")
 	assert.Contains(t, files, "i2")
 	assert.Contains(t, files, "t1")
+
+	// MD-Repo accepts the uniprot id, so it is only a warning
+	submitMetadata, err = ParseSubmitMetadataDir(writeSubmitMetadataDir(t, metadata, files))
+	assert.NoError(t, err)
+	assert.NoError(t, submitMetadata.ValidateFiles())
+	assert.Len(t, submitMetadata.GetFormatWarnings(), 1)
+}
+
+func testValidateOrcID(t *testing.T) {
+	assert.NoError(t, ValidateOrcID("0000-0001-7374-1561"))
+	assert.NoError(t, ValidateOrcID("0000-0002-1694-233X"))
+	assert.Error(t, ValidateOrcID("0000-0001-7374-1562"))
+	assert.Error(t, ValidateOrcID("0000-0001-7374"))
+	assert.Error(t, ValidateOrcID("abcd-0001-7374-1561"))
+}
+
+func writeSubmitMetadataDir(t *testing.T, metadata string, files []string) string {
+	dirPath := t.TempDir()
+
+	err := os.WriteFile(filepath.Join(dirPath, SubmissionMetadataFilename), []byte(metadata), 0644)
+	assert.NoError(t, err)
+
+	for _, file := range files {
+		err = os.WriteFile(filepath.Join(dirPath, file), []byte(file), 0644)
+		assert.NoError(t, err)
+	}
+
+	return dirPath
+}
+
+func testValidateSchema(t *testing.T) {
+	files := []string{"run.xtc", "start.pdb", "system.prmtop", "params.mdp"}
+
+	validMetadata := `
+	short_description = "test"
+	lead_contributor_orcid = "0000-0001-7374-1561"
+	software_name = "GROMACS"
+	software_version = "2023.1"
+	pdb_id = "4u3n"
+	uniprot_ids = ["P69905", "A0A023GPI8"]
+	forcefield = "CHARMM36m"
+	temperature_kelvin = 310
+	integration_time_step_fs = 2
+	trajectory_file_names = ["run.xtc"]
+	structure_file_name = "start.pdb"
+	topology_file_name = "system.prmtop"
+
+	[water]
+	model = "TIP3P"
+	density = 0.986
+	water_density_units = "g/cm^3"
+
+	[[ligands]]
+	name = "ATP"
+	smiles = "c1nc(c2c(n1)n(cn2)C3C(C(C(O3)COP(=O)(O)OP(=O)(O)OP(=O)(O)O)O)O)N"
+
+	[[contributors]]
+	name = "Jane Doe"
+	orcid = "0000-0002-1694-233X"
+	email = "jane@example.org"
+
+	[[papers]]
+	title = "A paper"
+	year = 2024
+	doi = "10.1000/xyz123"
+
+	[[additional_files]]
+	file_type = "Input"
+	file_name = "params.mdp"
+	description = "MD parameters"
+`
+
+	metadata, err := ParseSubmitMetadataDir(writeSubmitMetadataDir(t, validMetadata, files))
+	assert.NoError(t, err)
+	assert.NoError(t, metadata.ValidateFiles())
+	assert.Empty(t, metadata.GetFormatWarnings())
+	assert.NoError(t, metadata.ValidateFormats())
+
+	invalidMetadata := `
+	lead_contributor_orcid = "0000-0001-7374-1562"
+	software_name = "GROMACS"
+	pdb_id = "abcde"
+	uniprot_ids = ["x33433"]
+	temperature_kelvin = -5
+	integration_time_step_fs = 0
+	trajectory_file_names = ["run.xtc"]
+	structure_file_name = "start.pdb"
+	topology_file_name = "system.prmtop"
+
+	[water]
+	density = -1.0
+
+	[[contributors]]
+	email = "not-an-email"
+
+	[[additional_files]]
+	file_name = "params.mdp"
+`
+
+	metadata, err = ParseSubmitMetadataDir(writeSubmitMetadataDir(t, invalidMetadata, files))
+	assert.NoError(t, err)
+
+	err = metadata.ValidateFiles()
+	assert.Error(t, err)
+
+	var invalidSubmitMetadataError *types.InvalidSubmitMetadataError
+	assert.True(t, errors.As(err, &invalidSubmitMetadataError))
+
+	// short_description, forcefield, temperature, time step, water density, water density units
+	assert.Equal(t, 6, invalidSubmitMetadataError.ErrorLen())
+
+	// orcid checksum, pdb_id, uniprot_ids, additional file type, contributor name, contributor email
+	assert.Len(t, metadata.GetFormatWarnings(), 6)
+	assert.Error(t, metadata.ValidateFormats())
+}
+
+func testTypeErrors(t *testing.T) {
+	files := []string{"run.xtc", "start.pdb", "system.prmtop"}
+
+	typeMismatchMetadata := `
+	short_description = "test"
+	lead_contributor_orcid = "0000-0001-7374-1561"
+	software_name = "GROMACS"
+	replicate_id = 1
+	forcefield = "CHARMM36m"
+	temperature_kelvin = "310K"
+	trajectory_file_names = ["run.xtc"]
+	structure_file_name = "start.pdb"
+	topology_file_name = "system.prmtop"
+	water = "TIP3P"
+
+	[[papers]]
+	title = "A paper"
+	volume = "12"
+	year = 2024
+	unknown_key = "value"
+`
+
+	metadata, err := ParseSubmitMetadataDir(writeSubmitMetadataDir(t, typeMismatchMetadata, files))
+	assert.NoError(t, err)
+
+	// other fields are decoded
+	assert.Equal(t, "GROMACS", metadata.SoftwareName)
+	assert.Empty(t, metadata.ReplicateID)
+	assert.Nil(t, metadata.Water)
+	assert.Len(t, metadata.Papers, 1)
+	assert.Equal(t, "A paper", metadata.Papers[0].Title)
+	assert.Equal(t, 2024, metadata.Papers[0].Year)
+
+	// unknown keys are still found
+	unknownKeys := metadata.GetUnknownKeys()
+	assert.Len(t, unknownKeys, 1)
+	assert.Equal(t, "papers.unknown_key", unknownKeys[0].Key)
+
+	err = metadata.ValidateFiles()
+	assert.Error(t, err)
+
+	var invalidSubmitMetadataError *types.InvalidSubmitMetadataError
+	assert.True(t, errors.As(err, &invalidSubmitMetadataError))
+
+	// replicate_id, temperature_kelvin, water, papers[0].volume
+	assert.Equal(t, 4, invalidSubmitMetadataError.ErrorLen())
+	assert.ErrorContains(t, err, "field 'replicate_id' has an invalid type")
+	assert.ErrorContains(t, err, "field 'papers[0].volume' has an invalid type")
+}
+
+func testUnknownKeys(t *testing.T) {
+	metadata := `lead_contributor_orcid = "0000-0001-7374-1561"
+trajectory_files_names = ["run.xtc"]
+structure_file_name = "start.pdb"
+topology_file_name = "system.prmtop"
+something_else = 1
+
+[water]
+modle = "TIP3P"
+
+[[additional_files]]
+file_type = "Input"
+file_name = "i1"
+
+[[additional_files]]
+file_type = "Input"
+file_name = "i2"
+descripton = "i2_desc"
+
+[extra]
+key = "value"
+`
+
+	submitMetadata, err := ParseSubmitMetadataString(metadata)
+	assert.NoError(t, err)
+
+	unknownKeys := submitMetadata.GetUnknownKeys()
+	assert.Equal(t, []SubmitMetadataUnknownKey{
+		{Key: "trajectory_files_names", Line: 2, Suggestion: "trajectory_file_names"},
+		{Key: "something_else", Line: 5, Suggestion: ""},
+		{Key: "water.modle", Line: 8, Suggestion: "model"},
+		{Key: "additional_files.descripton", Line: 17, Suggestion: "description"},
+		{Key: "extra", Line: 19, Suggestion: ""},
+	}, unknownKeys)
+
+	assert.Equal(t, "unknown field 'trajectory_files_names' at line 2, did you mean 'trajectory_file_names'?", unknownKeys[0].String())
+
+	err = submitMetadata.ValidateKeys()
+	assert.Error(t, err)
+
+	var invalidSubmitMetadataError *types.InvalidSubmitMetadataError
+	assert.True(t, errors.As(err, &invalidSubmitMetadataError))
+	assert.Equal(t, 5, invalidSubmitMetadataError.ErrorLen())
+}
+
+func testNestedFiles(t *testing.T) {
+	assert.NoError(t, ValidateSubmitFilePath("run.xtc"))
+	assert.NoError(t, ValidateSubmitFilePath("inputs/md.mdp"))
+	assert.Error(t, ValidateSubmitFilePath("../run.xtc"))
+	assert.Error(t, ValidateSubmitFilePath("inputs/../../run.xtc"))
+	assert.Error(t, ValidateSubmitFilePath("/data/run.xtc"))
+	assert.Error(t, ValidateSubmitFilePath("inputs\\md.mdp"))
+
+	dirPath := writeSubmitMetadataDir(t, `trajectory_file_names = ["run.xtc"]`, []string{"run.xtc"})
+
+	err := os.MkdirAll(filepath.Join(dirPath, "inputs"), 0755)
+	assert.NoError(t, err)
+
+	err = os.WriteFile(filepath.Join(dirPath, "inputs", "md.mdp"), []byte("md.mdp"), 0644)
+	assert.NoError(t, err)
+
+	// sub-directories are allowed
+	assert.NoError(t, ValidateSubmissionSourcePath(dirPath))
+
+	metadata, err := ParseSubmitMetadataDir(dirPath)
+	assert.NoError(t, err)
+
+	metadata.AdditionalFiles = []MDRepoSubmitAdditionalFile{
+		{FileType: "Input", FileName: "inputs/md.mdp"},
+	}
+
+	fileHashes, err := HashSubmissionFiles(metadata, nil)
+	assert.NoError(t, err)
+	assert.Len(t, fileHashes, 3)
+	assert.Contains(t, fileHashes, filepath.Join(dirPath, "inputs", "md.mdp"))
+
+	// but not another simulation
+	err = os.WriteFile(filepath.Join(dirPath, "inputs", SubmissionMetadataFilename), []byte("x"), 0644)
+	assert.NoError(t, err)
+
+	assert.Error(t, ValidateSubmissionSourcePath(dirPath))
+}
+
+func testOrphanFiles(t *testing.T) {
+	dirPath := writeSubmitMetadataDir(t, `trajectory_file_names = ["run.xtc"]`, []string{"run.xtc", "notes.txt", "empty.log"})
+
+	// unlisted files are not checked
+	err := os.WriteFile(filepath.Join(dirPath, "empty.log"), []byte{}, 0644)
+	assert.NoError(t, err)
+
+	err = os.MkdirAll(filepath.Join(dirPath, "scratch"), 0755)
+	assert.NoError(t, err)
+
+	err = os.WriteFile(filepath.Join(dirPath, "scratch", "copy.xtc"), []byte("run.xtc"), 0644)
+	assert.NoError(t, err)
+
+	metadata, err := ParseSubmitMetadataDir(dirPath)
+	assert.NoError(t, err)
+
+	fileHashes, err := HashSubmissionFiles(metadata, nil)
+	assert.NoError(t, err)
+	assert.Len(t, fileHashes, 2)
+	assert.NoError(t, CheckDuplicateSubmissionFiles(fileHashes))
+
+	orphanFiles, err := FindOrphanSubmissionFiles(metadata)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"empty.log", "notes.txt", "scratch/copy.xtc"}, orphanFiles)
+
+	err = os.WriteFile(filepath.Join(dirPath, SubmissionIgnoreFilename), []byte("*.log\nscratch/\n"), 0644)
+	assert.NoError(t, err)
+
+	orphanFiles, err = FindOrphanSubmissionFiles(metadata)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"notes.txt"}, orphanFiles)
+}
+
+func testHashSubmissionFilesParallel(t *testing.T) {
+	metadataList := []*MDRepoSubmitMetadata{}
+	for _, files := range [][]string{{"run1.xtc", "start1.pdb"}, {"run2.xtc", "start2.pdb"}} {
+		dirPath := writeSubmitMetadataDir(t, fmt.Sprintf("trajectory_file_names = [%q]\nstructure_file_name = %q", files[0], files[1]), files)
+
+		metadata, err := ParseSubmitMetadataDir(dirPath)
+		assert.NoError(t, err)
+
+		metadataList = append(metadataList, metadata)
+	}
+
+	fileHashes, err := HashSubmissionFilesParallel(metadataList, nil, 4, false, false)
+	assert.NoError(t, err)
+	// 2 listed files and metadata file for each simulation
+	assert.Len(t, fileHashes, 6)
+
+	runPath, err := filepath.Abs(metadataList[0].GetSubmitFileLocalPath("run1.xtc"))
+	assert.NoError(t, err)
+	assert.Equal(t, "22fe845f9c32e865956963a503087067", fileHashes[runPath])
+
+	// empty files are reported together
+	for _, metadata := range metadataList {
+		err = os.WriteFile(metadata.GetSubmitFileLocalPath(metadata.StructureFileName), []byte{}, 0644)
+		assert.NoError(t, err)
+	}
+
+	_, err = HashSubmissionFilesParallel(metadataList, nil, 4, false, false)
+	assert.ErrorContains(t, err, "start1.pdb")
+	assert.ErrorContains(t, err, "start2.pdb")
 }
@@ -0,0 +1,388 @@
+package mdrepo
+
+import (
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/MD-Repo/md-repo-cli/commons/hashcache"
+	"github.com/MD-Repo/md-repo-cli/commons/parallel"
+	commons_path "github.com/MD-Repo/md-repo-cli/commons/path"
+	"github.com/MD-Repo/md-repo-cli/commons/types"
+	"github.com/cockroachdb/errors"
+	irodsclient_types "github.com/cyverse/go-irodsclient/irods/types"
+	"github.com/jedib0t/go-pretty/v6/progress"
+	"golang.org/x/exp/slices"
+)
+
+// SubmissionDiscoveryOptions controls how simulation directories are discovered
+type SubmissionDiscoveryOptions struct {
+	Recursive bool
+	MaxDepth  int      // max depth to look into in recursive mode, 0 for unlimited
+	Include   []string // glob patterns of simulation directories to include, matched against relative path or name
+	Exclude   []string // glob patterns of directories to exclude, matched against relative path or name
+}
+
+// FindSubmissionSourcePaths finds simulation directories in the given source paths
+// a source path is a simulation directory if it has submit metadata, otherwise its sub-directories are checked
+// sub-directories are checked one level deep unless recursive discovery is requested in options
+// returns simulation directories found, directories ignored and the reasons why they are ignored
+func FindSubmissionSourcePaths(sourcePaths []string, options *SubmissionDiscoveryOptions) ([]string, []string, []error, error) {
+	if options == nil {
+		options = &SubmissionDiscoveryOptions{}
+	}
+
+	for _, pattern := range append(append([]string{}, options.Include...), options.Exclude...) {
+		_, err := path.Match(pattern, "")
+		if err != nil {
+			return nil, nil, nil, errors.Wrapf(err, "invalid pattern %q", pattern)
+		}
+	}
+
+	discovery := &submissionDiscovery{
+		options:                  options,
+		validSourcePaths:         []string{},
+		invalidSourcePaths:       []string{},
+		invalidSourcePathsErrors: []error{},
+	}
+
+	for _, sourcePath := range sourcePaths {
+		sourcePath = commons_path.MakeLocalPath(sourcePath)
+
+		st, stErr := os.Stat(sourcePath)
+		if stErr != nil {
+			if os.IsNotExist(stErr) {
+				return nil, nil, nil, errors.Join(stErr, irodsclient_types.NewFileNotFoundError(sourcePath))
+			}
+
+			return nil, nil, nil, stErr
+		}
+
+		if !st.IsDir() {
+			return nil, nil, nil, types.NewNotDirError(sourcePath)
+		}
+
+		err := discovery.discover(sourcePath, sourcePath, 0)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+	}
+
+	// sort source paths by name to match to tickets always in the same order
+	slices.Sort(discovery.validSourcePaths)
+
+	return discovery.validSourcePaths, discovery.invalidSourcePaths, discovery.invalidSourcePathsErrors, nil
+}
+
+type submissionDiscovery struct {
+	options *SubmissionDiscoveryOptions
+
+	validSourcePaths         []string
+	invalidSourcePaths       []string
+	invalidSourcePathsErrors []error
+}
+
+func (discovery *submissionDiscovery) addInvalid(sourcePath string, err error) {
+	discovery.invalidSourcePaths = append(discovery.invalidSourcePaths, sourcePath)
+	discovery.invalidSourcePathsErrors = append(discovery.invalidSourcePathsErrors, err)
+}
+
+func (discovery *submissionDiscovery) matchAny(patterns []string, rootPath string, sourcePath string) string {
+	relPath, err := filepath.Rel(rootPath, sourcePath)
+	if err != nil {
+		relPath = sourcePath
+	}
+
+	relPath = filepath.ToSlash(relPath)
+	name := filepath.Base(sourcePath)
+
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(pattern, relPath); matched {
+			return pattern
+		}
+
+		if matched, _ := path.Match(pattern, name); matched {
+			return pattern
+		}
+	}
+
+	return ""
+}
+
+func (discovery *submissionDiscovery) discover(rootPath string, sourcePath string, depth int) error {
+	if depth > 0 {
+		excludePattern := discovery.matchAny(discovery.options.Exclude, rootPath, sourcePath)
+		if len(excludePattern) > 0 {
+			discovery.addInvalid(sourcePath, errors.Errorf("directory %q is excluded by pattern %q", sourcePath, excludePattern))
+			return nil
+		}
+	}
+
+	err := ValidateSubmissionSourcePath(sourcePath)
+	if err == nil {
+		// valid
+		if len(discovery.options.Include) > 0 && len(discovery.matchAny(discovery.options.Include, rootPath, sourcePath)) == 0 {
+			discovery.addInvalid(sourcePath, errors.Errorf("simulation %q does not match include patterns", sourcePath))
+			return nil
+		}
+
+		discovery.validSourcePaths = append(discovery.validSourcePaths, sourcePath)
+		return nil
+	}
+
+	// may have sub dirs?
+	dirEntries, readErr := os.ReadDir(sourcePath)
+	if readErr != nil {
+		return errors.Wrapf(readErr, "Failed to list source %q", sourcePath)
+	}
+
+	subDirPaths := []string{}
+	for _, dirEntry := range dirEntries {
+		if dirEntry.IsDir() && !strings.HasPrefix(dirEntry.Name(), ".") {
+			subDirPaths = append(subDirPaths, filepath.Join(sourcePath, dirEntry.Name()))
+		}
+	}
+
+	if len(subDirPaths) == 0 {
+		// invalid
+		discovery.addInvalid(sourcePath, err)
+		return nil
+	}
+
+	maxDepth := 1
+	if discovery.options.Recursive {
+		maxDepth = discovery.options.MaxDepth
+	}
+
+	if maxDepth > 0 && depth >= maxDepth {
+		if discovery.options.Recursive {
+			err = errors.Wrapf(err, "max depth %d reached", maxDepth)
+		}
+
+		discovery.addInvalid(sourcePath, err)
+		return nil
+	}
+
+	for _, subDirPath := range subDirPaths {
+		err = discovery.discover(rootPath, subDirPath, depth+1)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// GetSubmissionFiles returns files to be submitted, files listed in the metadata and the metadata file itself
+// returned paths are relative to the simulation dir, separated by '/'
+func GetSubmissionFiles(metadata *MDRepoSubmitMetadata) []string {
+	files := []string{}
+	hasMetadata := false
+
+	for _, file := range metadata.GetFiles() {
+		if len(file) == 0 {
+			continue
+		}
+
+		file = path.Clean(file)
+		if file == SubmissionMetadataFilename {
+			hasMetadata = true
+		}
+
+		if !slices.Contains(files, file) {
+			files = append(files, file)
+		}
+	}
+
+	if !hasMetadata {
+		// include submission metadata file itself
+		files = append(files, SubmissionMetadataFilename)
+	}
+
+	return files
+}
+
+// HashSubmissionFiles computes MD5 hashes of files to be submitted
+// files missing or not regular files are skipped as they are reported while validating metadata
+// hashes are read from hashCache if available, hashCache can be nil
+// returns absolute file path -> md5 hex string
+func HashSubmissionFiles(metadata *MDRepoSubmitMetadata, hashCache *hashcache.HashCache) (map[string]string, error) {
+	return HashSubmissionFilesParallel([]*MDRepoSubmitMetadata{metadata}, hashCache, 1, false, false)
+}
+
+// HashSubmissionFilesParallel computes MD5 hashes of files to be submitted for multiple simulations
+// hashing runs on a bounded worker pool, and shows "checksum" progress if showProgress is set
+// returns absolute file path -> md5 hex string
+func HashSubmissionFilesParallel(metadataList []*MDRepoSubmitMetadata, hashCache *hashcache.HashCache, workers int, showProgress bool, showFullPath bool) (map[string]string, error) {
+	fileHashes := map[string]string{}
+	fileHashesMutex := sync.Mutex{}
+
+	if workers < 1 {
+		workers = 1
+	}
+
+	jobManager := parallel.NewParallelJobManager(workers, showProgress, showFullPath, true)
+
+	emptyFiles := []string{}
+	for _, metadata := range metadataList {
+		for _, file := range GetSubmissionFiles(metadata) {
+			filePath := metadata.GetSubmitFileLocalPath(file)
+			absFilePath, err := filepath.Abs(filePath)
+			if err != nil {
+				return nil, errors.Wrapf(err, "Failed to get absolute path for %q", filePath)
+			}
+
+			st, err := os.Stat(absFilePath)
+			if err != nil || st.IsDir() {
+				continue
+			}
+
+			if st.Size() == 0 {
+				emptyFiles = append(emptyFiles, filePath)
+				continue
+			}
+
+			fileSize := st.Size()
+			hashTask := func(job *parallel.ParallelJob) error {
+				if job.IsCanceled() {
+					job.Progress("checksum", -1, fileSize, true)
+					return nil
+				}
+
+				job.Progress("checksum", 0, fileSize, false)
+
+				progressCallback := func(taskName string, processed int64, total int64) {
+					job.Progress("checksum", processed, total, false)
+				}
+
+				hash, err := hashCache.HashLocalFile(absFilePath, "md5", progressCallback)
+				if err != nil {
+					job.Progress("checksum", -1, fileSize, true)
+					return errors.Wrapf(err, "Failed to compute MD5 for %q", filePath)
+				}
+
+				job.Progress("checksum", fileSize, fileSize, false)
+
+				fileHashesMutex.Lock()
+				fileHashes[absFilePath] = hex.EncodeToString(hash)
+				fileHashesMutex.Unlock()
+				return nil
+			}
+
+			jobManager.Schedule(absFilePath, hashTask, 1, progress.UnitsBytes)
+		}
+	}
+
+	if len(emptyFiles) == 1 {
+		return nil, errors.Errorf("file %q is empty", emptyFiles[0])
+	} else if len(emptyFiles) > 1 {
+		return nil, errors.Errorf("files %s are empty", strings.Join(emptyFiles, ", "))
+	}
+
+	err := jobManager.Start()
+	if err != nil {
+		return nil, err
+	}
+
+	return fileHashes, nil
+}
+
+// FindOrphanSubmissionFiles returns files in the simulation dir that are not listed in the metadata
+// files matching patterns in .mdrepoignore are not reported
+// returned paths are relative to the simulation dir, separated by '/'
+func FindOrphanSubmissionFiles(metadata *MDRepoSubmitMetadata) ([]string, error) {
+	ignore, err := LoadSubmissionIgnore(metadata.SubmissionPath)
+	if err != nil {
+		return nil, err
+	}
+
+	submissionFiles := GetSubmissionFiles(metadata)
+	orphanFiles := []string{}
+
+	err = filepath.WalkDir(metadata.SubmissionPath, func(filePath string, entry fs.DirEntry, walkErr error) error {
+		if walkErr != nil {
+			return errors.Wrapf(walkErr, "Failed to read %q", filePath)
+		}
+
+		if filePath == metadata.SubmissionPath {
+			return nil
+		}
+
+		relPath, err := filepath.Rel(metadata.SubmissionPath, filePath)
+		if err != nil {
+			return errors.Wrapf(err, "Failed to get relative path for %q", filePath)
+		}
+
+		relPath = filepath.ToSlash(relPath)
+
+		if ignore.IsIgnored(relPath, entry.IsDir()) {
+			if entry.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if entry.IsDir() {
+			if relPath == SubmitStateDirname {
+				// submit state of the simulation itself
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if relPath == SubmissionIgnoreFilename || IsStatusFile(entry.Name()) {
+			return nil
+		}
+
+		if !slices.Contains(submissionFiles, relPath) {
+			orphanFiles = append(orphanFiles, relPath)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return orphanFiles, nil
+}
+
+// GetDuplicateSubmissionFiles returns files sharing the same MD5 hash, grouped by the hash
+func GetDuplicateSubmissionFiles(fileHashes map[string]string) map[string][]string {
+	hashToFiles := map[string][]string{} // md5 hex -> all file paths sharing it
+	for filePath, hashStr := range fileHashes {
+		hashToFiles[hashStr] = append(hashToFiles[hashStr], filePath)
+	}
+
+	duplicates := map[string][]string{}
+	for hashStr, files := range hashToFiles {
+		if len(files) > 1 {
+			slices.Sort(files)
+			duplicates[hashStr] = files
+		}
+	}
+
+	return duplicates
+}
+
+// CheckDuplicateSubmissionFiles returns an error if any files share the same MD5 hash
+func CheckDuplicateSubmissionFiles(fileHashes map[string]string) error {
+	duplicates := GetDuplicateSubmissionFiles(fileHashes)
+
+	duplicateMessages := []string{}
+	for hashStr, files := range duplicates {
+		duplicateMessages = append(duplicateMessages, fmt.Sprintf("hash %s: %s", hashStr, strings.Join(files, ", ")))
+	}
+
+	if len(duplicateMessages) > 0 {
+		slices.Sort(duplicateMessages)
+		return errors.Errorf("duplicate MD5 hashes found:\n%s", strings.Join(duplicateMessages, "\n"))
+	}
+
+	return nil
+}
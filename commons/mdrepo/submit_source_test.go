@@ -0,0 +1,89 @@
+package mdrepo
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSubmitSource(t *testing.T) {
+	t.Run("test FindSubmissionSourcePaths", testFindSubmissionSourcePaths)
+	t.Run("test FindSubmissionSourcePathsRecursive", testFindSubmissionSourcePathsRecursive)
+}
+
+func makeSubmissionTree(t *testing.T, simulations []string, others []string) string {
+	rootPath := t.TempDir()
+
+	for _, simulation := range simulations {
+		simulationPath := filepath.Join(rootPath, filepath.FromSlash(simulation))
+		err := os.MkdirAll(simulationPath, 0755)
+		assert.NoError(t, err)
+
+		err = os.WriteFile(GetSubmitMetadataPath(simulationPath), []byte("x"), 0644)
+		assert.NoError(t, err)
+	}
+
+	for _, other := range others {
+		err := os.MkdirAll(filepath.Join(rootPath, filepath.FromSlash(other)), 0755)
+		assert.NoError(t, err)
+	}
+
+	return rootPath
+}
+
+func testFindSubmissionSourcePaths(t *testing.T) {
+	rootPath := makeSubmissionTree(t, []string{"sim1", "sim2", "system/cond/rep1"}, []string{"empty", ".mdrepo"})
+
+	valid, invalid, invalidErrors, err := FindSubmissionSourcePaths([]string{rootPath}, nil)
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{filepath.Join(rootPath, "sim1"), filepath.Join(rootPath, "sim2")}, valid)
+	assert.ElementsMatch(t, []string{filepath.Join(rootPath, "empty"), filepath.Join(rootPath, "system")}, invalid)
+	assert.Len(t, invalidErrors, 2)
+}
+
+func testFindSubmissionSourcePathsRecursive(t *testing.T) {
+	rootPath := makeSubmissionTree(t, []string{
+		"sysA/cond1/rep1",
+		"sysA/cond1/rep2",
+		"sysA/cond2/rep1",
+		"sysB/cond1/rep1",
+		"sysB/deep/deeper/deepest/rep1",
+	}, []string{"sysB/empty"})
+
+	options := &SubmissionDiscoveryOptions{
+		Recursive: true,
+	}
+
+	valid, invalid, _, err := FindSubmissionSourcePaths([]string{rootPath}, options)
+	assert.NoError(t, err)
+	assert.Len(t, valid, 5)
+	assert.Equal(t, []string{filepath.Join(rootPath, "sysB/empty")}, invalid)
+
+	// max depth
+	options.MaxDepth = 3
+	valid, invalid, _, err = FindSubmissionSourcePaths([]string{rootPath}, options)
+	assert.NoError(t, err)
+	assert.Len(t, valid, 4)
+	assert.ElementsMatch(t, []string{filepath.Join(rootPath, "sysB/empty"), filepath.Join(rootPath, "sysB/deep/deeper")}, invalid)
+
+	// include and exclude
+	options.MaxDepth = 0
+	options.Include = []string{"sysA/*/*"}
+	options.Exclude = []string{"cond2"}
+	valid, invalid, invalidErrors, err := FindSubmissionSourcePaths([]string{rootPath}, options)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		filepath.Join(rootPath, "sysA/cond1/rep1"),
+		filepath.Join(rootPath, "sysA/cond1/rep2"),
+	}, valid)
+	assert.Len(t, invalid, 4)
+	assert.Len(t, invalidErrors, 4)
+
+	// bad pattern
+	options.Include = []string{"["}
+	_, _, _, err = FindSubmissionSourcePaths([]string{rootPath}, options)
+	assert.Error(t, err)
+}
@@ -4,12 +4,16 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"runtime"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/MD-Repo/md-repo-cli/commons"
 	common_path "github.com/MD-Repo/md-repo-cli/commons/path"
 	"github.com/cockroachdb/errors"
 	"github.com/cyverse/go-irodsclient/fs"
+	irodsclient_types "github.com/cyverse/go-irodsclient/irods/types"
 )
 
 type SubmitStatus string
@@ -88,6 +92,30 @@ type SubmitStatusFileWriter struct {
 	Status          SubmitStatus
 	Files           []SubmitStatusEntry
 	Time            time.Time
+
+	Provenance SubmitStatusProvenance
+
+	// progress, updated by heartbeat
+	CompletedFileNumber int64
+	TransferredSize     int64
+	TransferRate        float64
+	LastUpdate          time.Time
+
+	fileIndices  map[string]int // irods path -> index in Files
+	writtenSizes map[string]int // status filename -> size written
+	mutex        sync.Mutex
+	uploadMutex  sync.Mutex // serializes uploads, not to block updates while uploading
+}
+
+// SubmitStatusProvenance describes how the submission was made, to debug failed submissions
+type SubmitStatusProvenance struct {
+	ClientVersion       string `json:"client_version"`
+	Platform            string `json:"platform"` // os/arch
+	TransferMode        string `json:"transfer_mode,omitempty"`
+	ThreadNumber        int    `json:"thread_number,omitempty"`
+	ThreadNumberPerFile int    `json:"thread_number_per_file,omitempty"`
+	MetadataMD5Hash     string `json:"metadata_md5_hash,omitempty"`
+	TokenFingerprint    string `json:"token_fingerprint,omitempty"`
 }
 
 func NewSubmitStatusFileWriter(filesystem *fs.FileSystem, token string, dataRootPath string) *SubmitStatusFileWriter {
@@ -98,67 +126,142 @@ func NewSubmitStatusFileWriter(filesystem *fs.FileSystem, token string, dataRoot
 		Status:       SubmitStatusUnknown,
 		Files:        []SubmitStatusEntry{},
 		Time:         time.Time{},
+		Provenance: SubmitStatusProvenance{
+			ClientVersion:    commons.GetClientVersion(),
+			Platform:         fmt.Sprintf("%s/%s", runtime.GOOS, runtime.GOARCH),
+			TokenFingerprint: GetTokenFingerprint(token),
+		},
+		fileIndices:  map[string]int{},
+		writtenSizes: map[string]int{},
 	}
 }
 
+// SetTransferSettings records the transfer mode and thread settings used
+func (s *SubmitStatusFileWriter) SetTransferSettings(transferMode string, threadNumber int, threadNumberPerFile int) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.Provenance.TransferMode = transferMode
+	s.Provenance.ThreadNumber = threadNumber
+	s.Provenance.ThreadNumberPerFile = threadNumberPerFile
+}
+
+// SetMetadataHash records MD5 hash of the metadata file
+func (s *SubmitStatusFileWriter) SetMetadataHash(md5Hash string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.Provenance.MetadataMD5Hash = md5Hash
+}
+
 func (s *SubmitStatusFileWriter) SetInProgress() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
 	s.Status = SubmitStatusInProgress
 	s.Time = time.Now().UTC()
 }
 
 func (s *SubmitStatusFileWriter) SetErrored() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
 	s.Status = SubmitStatusErrored
 	s.Time = time.Now().UTC()
 }
 
 func (s *SubmitStatusFileWriter) SetCompleted() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
 	s.Status = SubmitStatusCompleted
 	s.Time = time.Now().UTC()
 }
 
 func (s *SubmitStatusFileWriter) AddFile(f SubmitStatusEntry) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
 	s.TotalFileNumber++
 	s.TotalFileSize += f.Size
+	s.fileIndices[f.IRODSPath] = len(s.Files)
 	s.Files = append(s.Files, f)
 }
 
+// SetFileTransfer records when the file was uploaded, with how many attempts and threads
+func (s *SubmitStatusFileWriter) SetFileTransfer(irodsPath string, startTime time.Time, endTime time.Time, attempts int, threads int) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	idx, ok := s.fileIndices[irodsPath]
+	if !ok {
+		return
+	}
+
+	startTimeUTC := startTime.UTC()
+	endTimeUTC := endTime.UTC()
+
+	s.Files[idx].StartTime = &startTimeUTC
+	s.Files[idx].EndTime = &endTimeUTC
+	s.Files[idx].Attempts = attempts
+	s.Files[idx].Threads = threads
+}
+
+// SetProgress sets files completed, bytes transferred and the current rate (bytes/sec)
+func (s *SubmitStatusFileWriter) SetProgress(completedFileNumber int64, transferredSize int64, transferRate float64) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.CompletedFileNumber = completedFileNumber
+	s.TransferredSize = transferredSize
+	s.TransferRate = transferRate
+	s.LastUpdate = time.Now().UTC()
+}
+
 type SubmitStatusEntry struct {
-	IRODSPath string `json:"irods_path"`
-	Size      int64  `json:"size"`
-	MD5Hash   string `json:"md5_hash"`
+	IRODSPath string     `json:"irods_path"`
+	Size      int64      `json:"size"`
+	MD5Hash   string     `json:"md5_hash"`
+	StartTime *time.Time `json:"start_time,omitempty"`
+	EndTime   *time.Time `json:"end_time,omitempty"`
+	Attempts  int        `json:"attempts,omitempty"`
+	Threads   int        `json:"threads,omitempty"`
 }
 
 type SubmitStatusFile struct {
 	TotalFileNumber int64               `json:"total_filenum"`
 	TotalFileSize   int64               `json:"total_filesize"`
 	Status          SubmitStatus        `json:"status"`
-	Token           string              `json:"token"`
+	Token           string              `json:"token"` // read by MD-Repo service
 	Files           []SubmitStatusEntry `json:"files"`
 	Time            time.Time           `json:"time"`
+
+	Provenance *SubmitStatusProvenance `json:"provenance,omitempty"`
+
+	CompletedFileNumber int64      `json:"completed_filenum,omitempty"`
+	TransferredSize     int64      `json:"transferred_size,omitempty"`
+	TransferRate        float64    `json:"transfer_rate,omitempty"` // bytes/sec
+	LastUpdate          *time.Time `json:"last_update,omitempty"`
 }
 
 func (s *SubmitStatusFileWriter) GetStatusFilename() string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
 	return getStatusFilename(s.Status)
 }
 
 func (s *SubmitStatusFileWriter) CreateStatusFile() error {
-	statusFileName := s.GetStatusFilename()
-	statusFilePath := common_path.MakeIRODSTargetFilePath(s.FileSystem, statusFileName, s.DataRootPath)
-
-	f := SubmitStatusFile{
-		TotalFileNumber: s.TotalFileNumber,
-		TotalFileSize:   s.TotalFileSize,
-		Status:          s.Status,
-		Token:           s.Token,
-		Files:           s.Files,
-		Time:            s.Time,
-	}
+	s.uploadMutex.Lock()
+	defer s.uploadMutex.Unlock()
 
-	jsonBytes, err := json.Marshal(f)
+	statusFileName, jsonBytes, err := s.makeStatusFile()
 	if err != nil {
-		return errors.Wrapf(err, "failed to marshal submit status file to json")
+		return err
 	}
 
+	statusFilePath := common_path.MakeIRODSTargetFilePath(s.FileSystem, statusFileName, s.DataRootPath)
+
 	// Note: We cannot remove old status files. Ticket does not support delete/move/rename operations
 	// remove old status files
 	//existingDirEntries, err := s.FileSystem.List(s.DataRootPath)
@@ -175,6 +278,15 @@ func (s *SubmitStatusFileWriter) CreateStatusFile() error {
 	//	}
 	//}
 
+	// the status file may be rewritten by heartbeat or by a resumed run
+	// pad with spaces not to be shorter than the existing one, as ticket may not allow truncating
+	existingSize, err := s.getExistingStatusFileSize(statusFileName, statusFilePath)
+	if err != nil {
+		return err
+	}
+
+	jsonBytes = padSubmitStatusFile(jsonBytes, existingSize)
+
 	// upload
 	jsonBytesBuffer := bytes.Buffer{}
 	_, err = jsonBytesBuffer.Write(jsonBytes)
@@ -182,11 +294,93 @@ func (s *SubmitStatusFileWriter) CreateStatusFile() error {
 		return errors.Wrapf(err, "failed to write submit status to buffer")
 	}
 
-	// we do not truncate status file as it should be empty
+	// we do not truncate status file as it is never shorter than before
 	_, err = s.FileSystem.UploadFileFromBuffer(&jsonBytesBuffer, statusFilePath, "", false, false, nil)
 	if err != nil {
 		return errors.Wrapf(err, "failed to create submit status file %q", statusFilePath)
 	}
 
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.writtenSizes[statusFileName] = len(jsonBytes)
 	return nil
 }
+
+// makeStatusFile returns the status filename and JSON content of the current status
+func (s *SubmitStatusFileWriter) makeStatusFile() (string, []byte, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	provenance := s.Provenance
+
+	f := SubmitStatusFile{
+		TotalFileNumber: s.TotalFileNumber,
+		TotalFileSize:   s.TotalFileSize,
+		Status:          s.Status,
+		Token:           s.Token,
+		Files:           s.Files,
+		Time:            s.Time,
+
+		Provenance: &provenance,
+
+		CompletedFileNumber: s.CompletedFileNumber,
+		TransferredSize:     s.TransferredSize,
+		TransferRate:        s.TransferRate,
+	}
+
+	if !s.LastUpdate.IsZero() {
+		lastUpdate := s.LastUpdate
+		f.LastUpdate = &lastUpdate
+	}
+
+	jsonBytes, err := json.Marshal(f)
+	if err != nil {
+		return "", nil, errors.Wrapf(err, "failed to marshal submit status file to json")
+	}
+
+	return getStatusFilename(s.Status), jsonBytes, nil
+}
+
+// getExistingStatusFileSize returns the size of the status file written before, 0 if not exist
+// the file may be written by a previous run, e.g., crashed and resumed
+func (s *SubmitStatusFileWriter) getExistingStatusFileSize(statusFileName string, statusFilePath string) (int, error) {
+	s.mutex.Lock()
+	existingSize := s.writtenSizes[statusFileName]
+	s.mutex.Unlock()
+
+	entry, err := s.FileSystem.Stat(statusFilePath)
+	if err != nil {
+		if irodsclient_types.IsFileNotFoundError(err) {
+			return existingSize, nil
+		}
+
+		return 0, errors.Wrapf(err, "failed to stat submit status file %q", statusFilePath)
+	}
+
+	if int(entry.Size) > existingSize {
+		existingSize = int(entry.Size)
+	}
+
+	return existingSize, nil
+}
+
+// padSubmitStatusFile pads the JSON content with spaces to be at least minSize
+func padSubmitStatusFile(jsonBytes []byte, minSize int) []byte {
+	if len(jsonBytes) >= minSize {
+		return jsonBytes
+	}
+
+	return append(jsonBytes, bytes.Repeat([]byte(" "), minSize-len(jsonBytes))...)
+}
+
+// ParseSubmitStatusFile parses a submit status file, trailing spaces padded are ignored
+func ParseSubmitStatusFile(data []byte) (*SubmitStatusFile, error) {
+	status := &SubmitStatusFile{}
+	err := json.Unmarshal(bytes.TrimSpace(data), status)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to decode submit status file")
+	}
+
+	return status, nil
+}
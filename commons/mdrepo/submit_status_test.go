@@ -0,0 +1,87 @@
+package mdrepo
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSubmitStatus(t *testing.T) {
+	t.Run("test StatusProvenance", testStatusProvenance)
+	t.Run("test StatusFileTransfer", testStatusFileTransfer)
+	t.Run("test ParseStatusFile", testParseStatusFile)
+	t.Run("test PadStatusFile", testPadStatusFile)
+}
+
+func testStatusProvenance(t *testing.T) {
+	writer := NewSubmitStatusFileWriter(nil, "token", "/landing/MDR1")
+	writer.SetTransferSettings("icat", 5, 4)
+	writer.SetMetadataHash("hash")
+
+	assert.Contains(t, writer.Provenance.Platform, "/")
+	assert.Equal(t, GetTokenFingerprint("token"), writer.Provenance.TokenFingerprint)
+	assert.NotContains(t, writer.Provenance.TokenFingerprint, "token")
+	assert.Equal(t, "icat", writer.Provenance.TransferMode)
+	assert.Equal(t, 5, writer.Provenance.ThreadNumber)
+	assert.Equal(t, 4, writer.Provenance.ThreadNumberPerFile)
+	assert.Equal(t, "hash", writer.Provenance.MetadataMD5Hash)
+}
+
+func testStatusFileTransfer(t *testing.T) {
+	writer := NewSubmitStatusFileWriter(nil, "token", "/landing/MDR1")
+	writer.AddFile(SubmitStatusEntry{IRODSPath: "run1.xtc", Size: 10, MD5Hash: "hash1"})
+	writer.AddFile(SubmitStatusEntry{IRODSPath: "run2.xtc", Size: 20, MD5Hash: "hash2"})
+
+	startTime := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	endTime := startTime.Add(time.Minute)
+	writer.SetFileTransfer("run2.xtc", startTime, endTime, 2, 4)
+	writer.SetFileTransfer("unknown.xtc", startTime, endTime, 1, 1)
+
+	assert.Nil(t, writer.Files[0].StartTime)
+	assert.Equal(t, 0, writer.Files[0].Attempts)
+
+	assert.Equal(t, startTime, *writer.Files[1].StartTime)
+	assert.Equal(t, endTime, *writer.Files[1].EndTime)
+	assert.Equal(t, 2, writer.Files[1].Attempts)
+	assert.Equal(t, 4, writer.Files[1].Threads)
+
+	// files not uploaded omit transfer fields
+	entryBytes, err := json.Marshal(writer.Files[0])
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"irods_path": "run1.xtc", "size": 10, "md5_hash": "hash1"}`, string(entryBytes))
+}
+
+func testParseStatusFile(t *testing.T) {
+	// status files rewritten by heartbeat are padded with spaces
+	status, err := ParseSubmitStatusFile([]byte(`{"total_filenum": 2, "total_filesize": 30, "status": "inprogress", "token": "token", "files": [], "time": "2024-01-02T03:04:05Z", "completed_filenum": 1}    `))
+	assert.NoError(t, err)
+	assert.Equal(t, SubmitStatusInProgress, status.Status)
+	assert.Equal(t, int64(2), status.TotalFileNumber)
+	assert.Equal(t, int64(1), status.CompletedFileNumber)
+	assert.Nil(t, status.Provenance)
+
+	_, err = ParseSubmitStatusFile([]byte(`{"status": "bogus"}`))
+	assert.Error(t, err)
+}
+
+func testPadStatusFile(t *testing.T) {
+	writer := NewSubmitStatusFileWriter(nil, "token", "/landing/MDR1")
+	writer.SetInProgress()
+
+	statusFileName, jsonBytes, err := writer.makeStatusFile()
+	assert.NoError(t, err)
+	assert.Equal(t, "mdrepo-submission.inprogress.json", statusFileName)
+
+	// shorter than the existing file written by a previous run
+	paddedBytes := padSubmitStatusFile(jsonBytes, len(jsonBytes)+100)
+	assert.Len(t, paddedBytes, len(jsonBytes)+100)
+
+	status, err := ParseSubmitStatusFile(paddedBytes)
+	assert.NoError(t, err)
+	assert.Equal(t, SubmitStatusInProgress, status.Status)
+
+	// not truncated
+	assert.Equal(t, jsonBytes, padSubmitStatusFile(jsonBytes, len(jsonBytes)-1))
+}
@@ -5,9 +5,11 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"path"
 	"strings"
 
 	"github.com/MD-Repo/md-repo-cli/commons/config"
+	commons_path "github.com/MD-Repo/md-repo-cli/commons/path"
 	"github.com/MD-Repo/md-repo-cli/commons/types"
 	"github.com/cockroachdb/errors"
 	irodsclient_types "github.com/cyverse/go-irodsclient/irods/types"
@@ -67,6 +69,42 @@ func GetMDRepoSimulationRelPath(irodsPath string) (string, error) {
 	return "", errors.Errorf("failed to extract submission ID")
 }
 
+// FindMDRepoTicketForReleasePath returns the ticket having the released file and the iRODS path of the file
+// the path starts with the simulation (e.g., MDR00001234/traj.xtc), or is relative to the simulation if there is only one ticket
+func FindMDRepoTicketForReleasePath(tickets []MDRepoTicket, relPath string) (*MDRepoTicket, string, error) {
+	relPath = path.Clean(strings.TrimPrefix(relPath, "/"))
+
+	for i := range tickets {
+		simulationRelPath, err := GetMDRepoSimulationRelPath(tickets[i].IRODSDataPath)
+		if err != nil {
+			continue
+		}
+
+		simulationRelPath = strings.TrimSuffix(simulationRelPath, "/")
+		if relPath == simulationRelPath || strings.HasPrefix(relPath, simulationRelPath+"/") {
+			releasePath := commons_path.MakeIRODSReleasePath(tickets[i].IRODSDataPath)
+			return &tickets[i], path.Join(releasePath, strings.TrimPrefix(relPath, simulationRelPath)), nil
+		}
+	}
+
+	if len(tickets) == 1 {
+		releasePath := commons_path.MakeIRODSReleasePath(tickets[0].IRODSDataPath)
+		return &tickets[0], path.Join(releasePath, relPath), nil
+	}
+
+	return nil, "", errors.Errorf("failed to find simulation of %q in tickets", relPath)
+}
+
+// GetSubmissionID returns submission ID of the ticket
+func (ticket *MDRepoTicket) GetSubmissionID() string {
+	submissionID, err := GetMDRepoSimulationRelPath(ticket.IRODSDataPath)
+	if err != nil {
+		return ticket.IRODSDataPath
+	}
+
+	return strings.TrimSuffix(submissionID, "/")
+}
+
 func GetMDRepoTicketsFromString(ticketString string) ([]MDRepoTicket, error) {
 	tickets := strings.Split(ticketString, ";")
 	if len(tickets) == 0 || len(ticketString) == 0 {
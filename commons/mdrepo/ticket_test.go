@@ -2,10 +2,13 @@ package mdrepo
 
 import (
 	"testing"
+
+	"github.com/stretchr/testify/assert"
 )
 
 func TestTicket(t *testing.T) {
 	t.Run("test GetMDRepoTicketStringFromToken", testGetMDRepoTicketStringFromToken)
+	t.Run("test FindMDRepoTicketForReleasePath", testFindMDRepoTicketForReleasePath)
 	// t.Run("test AES", testAES)
 	// t.Run("test SingleTicket", testSingleTicket)
 	// t.Run("test MultiTickets", testMultiTickets)
@@ -24,6 +27,27 @@ func testGetMDRepoTicketStringFromToken(t *testing.T) {
 	t.Logf("MDRepo ticket string from token: %s", ticketString)
 }
 
+func testFindMDRepoTicketForReleasePath(t *testing.T) {
+	tickets := []MDRepoTicket{
+		{IRODSTicket: "ticket1", IRODSDataPath: "/iplant/home/shared/mdrepo/release/MDR00000001"},
+		{IRODSTicket: "ticket2", IRODSDataPath: "/iplant/home/shared/mdrepo/release/MDR00000002"},
+	}
+
+	ticket, irodsPath, err := FindMDRepoTicketForReleasePath(tickets, "MDR00000002/inputs/md.mdp")
+	assert.NoError(t, err)
+	assert.Equal(t, "ticket2", ticket.IRODSTicket)
+	assert.Equal(t, "/iplant/home/shared/mdrepo/release/MDR00000002/inputs/md.mdp", irodsPath)
+
+	_, _, err = FindMDRepoTicketForReleasePath(tickets, "traj.xtc")
+	assert.Error(t, err)
+
+	// relative to the only simulation
+	ticket, irodsPath, err = FindMDRepoTicketForReleasePath(tickets[:1], "traj.xtc")
+	assert.NoError(t, err)
+	assert.Equal(t, "ticket1", ticket.IRODSTicket)
+	assert.Equal(t, "/iplant/home/shared/mdrepo/release/MDR00000001/traj.xtc", irodsPath)
+}
+
 /*
 func testAES(t *testing.T) {
 	data := "ticketstr123abc902#2134:/iplant/home/iychoi/data123;ticketstr345efv932#2424:/iplant/home/iychoi/data345"
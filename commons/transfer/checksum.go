@@ -0,0 +1,32 @@
+package transfer
+
+import (
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"hash"
+	"hash/adler32"
+	"strings"
+
+	"github.com/cockroachdb/errors"
+	irodsclient_types "github.com/cyverse/go-irodsclient/irods/types"
+)
+
+// NewChecksumHash returns a hash for the iRODS checksum algorithm, to verify data while streaming
+func NewChecksumHash(algorithm string) (hash.Hash, error) {
+	switch strings.ToLower(algorithm) {
+	case strings.ToLower(string(irodsclient_types.ChecksumAlgorithmMD5)):
+		return md5.New(), nil
+	case strings.ToLower(string(irodsclient_types.ChecksumAlgorithmADLER32)):
+		return adler32.New(), nil
+	case strings.ToLower(string(irodsclient_types.ChecksumAlgorithmSHA1)):
+		return sha1.New(), nil
+	case strings.ToLower(string(irodsclient_types.ChecksumAlgorithmSHA256)):
+		return sha256.New(), nil
+	case strings.ToLower(string(irodsclient_types.ChecksumAlgorithmSHA512)):
+		return sha512.New(), nil
+	default:
+		return nil, errors.Errorf("unknown checksum algorithm %q", algorithm)
+	}
+}
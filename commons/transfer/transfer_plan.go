@@ -0,0 +1,188 @@
+package transfer
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/MD-Repo/md-repo-cli/commons/format"
+	"github.com/MD-Repo/md-repo-cli/commons/types"
+)
+
+// TransferPlanAction determines what a transfer would do to a file
+type TransferPlanAction string
+
+const (
+	// TransferPlanActionNew is for transferring a file not existing at the destination
+	TransferPlanActionNew TransferPlanAction = "new"
+	// TransferPlanActionOverwrite is for transferring a file overwriting the destination
+	TransferPlanActionOverwrite TransferPlanAction = "overwrite"
+	// TransferPlanActionResume is for resuming an incomplete transfer
+	TransferPlanActionResume TransferPlanAction = "resume"
+	// TransferPlanActionSkip is for a file not transferred as the destination has the same content
+	TransferPlanActionSkip TransferPlanAction = "skip"
+	// TransferPlanActionFiltered is for a file not transferred as it is not selected by filters
+	TransferPlanActionFiltered TransferPlanAction = "filtered"
+)
+
+// IsTransfer returns true if the action transfers data
+func (action TransferPlanAction) IsTransfer() bool {
+	return action == TransferPlanActionNew || action == TransferPlanActionOverwrite || action == TransferPlanActionResume
+}
+
+// TransferPlanEntry is a planned transfer of a file
+type TransferPlanEntry struct {
+	Simulation string             `json:"simulation"` // simulation the file belongs to
+	Method     TransferMethod     `json:"method"`
+	Action     TransferPlanAction `json:"action"`
+	SourcePath string             `json:"source_path"`
+	SourceSize int64              `json:"source_size"`
+	DestPath   string             `json:"dest_path"`
+	DestSize   int64              `json:"dest_size"`
+	Notes      []string           `json:"notes"` // reasons of the action
+}
+
+// TransferPlan collects planned transfers for dry-run
+type TransferPlan struct {
+	entries []*TransferPlanEntry
+	mutex   sync.Mutex
+}
+
+// NewTransferPlan creates a new TransferPlan
+func NewTransferPlan() *TransferPlan {
+	return &TransferPlan{
+		entries: []*TransferPlanEntry{},
+	}
+}
+
+// AddEntry adds a planned transfer
+func (plan *TransferPlan) AddEntry(entry *TransferPlanEntry) {
+	plan.mutex.Lock()
+	defer plan.mutex.Unlock()
+
+	plan.entries = append(plan.entries, entry)
+}
+
+// GetEntries returns planned transfers in order they were added
+func (plan *TransferPlan) GetEntries() []*TransferPlanEntry {
+	plan.mutex.Lock()
+	defer plan.mutex.Unlock()
+
+	entries := make([]*TransferPlanEntry, len(plan.entries))
+	copy(entries, plan.entries)
+	return entries
+}
+
+// GetTransferFileCount returns the number of files to be transferred
+func (plan *TransferPlan) GetTransferFileCount() int {
+	count := 0
+	for _, entry := range plan.GetEntries() {
+		if entry.Action.IsTransfer() {
+			count++
+		}
+	}
+
+	return count
+}
+
+// GetTransferSize returns the total bytes to be transferred
+// resumed transfers are counted in full as the size of partial data is unknown
+func (plan *TransferPlan) GetTransferSize() int64 {
+	size := int64(0)
+	for _, entry := range plan.GetEntries() {
+		if entry.Action.IsTransfer() {
+			size += entry.SourceSize
+		}
+	}
+
+	return size
+}
+
+// GetSimulations returns simulations in order they were added
+func (plan *TransferPlan) GetSimulations() []string {
+	simulations := []string{}
+	seen := map[string]bool{}
+	for _, entry := range plan.GetEntries() {
+		if !seen[entry.Simulation] {
+			seen[entry.Simulation] = true
+			simulations = append(simulations, entry.Simulation)
+		}
+	}
+
+	return simulations
+}
+
+// MakeOutputTables adds a table listing planned transfers and a summary table to the output formatter
+// the summary table has a row for each simulation and a row for the total
+func (plan *TransferPlan) MakeOutputTables(outputFormatter *format.OutputFormatter, title string) {
+	entries := plan.GetEntries()
+
+	planTable := outputFormatter.NewTable(title)
+	planTable.SetHeader([]string{
+		"Simulation",
+		"Action",
+		"Source Path",
+		"Size",
+		"Destination Path",
+		"Notes",
+	})
+
+	for _, entry := range entries {
+		planTable.AppendRow([]interface{}{
+			entry.Simulation,
+			string(entry.Action),
+			entry.SourcePath,
+			types.SizeString(entry.SourceSize),
+			entry.DestPath,
+			strings.Join(entry.Notes, ", "),
+		})
+	}
+
+	summaryTable := outputFormatter.NewTable(title + " Summary")
+	summaryTable.SetHeader([]string{
+		"Simulation",
+		"Files",
+		"Files To Transfer",
+		"Files Skipped",
+		"Bytes To Transfer",
+		"Size To Transfer",
+	})
+
+	makeSummaryRow := func(simulation string, entries []*TransferPlanEntry) []interface{} {
+		transferFiles := 0
+		skippedFiles := 0
+		transferSize := int64(0)
+		for _, entry := range entries {
+			if entry.Action.IsTransfer() {
+				transferFiles++
+				transferSize += entry.SourceSize
+			} else if entry.Action == TransferPlanActionSkip || entry.Action == TransferPlanActionFiltered {
+				skippedFiles++
+			}
+		}
+
+		return []interface{}{
+			simulation,
+			len(entries),
+			transferFiles,
+			skippedFiles,
+			transferSize,
+			types.SizeString(transferSize),
+		}
+	}
+
+	simulations := plan.GetSimulations()
+	if len(simulations) > 1 {
+		for _, simulation := range simulations {
+			simulationEntries := []*TransferPlanEntry{}
+			for _, entry := range entries {
+				if entry.Simulation == simulation {
+					simulationEntries = append(simulationEntries, entry)
+				}
+			}
+
+			summaryTable.AppendRow(makeSummaryRow(simulation, simulationEntries))
+		}
+	}
+
+	summaryTable.AppendRow(makeSummaryRow("total", entries))
+}
@@ -0,0 +1,50 @@
+package transfer
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/MD-Repo/md-repo-cli/commons/format"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransferPlan(t *testing.T) {
+	t.Run("test TransferSize", testTransferSize)
+	t.Run("test MakeOutputTables", testMakeOutputTables)
+}
+
+func makeTestTransferPlan() *TransferPlan {
+	plan := NewTransferPlan()
+	plan.AddEntry(&TransferPlanEntry{Simulation: "sim1", Method: TransferMethodPut, Action: TransferPlanActionNew, SourcePath: "a.xtc", SourceSize: 100, DestPath: "/landing/a.xtc"})
+	plan.AddEntry(&TransferPlanEntry{Simulation: "sim1", Method: TransferMethodPut, Action: TransferPlanActionOverwrite, SourcePath: "b.pdb", SourceSize: 20, DestPath: "/landing/b.pdb", DestSize: 10, Notes: []string{"different size"}})
+	plan.AddEntry(&TransferPlanEntry{Simulation: "sim2", Method: TransferMethodPut, Action: TransferPlanActionSkip, SourcePath: "c.top", SourceSize: 5, DestPath: "/landing/c.top", DestSize: 5, Notes: []string{"same checksum"}})
+	return plan
+}
+
+func testTransferSize(t *testing.T) {
+	plan := makeTestTransferPlan()
+
+	assert.Len(t, plan.GetEntries(), 3)
+	assert.Equal(t, []string{"sim1", "sim2"}, plan.GetSimulations())
+	assert.Equal(t, 2, plan.GetTransferFileCount())
+	assert.Equal(t, int64(120), plan.GetTransferSize())
+}
+
+func testMakeOutputTables(t *testing.T) {
+	plan := makeTestTransferPlan()
+
+	buffer := &bytes.Buffer{}
+	outputFormatter := format.NewOutputFormatter(buffer)
+	plan.MakeOutputTables(outputFormatter, "Plan")
+
+	assert.Len(t, outputFormatter.Tables, 2)
+	assert.Len(t, outputFormatter.Tables[0].Rows, 3)
+	assert.Equal(t, [][]interface{}{
+		{"sim1", 2, 2, 0, int64(120), "120B"},
+		{"sim2", 1, 0, 1, int64(0), "0B"},
+		{"total", 3, 2, 1, int64(120), "120B"},
+	}, outputFormatter.Tables[1].Rows)
+
+	outputFormatter.Render(format.OutputFormatCSV)
+	assert.Contains(t, buffer.String(), "sim1,overwrite,b.pdb,20B,/landing/b.pdb,different size")
+}
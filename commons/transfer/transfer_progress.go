@@ -0,0 +1,84 @@
+package transfer
+
+import (
+	"sync"
+	"time"
+)
+
+// TransferProgress tracks files completed and bytes transferred, to report progress periodically
+type TransferProgress struct {
+	processedBytes map[string]int64 // file -> bytes transferred
+	completedFiles int64
+
+	lastSampleTime  time.Time
+	lastSampleBytes int64
+
+	mutex sync.Mutex
+}
+
+// NewTransferProgress creates a new TransferProgress
+func NewTransferProgress() *TransferProgress {
+	return &TransferProgress{
+		processedBytes: map[string]int64{},
+		lastSampleTime: time.Now(),
+	}
+}
+
+// Update sets bytes transferred for the file
+func (p *TransferProgress) Update(name string, processed int64) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if processed >= 0 {
+		p.processedBytes[name] = processed
+	}
+}
+
+// Complete marks the file transferred
+func (p *TransferProgress) Complete(name string, size int64) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.processedBytes[name] = size
+	p.completedFiles++
+}
+
+// Skip marks the file completed without transfer
+func (p *TransferProgress) Skip() {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.completedFiles++
+}
+
+func (p *TransferProgress) getTransferredBytes() int64 {
+	transferred := int64(0)
+	for _, processed := range p.processedBytes {
+		transferred += processed
+	}
+
+	return transferred
+}
+
+// Sample returns files completed, bytes transferred and the rate (bytes/sec) since the last sample
+func (p *TransferProgress) Sample() (int64, int64, float64) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	now := time.Now()
+	transferred := p.getTransferredBytes()
+
+	rate := float64(0)
+	elapsed := now.Sub(p.lastSampleTime).Seconds()
+	if elapsed > 0 {
+		rate = float64(transferred-p.lastSampleBytes) / elapsed
+		if rate < 0 {
+			rate = 0
+		}
+	}
+
+	p.lastSampleTime = now
+	p.lastSampleBytes = transferred
+
+	return p.completedFiles, transferred, rate
+}
@@ -0,0 +1,31 @@
+package transfer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransferProgress(t *testing.T) {
+	t.Run("test Sample", testSample)
+}
+
+func testSample(t *testing.T) {
+	progress := NewTransferProgress()
+
+	progress.Update("a.xtc", 50)
+	progress.Update("b.pdb", 10)
+	progress.Update("b.pdb", -1) // errored progress is ignored
+	progress.Skip()
+
+	completedFiles, transferredSize, rate := progress.Sample()
+	assert.Equal(t, int64(1), completedFiles)
+	assert.Equal(t, int64(60), transferredSize)
+	assert.GreaterOrEqual(t, rate, float64(0))
+
+	progress.Complete("a.xtc", 100)
+
+	completedFiles, transferredSize, _ = progress.Sample()
+	assert.Equal(t, int64(2), completedFiles)
+	assert.Equal(t, int64(110), transferredSize)
+}
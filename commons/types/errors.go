@@ -138,6 +138,148 @@ func IsSimulationNoNotMatchingError(err error) bool {
 	return errors.As(err, &simulationNoNotMatchingErr)
 }
 
+type SubmissionRejectedError struct {
+	RejectedSimulationPaths []string
+}
+
+// NewSubmissionRejectedError creates a submission rejected error
+func NewSubmissionRejectedError(rejected []string) error {
+	return &SubmissionRejectedError{
+		RejectedSimulationPaths: rejected,
+	}
+}
+
+// Error returns error message
+func (err *SubmissionRejectedError) Error() string {
+	return fmt.Sprintf("%d simulation directories would be rejected", len(err.RejectedSimulationPaths))
+}
+
+// Is tests type of error
+func (err *SubmissionRejectedError) Is(other error) bool {
+	_, ok := other.(*SubmissionRejectedError)
+	return ok
+}
+
+// ToString stringifies the object
+func (err *SubmissionRejectedError) ToString() string {
+	return fmt.Sprintf("SubmissionRejectedError: %s", err.Error())
+}
+
+// IsSubmissionRejectedError evaluates if the given error is SubmissionRejectedError
+func IsSubmissionRejectedError(err error) bool {
+	var submissionRejectedErr *SubmissionRejectedError
+	return errors.As(err, &submissionRejectedErr)
+}
+
+type SubmissionNotMatchingError struct {
+	SimulationPaths []string
+	Differences     int
+}
+
+// NewSubmissionNotMatchingError creates an error for local simulations not matching submitted data
+func NewSubmissionNotMatchingError(simulationPaths []string, differences int) error {
+	return &SubmissionNotMatchingError{
+		SimulationPaths: simulationPaths,
+		Differences:     differences,
+	}
+}
+
+// Error returns error message
+func (err *SubmissionNotMatchingError) Error() string {
+	return fmt.Sprintf("%d files in %d simulation directories do not match submitted data", err.Differences, len(err.SimulationPaths))
+}
+
+// Is tests type of error
+func (err *SubmissionNotMatchingError) Is(other error) bool {
+	_, ok := other.(*SubmissionNotMatchingError)
+	return ok
+}
+
+// ToString stringifies the object
+func (err *SubmissionNotMatchingError) ToString() string {
+	return fmt.Sprintf("SubmissionNotMatchingError: %s", err.Error())
+}
+
+// IsSubmissionNotMatchingError evaluates if the given error is SubmissionNotMatchingError
+func IsSubmissionNotMatchingError(err error) bool {
+	var submissionNotMatchingErr *SubmissionNotMatchingError
+	return errors.As(err, &submissionNotMatchingErr)
+}
+
+type DamagedDownloadError struct {
+	SimulationPaths []string
+	DamagedFiles    int
+}
+
+// NewDamagedDownloadError creates an error for downloaded simulations having missing or corrupt files
+func NewDamagedDownloadError(simulationPaths []string, damagedFiles int) error {
+	return &DamagedDownloadError{
+		SimulationPaths: simulationPaths,
+		DamagedFiles:    damagedFiles,
+	}
+}
+
+// Error returns error message
+func (err *DamagedDownloadError) Error() string {
+	return fmt.Sprintf("%d files in %d simulation directories are missing or corrupt", err.DamagedFiles, len(err.SimulationPaths))
+}
+
+// Is tests type of error
+func (err *DamagedDownloadError) Is(other error) bool {
+	_, ok := other.(*DamagedDownloadError)
+	return ok
+}
+
+// ToString stringifies the object
+func (err *DamagedDownloadError) ToString() string {
+	return fmt.Sprintf("DamagedDownloadError: %s", err.Error())
+}
+
+// IsDamagedDownloadError evaluates if the given error is DamagedDownloadError
+func IsDamagedDownloadError(err error) bool {
+	var damagedDownloadErr *DamagedDownloadError
+	return errors.As(err, &damagedDownloadErr)
+}
+
+type ChecksumMismatchError struct {
+	Path             string
+	Algorithm        string
+	ExpectedChecksum string
+	ActualChecksum   string
+}
+
+// NewChecksumMismatchError creates an error for data not matching the checksum recorded in iRODS
+func NewChecksumMismatchError(path string, algorithm string, expectedChecksum string, actualChecksum string) error {
+	return &ChecksumMismatchError{
+		Path:             path,
+		Algorithm:        algorithm,
+		ExpectedChecksum: expectedChecksum,
+		ActualChecksum:   actualChecksum,
+	}
+}
+
+// Error returns error message
+func (err *ChecksumMismatchError) Error() string {
+	return fmt.Sprintf("%s checksum of %q does not match (expected %s, actual %s)", err.Algorithm, err.Path, err.ExpectedChecksum, err.ActualChecksum)
+}
+
+// Is tests type of error
+func (err *ChecksumMismatchError) Is(other error) bool {
+	_, ok := other.(*ChecksumMismatchError)
+	return ok
+}
+
+// ToString stringifies the object
+func (err *ChecksumMismatchError) ToString() string {
+	return fmt.Sprintf("ChecksumMismatchError: %s", err.Error())
+}
+
+// IsChecksumMismatchError evaluates if the given error is ChecksumMismatchError
+func IsChecksumMismatchError(err error) bool {
+	var checksumMismatchErr *ChecksumMismatchError
+	return errors.As(err, &checksumMismatchErr)
+}
+
 type NotDirError struct {
 	Path string
 }
@@ -284,6 +426,46 @@ func IsInvalidSubmitMetadataError(err error) bool {
 	return errors.As(err, &invalidSubmitMetadataErr)
 }
 
+type SubmitMappingError struct {
+	MappingPath string
+	Problems    []string
+}
+
+// NewSubmitMappingError creates an error for simulation dirs not matching tickets
+func NewSubmitMappingError(mappingPath string, problems []string) error {
+	return &SubmitMappingError{
+		MappingPath: mappingPath,
+		Problems:    problems,
+	}
+}
+
+// Error returns error message
+func (err *SubmitMappingError) Error() string {
+	message := ""
+	for idx, problem := range err.Problems {
+		message += fmt.Sprintf("%d. %s\n", idx+1, problem)
+	}
+
+	return fmt.Sprintf("simulation directories do not match tickets (mapping %q)\n%s", err.MappingPath, message)
+}
+
+// Is tests type of error
+func (err *SubmitMappingError) Is(other error) bool {
+	_, ok := other.(*SubmitMappingError)
+	return ok
+}
+
+// ToString stringifies the object
+func (err *SubmitMappingError) ToString() string {
+	return fmt.Sprintf("SubmitMappingError: %s", err.Error())
+}
+
+// IsSubmitMappingError evaluates if the given error is SubmitMappingError
+func IsSubmitMappingError(err error) bool {
+	var submitMappingErr *SubmitMappingError
+	return errors.As(err, &submitMappingErr)
+}
+
 type DialHTTPError struct {
 	URL string
 }
@@ -0,0 +1,302 @@
+package webdav
+
+import (
+	"container/list"
+	"io"
+	"sync"
+
+	"github.com/cockroachdb/errors"
+	irodsclient_fs "github.com/cyverse/go-irodsclient/fs"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	RangeReaderBlockSizeDefault       int64 = 1024 * 1024 // 1MB
+	RangeReaderCacheBlocksDefault     int   = 64
+	RangeReaderReadAheadBlocksDefault int   = 4
+)
+
+// RangeReader provides random access to a remote file over WebDAV range requests
+// the file is read in blocks, recently used blocks are cached and following blocks are read ahead on sequential reads
+// ReadAt is safe for concurrent use, Read and Seek share an offset like os.File
+type RangeReader struct {
+	client    *WebDAVClient
+	irodsPath string
+	ticket    string
+	size      int64
+
+	blockSize       int64
+	cacheBlocks     int
+	readAheadBlocks int
+
+	blocks         map[int64]*rangeReaderBlock
+	lru            *list.List // front is the most recently used
+	lastBlockIndex int64
+	closed         bool
+	mutex          sync.Mutex
+	fetchWaitGroup sync.WaitGroup
+
+	offset      int64
+	offsetMutex sync.Mutex
+}
+
+type rangeReaderBlock struct {
+	index   int64
+	data    []byte
+	err     error
+	ready   chan struct{} // closed when data or err is set
+	element *list.Element
+}
+
+// NewRangeReader creates a new RangeReader for the released file with default block settings
+func NewRangeReader(client *WebDAVClient, sourceEntry *irodsclient_fs.Entry, ticket string) (*RangeReader, error) {
+	if sourceEntry.IsDir() {
+		return nil, errors.Errorf("%q is not a file", sourceEntry.Path)
+	}
+
+	return NewRangeReaderWithConfig(client, sourceEntry.Path, ticket, sourceEntry.Size, RangeReaderBlockSizeDefault, RangeReaderCacheBlocksDefault, RangeReaderReadAheadBlocksDefault)
+}
+
+// NewRangeReaderWithConfig creates a new RangeReader for the file of the size
+// at least readAheadBlocks + 1 blocks are cached
+func NewRangeReaderWithConfig(client *WebDAVClient, irodsPath string, ticket string, size int64, blockSize int64, cacheBlocks int, readAheadBlocks int) (*RangeReader, error) {
+	if blockSize <= 0 {
+		return nil, errors.Errorf("invalid block size %d", blockSize)
+	}
+
+	if size < 0 {
+		return nil, errors.Errorf("invalid size %d", size)
+	}
+
+	if readAheadBlocks < 0 {
+		readAheadBlocks = 0
+	}
+
+	if cacheBlocks < readAheadBlocks+1 {
+		cacheBlocks = readAheadBlocks + 1
+	}
+
+	return &RangeReader{
+		client:    client,
+		irodsPath: irodsPath,
+		ticket:    ticket,
+		size:      size,
+
+		blockSize:       blockSize,
+		cacheBlocks:     cacheBlocks,
+		readAheadBlocks: readAheadBlocks,
+
+		blocks:         map[int64]*rangeReaderBlock{},
+		lru:            list.New(),
+		lastBlockIndex: -1,
+	}, nil
+}
+
+// Size returns the size of the file
+func (reader *RangeReader) Size() int64 {
+	return reader.size
+}
+
+// ReadAt reads len(p) bytes from the offset
+func (reader *RangeReader) ReadAt(p []byte, offset int64) (int, error) {
+	if offset < 0 {
+		return 0, errors.Errorf("invalid offset %d", offset)
+	}
+
+	if offset >= reader.size {
+		return 0, io.EOF
+	}
+
+	readLen := 0
+	for readLen < len(p) && offset < reader.size {
+		blockIndex := offset / reader.blockSize
+
+		block, err := reader.getBlock(blockIndex)
+		if err != nil {
+			return readLen, err
+		}
+
+		copied := copy(p[readLen:], block.data[offset-blockIndex*reader.blockSize:])
+		readLen += copied
+		offset += int64(copied)
+	}
+
+	if readLen < len(p) {
+		return readLen, io.EOF
+	}
+
+	return readLen, nil
+}
+
+// Read reads from the current offset
+func (reader *RangeReader) Read(p []byte) (int, error) {
+	reader.offsetMutex.Lock()
+	defer reader.offsetMutex.Unlock()
+
+	if len(p) == 0 {
+		return 0, nil
+	}
+
+	readLen, err := reader.ReadAt(p, reader.offset)
+	reader.offset += int64(readLen)
+
+	if err == io.EOF && readLen > 0 {
+		// return EOF on the next read
+		err = nil
+	}
+
+	return readLen, err
+}
+
+// Seek sets the offset for the next Read
+func (reader *RangeReader) Seek(offset int64, whence int) (int64, error) {
+	reader.offsetMutex.Lock()
+	defer reader.offsetMutex.Unlock()
+
+	newOffset := offset
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		newOffset += reader.offset
+	case io.SeekEnd:
+		newOffset += reader.size
+	default:
+		return reader.offset, errors.Errorf("invalid whence %d", whence)
+	}
+
+	if newOffset < 0 {
+		return reader.offset, errors.Errorf("invalid offset %d", newOffset)
+	}
+
+	reader.offset = newOffset
+	return newOffset, nil
+}
+
+// Close releases cached blocks, waiting for blocks being read
+func (reader *RangeReader) Close() error {
+	reader.mutex.Lock()
+	reader.closed = true
+	reader.blocks = map[int64]*rangeReaderBlock{}
+	reader.lru.Init()
+	reader.mutex.Unlock()
+
+	reader.fetchWaitGroup.Wait()
+	return nil
+}
+
+// getBlock returns the block, reading it from the server if not cached
+func (reader *RangeReader) getBlock(blockIndex int64) (*rangeReaderBlock, error) {
+	reader.mutex.Lock()
+
+	if reader.closed {
+		reader.mutex.Unlock()
+		return nil, errors.Errorf("reader of %q is closed", reader.irodsPath)
+	}
+
+	block := reader.requestBlock(blockIndex)
+
+	// read ahead only on sequential reads, not to waste bandwidth on random reads
+	if blockIndex == reader.lastBlockIndex+1 {
+		for i := 1; i <= reader.readAheadBlocks; i++ {
+			readAheadIndex := blockIndex + int64(i)
+			if readAheadIndex*reader.blockSize >= reader.size {
+				break
+			}
+
+			reader.requestBlock(readAheadIndex)
+		}
+
+		// keep the requested block most recently used
+		reader.lru.MoveToFront(block.element)
+	}
+
+	reader.lastBlockIndex = blockIndex
+	reader.mutex.Unlock()
+
+	<-block.ready
+
+	if block.err != nil {
+		return nil, block.err
+	}
+
+	return block, nil
+}
+
+// requestBlock returns the cached block, or a block being read in background
+// must be called with the mutex locked
+func (reader *RangeReader) requestBlock(blockIndex int64) *rangeReaderBlock {
+	if block, ok := reader.blocks[blockIndex]; ok {
+		reader.lru.MoveToFront(block.element)
+		return block
+	}
+
+	block := &rangeReaderBlock{
+		index: blockIndex,
+		ready: make(chan struct{}),
+	}
+
+	block.element = reader.lru.PushFront(block)
+	reader.blocks[blockIndex] = block
+
+	for reader.lru.Len() > reader.cacheBlocks {
+		// readers of the evicted block still hold it
+		evicted := reader.lru.Remove(reader.lru.Back()).(*rangeReaderBlock)
+		delete(reader.blocks, evicted.index)
+	}
+
+	reader.fetchWaitGroup.Add(1)
+	go reader.fetchBlock(block)
+
+	return block
+}
+
+func (reader *RangeReader) fetchBlock(block *rangeReaderBlock) {
+	defer reader.fetchWaitGroup.Done()
+	defer close(block.ready)
+
+	offset := block.index * reader.blockSize
+	length := reader.blockSize
+	if offset+length > reader.size {
+		length = reader.size - offset
+	}
+
+	logger := log.WithFields(log.Fields{
+		"irods_path": reader.irodsPath,
+		"offset":     offset,
+		"length":     length,
+	})
+
+	logger.Debug("reading a block from WebDAV server")
+
+	data, err := reader.readRange(offset, length)
+	if err != nil {
+		block.err = err
+
+		// do not cache the error, to try again on the next read
+		reader.mutex.Lock()
+		if cached, ok := reader.blocks[block.index]; ok && cached == block {
+			reader.lru.Remove(block.element)
+			delete(reader.blocks, block.index)
+		}
+		reader.mutex.Unlock()
+		return
+	}
+
+	block.data = data
+}
+
+func (reader *RangeReader) readRange(offset int64, length int64) ([]byte, error) {
+	rangeReader, err := reader.client.ReadFileRange(reader.irodsPath, reader.ticket, offset, length)
+	if err != nil {
+		return nil, err
+	}
+	defer rangeReader.Close()
+
+	data := make([]byte, length)
+	_, err = io.ReadFull(rangeReader, data)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read file %q (offset %d, length %d) from WebDAV server", reader.irodsPath, offset, length)
+	}
+
+	return data, nil
+}
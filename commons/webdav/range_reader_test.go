@@ -0,0 +1,149 @@
+package webdav
+
+import (
+	"bytes"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRangeReader(t *testing.T) {
+	t.Run("test ReadAt", testRangeReaderReadAt)
+	t.Run("test ReadSeek", testRangeReaderReadSeek)
+	t.Run("test BlockCache", testRangeReaderBlockCache)
+}
+
+// newTestWebDAVServer returns a local WebDAV server serving the data at /data.bin, and the number of GET requests
+func newTestWebDAVServer(t *testing.T, data []byte) (*httptest.Server, *int64) {
+	getRequests := int64(0)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodOptions:
+			w.Header().Set("DAV", "1")
+			w.WriteHeader(http.StatusOK)
+		case http.MethodGet:
+			if r.URL.Path != "/data.bin" {
+				http.NotFound(w, r)
+				return
+			}
+
+			atomic.AddInt64(&getRequests, 1)
+			http.ServeContent(w, r, "data.bin", time.Now(), bytes.NewReader(data))
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return server, &getRequests
+}
+
+func newTestRangeReader(t *testing.T, data []byte, blockSize int64, cacheBlocks int, readAheadBlocks int) (*RangeReader, *int64) {
+	server, getRequests := newTestWebDAVServer(t, data)
+
+	client, err := NewWebDAVClient(nil, server.URL, "user", "password")
+	assert.NoError(t, err)
+
+	reader, err := NewRangeReaderWithConfig(client, "/data.bin", "", int64(len(data)), blockSize, cacheBlocks, readAheadBlocks)
+	assert.NoError(t, err)
+	t.Cleanup(func() {
+		reader.Close()
+	})
+
+	return reader, getRequests
+}
+
+func makeTestData(size int) []byte {
+	data := make([]byte, size)
+	rand.New(rand.NewSource(1)).Read(data)
+	return data
+}
+
+func testRangeReaderReadAt(t *testing.T) {
+	data := makeTestData(10000)
+	reader, _ := newTestRangeReader(t, data, 1024, 4, 1)
+
+	// spans blocks
+	buf := make([]byte, 3000)
+	readLen, err := reader.ReadAt(buf, 1000)
+	assert.NoError(t, err)
+	assert.Equal(t, 3000, readLen)
+	assert.Equal(t, data[1000:4000], buf)
+
+	// the last block is shorter than the block size
+	readLen, err = reader.ReadAt(buf, 9000)
+	assert.Equal(t, io.EOF, err)
+	assert.Equal(t, 1000, readLen)
+	assert.Equal(t, data[9000:], buf[:readLen])
+
+	readLen, err = reader.ReadAt(buf, 10000)
+	assert.Equal(t, io.EOF, err)
+	assert.Equal(t, 0, readLen)
+}
+
+func testRangeReaderReadSeek(t *testing.T) {
+	data := makeTestData(5000)
+	reader, _ := newTestRangeReader(t, data, 512, 4, 2)
+
+	readData, err := io.ReadAll(reader)
+	assert.NoError(t, err)
+	assert.Equal(t, data, readData)
+
+	offset, err := reader.Seek(-100, io.SeekEnd)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4900), offset)
+
+	readData, err = io.ReadAll(reader)
+	assert.NoError(t, err)
+	assert.Equal(t, data[4900:], readData)
+
+	_, err = reader.Seek(10, io.SeekStart)
+	assert.NoError(t, err)
+
+	offset, err = reader.Seek(20, io.SeekCurrent)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(30), offset)
+
+	buf := make([]byte, 10)
+	_, err = io.ReadFull(reader, buf)
+	assert.NoError(t, err)
+	assert.Equal(t, data[30:40], buf)
+
+	_, err = reader.Seek(-1, io.SeekStart)
+	assert.Error(t, err)
+}
+
+func testRangeReaderBlockCache(t *testing.T) {
+	data := makeTestData(8192)
+	reader, getRequests := newTestRangeReader(t, data, 1024, 8, 0)
+
+	buf := make([]byte, 100)
+	for i := 0; i < 10; i++ {
+		_, err := reader.ReadAt(buf, 2048+int64(i*100))
+		assert.NoError(t, err)
+		assert.Equal(t, data[2048+i*100:2148+i*100], buf)
+	}
+
+	// all reads are served from a block
+	assert.Equal(t, int64(1), atomic.LoadInt64(getRequests))
+
+	// sequential reads read following blocks ahead
+	readAheadReader, readAheadRequests := newTestRangeReader(t, data, 1024, 8, 3)
+
+	_, err := readAheadReader.ReadAt(buf, 0)
+	assert.NoError(t, err)
+
+	readAheadReader.fetchWaitGroup.Wait()
+	assert.Equal(t, int64(4), atomic.LoadInt64(readAheadRequests))
+
+	_, err = readAheadReader.ReadAt(buf, 3000)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), atomic.LoadInt64(readAheadRequests))
+}
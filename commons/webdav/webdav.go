@@ -5,6 +5,7 @@ import (
 	"crypto/tls"
 	"io"
 	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
 	"strings"
@@ -22,6 +23,10 @@ import (
 	"github.com/studio-b12/gowebdav"
 )
 
+const (
+	ticketQueryPrefix string = "?ticket="
+)
+
 type WebDAVClient struct {
 	filesystem *irodsclient_fs.FileSystem
 	baseURL    string
@@ -88,6 +93,7 @@ func (client *WebDAVClient) initWebDAV() error {
 	}
 
 	webdav.SetTransport(transport)
+	webdav.SetInterceptor(restoreTicketQuery)
 	err := webdav.Connect()
 	if err != nil {
 		if httpStatusErr, ok := client.getWebDAVErrorCode(err); ok {
@@ -120,8 +126,26 @@ func (client *WebDAVClient) getWebDavError(url string, err error) error {
 	return err
 }
 
+// getPathForTicket returns the path relative to the base URL, as gowebdav joins it to the base URL
+// the ticket is given as a query, restored by restoreTicketQuery as gowebdav escapes '?' in the path
 func (client *WebDAVClient) getPathForTicket(irodsPath string, ticket string) string {
-	return client.baseURL + irodsPath + "?ticket=" + ticket
+	if len(ticket) == 0 {
+		return irodsPath
+	}
+
+	return irodsPath + ticketQueryPrefix + url.QueryEscape(ticket)
+}
+
+// restoreTicketQuery moves the ticket query escaped into the path back to the query of the request
+func restoreTicketQuery(method string, request *http.Request) {
+	queryIdx := strings.LastIndex(request.URL.Path, ticketQueryPrefix)
+	if queryIdx < 0 {
+		return
+	}
+
+	request.URL.RawQuery = request.URL.Path[queryIdx+1:]
+	request.URL.Path = request.URL.Path[:queryIdx]
+	request.URL.RawPath = ""
 }
 
 func (client *WebDAVClient) DownloadFile(sourceEntry *irodsclient_fs.Entry, localPath string, ticket string, verifyChecksum bool, callback irodsclient_common.TransferTrackerCallback) (*irodsclient_fs.FileTransferResult, error) {
@@ -227,6 +251,19 @@ func (client *WebDAVClient) DownloadFile(sourceEntry *irodsclient_fs.Entry, loca
 	return fileTransferResult, nil
 }
 
+// ReadFileRange returns a reader of length bytes of the file from offset
+func (client *WebDAVClient) ReadFileRange(irodsPath string, ticket string, offset int64, length int64) (io.ReadCloser, error) {
+	webdavPath := client.getPathForTicket(irodsPath, ticket)
+
+	reader, readErr := client.webdav.ReadStreamRange(webdavPath, offset, length)
+	if readErr != nil {
+		baseErr := client.getWebDavError(client.baseURL+irodsPath, readErr)
+		return nil, errors.Wrapf(baseErr, "failed to read stream range of file %q (offset %d, length %d) from WebDAV server", irodsPath, offset, length)
+	}
+
+	return reader, nil
+}
+
 func (client *WebDAVClient) UploadFile(localPath string, irodsPath string, ticket string, verifyChecksum bool, callback irodsclient_common.TransferTrackerCallback) (*irodsclient_fs.FileTransferResult, error) {
 	logger := log.WithFields(log.Fields{
 		"local_source_path": localPath,
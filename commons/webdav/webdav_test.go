@@ -2,8 +2,13 @@ package webdav
 
 import (
 	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"strings"
 	"testing"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 
@@ -15,6 +20,72 @@ func TestWebDAV(t *testing.T) {
 	t.Run("test DownloadFileFromWebDAV", testDownloadFileFromWebDAV)
 }
 
+func TestWebDAVPath(t *testing.T) {
+	t.Run("test ReadFileRange", testReadFileRange)
+	t.Run("test ReadFileRangeWithTicket", testReadFileRangeWithTicket)
+}
+
+// newTestWebDAVPathServer returns a local WebDAV server under /dav serving the data at /dav/sim/data.bin
+// the ticket query of the last GET request is recorded
+func newTestWebDAVPathServer(t *testing.T, data string) (*httptest.Server, *string) {
+	lastTicket := ""
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodOptions:
+			w.Header().Set("DAV", "1")
+			w.WriteHeader(http.StatusOK)
+		case http.MethodGet:
+			if r.URL.Path != "/dav/sim/data.bin" {
+				http.NotFound(w, r)
+				return
+			}
+
+			lastTicket = r.URL.Query().Get("ticket")
+			http.ServeContent(w, r, "data.bin", time.Now(), strings.NewReader(data))
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return server, &lastTicket
+}
+
+func testReadFileRange(t *testing.T) {
+	server, lastTicket := newTestWebDAVPathServer(t, "0123456789")
+
+	client, err := NewWebDAVClient(nil, server.URL+"/dav/", "user", "password")
+	assert.NoError(t, err)
+	assert.Equal(t, "/sim/data.bin", client.getPathForTicket("/sim/data.bin", ""))
+
+	reader, err := client.ReadFileRange("/sim/data.bin", "", 2, 5)
+	assert.NoError(t, err)
+	defer reader.Close()
+
+	readBytes, err := io.ReadAll(reader)
+	assert.NoError(t, err)
+	assert.Equal(t, "23456", string(readBytes))
+	assert.Empty(t, *lastTicket)
+}
+
+func testReadFileRangeWithTicket(t *testing.T) {
+	server, lastTicket := newTestWebDAVPathServer(t, "0123456789")
+
+	client, err := NewWebDAVClient(nil, server.URL+"/dav", "user", "password")
+	assert.NoError(t, err)
+	assert.Equal(t, "/sim/data.bin?ticket=ticket1", client.getPathForTicket("/sim/data.bin", "ticket1"))
+
+	reader, err := client.ReadFileRange("/sim/data.bin", "ticket1", 0, 4)
+	assert.NoError(t, err)
+	defer reader.Close()
+
+	readBytes, err := io.ReadAll(reader)
+	assert.NoError(t, err)
+	assert.Equal(t, "0123", string(readBytes))
+	assert.Equal(t, "ticket1", *lastTicket)
+}
+
 func testDownloadFileFromWebDAV(t *testing.T) {
 	checksumBytes, _ := hex.DecodeString("713133e1a59ef6d1e42aa5405beae0de")
 	sourceEntry := &irodsclient_fs.Entry{
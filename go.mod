@@ -12,6 +12,7 @@ require (
 	github.com/cyverse/go-irodsclient v0.20.1
 	github.com/cyverse/gocommands v0.12.2
 	github.com/jedib0t/go-pretty/v6 v6.6.7
+	github.com/klauspost/compress v1.18.0
 	github.com/sirupsen/logrus v1.9.3
 	github.com/spf13/cobra v1.10.1
 	github.com/stretchr/testify v1.11.1